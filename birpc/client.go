@@ -1,14 +1,18 @@
 package birpc
 
 import (
+	"context"
 	"crypto/tls"
 	"fmt"
+	"math/rand"
 	"net"
 	"runtime"
+	"sync"
 	"time"
 
 	"github.com/damnever/sunflower/msg"
 	"github.com/damnever/sunflower/msg/msgpb"
+	"github.com/damnever/sunflower/pkg/dialer"
 	"github.com/damnever/sunflower/pkg/retry"
 	"github.com/damnever/sunflower/pkg/util"
 	"github.com/damnever/sunflower/version"
@@ -26,38 +30,103 @@ type ClientHandler interface {
 	HandleNewTunnelRequest(req *msgpb.NewTunnelRequest) *msgpb.NewTunnelResponse
 	HandleCloseTunnelRequest(req *msgpb.CloseTunnelRequest) *msgpb.CloseTunnelResponse
 	HandleShutdownRequest(req *msgpb.ShutdownRequest) bool
+	HandlePunchRequest(req *msgpb.PunchRequest)
+	HandleDeclareTunnelsResponse(resp *msgpb.DeclareTunnelsResponse)
+	// HandleConnected is called after the handshake of every connection.
+	HandleConnected()
 	HandleUnknownMessage(m interface{})
 	HandleError(err error)
 }
 
 type ClientConfig struct {
-	ID                string
-	Hash              string
-	RemoteAddr        string
+	ID         string
+	Hash       string
+	RemoteAddr string // Used if Resolve is nil
+	// Resolve returns the server addresses in order of preference, they are
+	// tried one by one until connected.
+	Resolve           func() ([]string, error)
 	HeartbeatInterval time.Duration
 	Timeout           util.TimeoutConfig
 	Retrier           *retry.Retrier
 	TLSConf           *tls.Config
+	Dial              dialer.DialFunc // Connects directly if nil
+}
+
+// ClientStatus describes the state of control connection.
+type ClientStatus struct {
+	Connected   bool          `json:"connected"`
+	RemoteAddr  string        `json:"remote_addr"`
+	ConnectedAt time.Time     `json:"connected_at"`
+	RTT         time.Duration `json:"rtt"` // The round-trip time of the last heartbeat
+	Reconnects  int           `json:"reconnects"`
 }
 
 type Client struct {
-	config *ClientConfig
-	conn   net.Conn
-	closed chan struct{}
+	mu      sync.RWMutex
+	config  *ClientConfig
+	conn    net.Conn
+	regAddr string
+	status  ClientStatus
+	out     chan interface{}
+	closed  chan struct{}
+	ctx     context.Context // Canceled after closed, stops reconnecting
+	cancel  context.CancelFunc
 }
 
+// Reconnecting makes no sense if the server rejects the agent itself.
+var (
+	errBadClient  = msg.CodeToError(msgpb.ErrCodeBadClient)
+	errBadVersion = msg.CodeToError(msgpb.ErrCodeBadVersion)
+)
+
 func NewClient(conf *ClientConfig) (*Client, error) {
-	conn, err := connectAndHandshake(conf)
+	conn, remoteAddr, regAddr, err := connectAny(conf, "", "")
 	if err != nil {
 		return nil, err
 	}
+	ctx, cancel := context.WithCancel(context.Background())
 	return &Client{
-		config: conf,
-		conn:   conn,
+		config:  conf,
+		conn:    conn,
+		regAddr: regAddr,
+		status: ClientStatus{
+			Connected:   true,
+			RemoteAddr:  remoteAddr,
+			ConnectedAt: time.Now(),
+		},
+		out:    make(chan interface{}, outChSize),
 		closed: make(chan struct{}),
+		ctx:    ctx,
+		cancel: cancel,
 	}, nil
 }
 
+// Status returns the current state of control connection.
+func (cli *Client) Status() ClientStatus {
+	cli.mu.RLock()
+	defer cli.mu.RUnlock()
+	return cli.status
+}
+
+// RegistryAddr returns the tunnel registry address told by server,
+// it may change after reconnected.
+func (cli *Client) RegistryAddr() string {
+	cli.mu.RLock()
+	defer cli.mu.RUnlock()
+	return cli.regAddr
+}
+
+// Send sends a message to server, it blocks if the connection is reconnecting,
+// false will be returned if the client has been closed.
+func (cli *Client) Send(m interface{}) bool {
+	select {
+	case <-cli.closed:
+		return false
+	case cli.out <- m:
+		return true
+	}
+}
+
 // Run starts communicating with server, do reconnecting and requests dispatching logic.
 func (cli *Client) Run(handler ClientHandler) error {
 	pingReq := &msgpb.PingRequest{}
@@ -69,76 +138,211 @@ func (cli *Client) Run(handler ClientHandler) error {
 
 	ticker := time.NewTicker(conf.HeartbeatInterval)
 	defer ticker.Stop()
-	tryConnect := tryConnectFunc(conf, cli.closed)
+	tryConnect := cli.tryConnectFunc()
+	var pingT time.Time
+	go handler.HandleConnected()
+
+	// avoid is tried at last since the server asked to reconnect elsewhere,
+	// prefer is the one it suggested, delay is the time it asked to wait.
+	reconnect := func(avoid, prefer string, delay time.Duration) error {
+		conn.Close()
+		cli.mu.Lock()
+		cli.status.Connected = false
+		cli.mu.Unlock()
+		if delay > 0 {
+			// Spread the agents which are told the same delay.
+			delay += time.Duration(rand.Int63n(int64(delay)/2 + 1))
+			select {
+			case <-cli.closed:
+				return context.Canceled
+			case <-time.After(delay):
+			}
+		}
+		rawConn, err := tryConnect(avoid, prefer)
+		if err != nil {
+			return err
+		}
+		conn = NewConn(rawConn, conf.Timeout.Read, conf.Timeout.Write)
+		conn.Go()
+		pingT = time.Time{}
+		go handler.HandleConnected()
+		return nil
+	}
+	recoverConn := func(err error) error {
+		if nerr, ok := err.(net.Error); ok && nerr.Temporary() {
+			return nil
+		}
+		handler.HandleError(err)
+		return reconnect("", "", 0)
+	}
+	// forward sends m unless the connection is broken meanwhile, nothing
+	// drains the outgoing messages after that, it is recovered instead.
+	forward := func(m interface{}) error {
+		select {
+		case conn.Out() <- m:
+			return nil
+		case err := <-conn.Err():
+			return recoverConn(err)
+		case <-cli.closed:
+			return context.Canceled
+		}
+	}
 
 LOOP:
 	for {
+		var fatalErr error
 		select {
 		case <-cli.closed:
 			return nil
 		case err := <-conn.Err():
-			if nerr, ok := err.(net.Error); ok && nerr.Temporary() {
-				continue LOOP
-			}
-			handler.HandleError(err)
-			conn.Close()
-			rawConn, fatalErr := tryConnect()
-			if fatalErr != nil {
-				if fatalErr == retry.ErrCanceled {
-					return nil
-				}
-				return fatalErr
-			}
-			conn = NewConn(rawConn, conf.Timeout.Read, conf.Timeout.Write)
-			conn.Go()
+			fatalErr = recoverConn(err)
 
 		case m := <-conn.In():
 			switch x := m.(type) {
 			case *msgpb.PingResponse:
+				if !pingT.IsZero() {
+					cli.mu.Lock()
+					cli.status.RTT = time.Since(pingT)
+					cli.mu.Unlock()
+					pingT = time.Time{}
+				}
 				go handler.HandlePingResponse(x)
 			case *msgpb.NewTunnelRequest:
-				go func() { conn.Out() <- handler.HandleNewTunnelRequest(x) }()
+				go func() { cli.Send(handler.HandleNewTunnelRequest(x)) }()
 			case *msgpb.CloseTunnelRequest:
-				go func() { conn.Out() <- handler.HandleCloseTunnelRequest(x) }()
+				go func() { cli.Send(handler.HandleCloseTunnelRequest(x)) }()
+			case *msgpb.PunchRequest:
+				go handler.HandlePunchRequest(x)
+			case *msgpb.DeclareTunnelsResponse:
+				go handler.HandleDeclareTunnelsResponse(x)
 			case *msgpb.ShutdownRequest:
 				if handler.HandleShutdownRequest(x) {
 					return nil
 				}
+				if !x.Reconnect {
+					continue LOOP
+				}
+				avoid, prefer := cli.Status().RemoteAddr, x.ReconnectAddr
+				if x.Restart && prefer == "" {
+					avoid, prefer = "", avoid
+				}
+				delay := time.Duration(x.ReconnectDelay) * time.Millisecond
+				fatalErr = reconnect(avoid, prefer, delay)
 			default:
 				go handler.HandleUnknownMessage(x)
 			}
 
+		case m := <-cli.out:
+			fatalErr = forward(m)
+
 		case <-ticker.C:
-			conn.Out() <- pingReq
+			pingT = time.Now() // Reset if reconnected
+			fatalErr = forward(pingReq)
+		}
+
+		if fatalErr != nil {
+			if fatalErr == context.Canceled {
+				return nil
+			}
+			return fatalErr
 		}
 	}
 }
 
 func (cli *Client) Close() error {
 	close(cli.closed)
+	cli.cancel()
 	return cli.conn.Close()
 }
 
-func tryConnectFunc(conf *ClientConfig, closed chan struct{}) func() (net.Conn, error) {
-	return func() (conn net.Conn, err error) {
-		conf.Retrier.Run(func() error {
-			select {
-			case <-closed:
-				err = retry.ErrCanceled
-				return err
-			default:
+func (cli *Client) tryConnectFunc() func(avoid, prefer string) (net.Conn, error) {
+	conf := cli.config
+	return func(avoid, prefer string) (conn net.Conn, err error) {
+		err = conf.Retrier.RunContext(cli.ctx, func(context.Context) error {
+			var remoteAddr, regAddr string
+			var cerr error
+			conn, remoteAddr, regAddr, cerr = connectAny(conf, avoid, prefer)
+			if cerr == errBadClient || cerr == errBadVersion {
+				return retry.Stop(cerr)
 			}
-			conn, err = connectAndHandshake(conf)
-			return err
+			if cerr != nil {
+				return cerr
+			}
+			cli.mu.Lock()
+			cli.regAddr = regAddr
+			cli.status.Connected = true
+			cli.status.RemoteAddr = remoteAddr
+			cli.status.ConnectedAt = time.Now()
+			cli.status.Reconnects++
+			cli.mu.Unlock()
+			return nil
 		})
 		return
 	}
 }
 
-func connectAndHandshake(conf *ClientConfig) (net.Conn, error) {
-	conn, err := net.DialTimeout("tcp", conf.RemoteAddr, conf.Timeout.Connect)
+// connectAny tries the server addresses in order until connected, prefer
+// is tried first and avoid is tried at last if they are not empty.
+func connectAny(conf *ClientConfig, avoid, prefer string) (net.Conn, string, string, error) {
+	addrs := []string{conf.RemoteAddr}
+	if conf.Resolve != nil {
+		resolved, err := conf.Resolve()
+		if err != nil {
+			return nil, "", "", err
+		}
+		addrs = resolved
+	}
+	addrs = reorderAddrs(addrs, avoid, prefer)
+	if len(addrs) == 0 {
+		return nil, "", "", fmt.Errorf("no server address available")
+	}
+
+	var lastErr error
+	for _, addr := range addrs {
+		conn, regAddr, err := connectAndHandshake(conf, addr)
+		if err == nil {
+			return conn, addr, regAddr, nil
+		}
+		if err == errBadClient || err == errBadVersion {
+			return nil, "", "", err
+		}
+		lastErr = err
+		if len(addrs) > 1 {
+			lastErr = fmt.Errorf("%s: %v", addr, err)
+		}
+	}
+	return nil, "", "", lastErr
+}
+
+func reorderAddrs(addrs []string, avoid, prefer string) []string {
+	ordered := make([]string, 0, len(addrs)+1)
+	if prefer != "" {
+		ordered = append(ordered, prefer)
+	}
+	avoided := false
+	for _, addr := range addrs {
+		switch addr {
+		case prefer:
+		case avoid:
+			avoided = true
+		default:
+			ordered = append(ordered, addr)
+		}
+	}
+	if avoided && avoid != prefer {
+		ordered = append(ordered, avoid)
+	}
+	return ordered
+}
+
+func connectAndHandshake(conf *ClientConfig, addr string) (net.Conn, string, error) {
+	dial := conf.Dial
+	if dial == nil {
+		dial = net.DialTimeout
+	}
+	conn, err := dial("tcp", addr, conf.Timeout.Connect)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	req := &msgpb.HandshakeRequest{
@@ -149,16 +353,19 @@ func connectAndHandshake(conf *ClientConfig) (net.Conn, error) {
 	}
 	conn.SetWriteDeadline(time.Now().Add(conf.Timeout.Write))
 	if err := msg.Write(conn, req); err != nil {
-		return nil, err
+		conn.Close()
+		return nil, "", err
 	}
 
 	conn.SetReadDeadline(time.Now().Add(conf.Timeout.Read))
 	var resp msgpb.HandshakeResponse
 	if err = msg.ReadTo(conn, &resp); err != nil {
-		return nil, err
+		conn.Close()
+		return nil, "", err
 	}
 	if err = msg.CodeToError(resp.ErrCode); err != nil {
-		return nil, err
+		conn.Close()
+		return nil, "", err
 	}
-	return conn, nil
+	return conn, resp.RegistryAddr, nil
 }
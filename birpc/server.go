@@ -14,9 +14,11 @@ type ValidateFunc func(id, hash, device, version string) msgpb.ErrCode
 
 type ServerConfig struct {
 	ListenAddr   string
+	Listener     net.Listener // Listens on ListenAddr if nil
 	Timeout      util.TimeoutConfig
 	TLSConf      *tls.Config
 	ValidateFunc ValidateFunc
+	RegistryAddr string // Told to clients after handshake
 }
 
 type Server struct {
@@ -27,10 +29,14 @@ type Server struct {
 }
 
 func NewServer(conf *ServerConfig) (*Server, error) {
-	l, err := net.Listen("tcp", conf.ListenAddr)
-	// l, err := tls.Listen("tcp", conf.ListenAddr, conf.TLSConf)
-	if err != nil {
-		return nil, err
+	l := conf.Listener
+	if l == nil {
+		var err error
+		l, err = net.Listen("tcp", conf.ListenAddr)
+		// l, err := tls.Listen("tcp", conf.ListenAddr, conf.TLSConf)
+		if err != nil {
+			return nil, err
+		}
 	}
 	return &Server{
 		l:      l,
@@ -57,6 +63,11 @@ func (s *Server) Serve() error {
 	}
 }
 
+// HandleConn serves the connection accepted elsewhere, e.g. over WebSocket.
+func (s *Server) HandleConn(conn net.Conn) {
+	s.handleConn(conn)
+}
+
 func (s *Server) Clients() <-chan *ClientConn {
 	return s.cliCh
 }
@@ -73,6 +84,9 @@ func (s *Server) handleConn(conn net.Conn) {
 	resp := msgpb.HandshakeResponse{
 		ErrCode: conf.ValidateFunc(req.ID, req.Hash, req.Device, req.Version),
 	}
+	if resp.ErrCode == msgpb.ErrCodeNull {
+		resp.RegistryAddr = conf.RegistryAddr
+	}
 
 	conn.SetReadDeadline(time.Now().Add(conf.Timeout.Write))
 	if err := msg.Write(conn, &resp); err != nil {
@@ -1,6 +1,7 @@
 package flower
 
 import (
+	"crypto/ed25519"
 	"crypto/tls"
 	"fmt"
 	"io/ioutil"
@@ -12,14 +13,21 @@ import (
 	"github.com/kardianos/osext"
 
 	"github.com/damnever/sunflower/birpc"
+	"github.com/damnever/sunflower/pkg/dialer"
 	"github.com/damnever/sunflower/pkg/retry"
+	"github.com/damnever/sunflower/pkg/selfupdate"
 	"github.com/damnever/sunflower/pkg/util"
 )
 
 type Config struct {
 	ID                string
 	Hash              string
-	ControlServer     string
+	ControlServers    []string // Fail over in order, srv://name is looked up from DNS SRV records
+	WebSocketServer   string   // Connects over WebSocket instead if not empty, ws:// or wss://
+	StatusAddr        string   // Serves the status of agent if not empty
+	UpdateServer      string   // Where to download the new version if incompatible
+	UpdateKey         ed25519.PublicKey
+	Proxy             string // The URL of HTTP CONNECT or SOCKS5 proxy to reach server
 	HeartbeatInterval time.Duration
 	Timeout           struct {
 		GracefulShutdown time.Duration
@@ -27,7 +35,30 @@ type Config struct {
 		Tunnel           util.TimeoutConfig
 		Local            util.TimeoutConfig
 	}
-	Retrier *retry.Retrier
+	HealthCheck struct {
+		Interval time.Duration
+		Timeout  time.Duration
+	}
+	Retrier  *retry.Retrier
+	Visitors []VisitorConfig
+	Tunnels  []TunnelConfig
+}
+
+// TunnelConfig describes a tunnel declared by agent, which will be created
+// in sun if it does not exist, the tag identifies the tunnel.
+type TunnelConfig struct {
+	Tag        string `json:"tag"`
+	Proto      string `json:"proto"`
+	ExportAddr string `json:"export_addr"`
+	ServerAddr string `json:"server_addr"` // Port, auto or subdomain, the same as control panel
+}
+
+// VisitorConfig describes how to visit a secret tunnel.
+type VisitorConfig struct {
+	Tunnel    string `json:"tunnel"` // The hash of secret tunnel
+	SecretKey string `json:"secret_key"`
+	BindAddr  string `json:"bind_addr"` // The local address to listen on
+	Direct    bool   `json:"direct"`    // Try peer-to-peer mode first
 }
 
 func loadConfigFromExec() ([]byte, error) {
@@ -53,17 +84,64 @@ func loadConfigFromExec() ([]byte, error) {
 	return nil, fmt.Errorf("no config found from: %v", exPath)
 }
 
-func buildConfig(rawConf cc.Configer) *Config {
+func buildConfig(rawConf cc.Configer) (*Config, error) {
 	conf := &Config{}
 	conf.ID = rawConf.String("id")
 	conf.Hash = rawConf.String("hash")
-	conf.ControlServer = rawConf.String("control_server")
+	for _, server := range rawConf.Value("control_servers").List() {
+		conf.ControlServers = append(conf.ControlServers, server.String())
+	}
+	if len(conf.ControlServers) == 0 {
+		conf.ControlServers = []string{rawConf.String("control_server")}
+	}
+	conf.WebSocketServer = rawConf.String("websocket_server")
+	conf.StatusAddr = rawConf.StringOr("status_addr", defaultStatusAddr)
+	conf.UpdateServer = rawConf.String("update_server")
+	if rawKey := rawConf.String("update_key"); rawKey != "" {
+		key, err := selfupdate.ParsePublicKey(rawKey)
+		if err != nil {
+			return nil, fmt.Errorf("update_key: %v", err)
+		}
+		conf.UpdateKey = key
+	}
+	conf.Proxy = rawConf.String("proxy")
+	if conf.Proxy == dialer.Env {
+		conf.Proxy = dialer.FromEnvironment()
+	}
 	conf.HeartbeatInterval = rawConf.DurationAndOr("heartbeat_interval", "N>=3", 3) * time.Second
 
 	retryC := rawConf.Config("retry")
 	backoff := retryC.DurationAndOr("backoff", "N>=100", 500) * time.Millisecond
-	max := retryC.IntAndOr("max", "N>=3", 10)
-	conf.Retrier = retry.New(backoff, max)
+	maxBackoff := retryC.DurationAndOr("max_backoff", "N>=1000", 30000) * time.Millisecond
+	max := retryC.IntAndOr("max", "N==0||N>=3", 0)
+	if max == 0 {
+		max = retry.Forever
+	}
+	conf.Retrier = retry.NewExponential(backoff, maxBackoff, max)
+
+	healthC := rawConf.Config("health_check")
+	conf.HealthCheck.Interval = healthC.DurationAndOr("interval", "N>=3", 10) * time.Second
+	conf.HealthCheck.Timeout = healthC.DurationAndOr("timeout", "N>=100", 2000) * time.Millisecond
+
+	for _, v := range rawConf.Value("visitors").List() {
+		visitorC := v.Config()
+		conf.Visitors = append(conf.Visitors, VisitorConfig{
+			Tunnel:    visitorC.String("tunnel"),
+			SecretKey: visitorC.String("secret_key"),
+			BindAddr:  visitorC.String("bind_addr"),
+			Direct:    visitorC.BoolOr("direct", true),
+		})
+	}
+
+	for _, v := range rawConf.Value("tunnels").List() {
+		tunnelC := v.Config()
+		conf.Tunnels = append(conf.Tunnels, TunnelConfig{
+			Tag:        tunnelC.String("tag"),
+			Proto:      tunnelC.String("proto"),
+			ExportAddr: tunnelC.String("export_addr"),
+			ServerAddr: tunnelC.String("server_addr"),
+		})
+	}
 
 	timeoutC := rawConf.Config("timeout")
 	conf.Timeout.GracefulShutdown = timeoutC.DurationAndOr("graceful_timeout", "N>0", 3) * time.Second
@@ -90,17 +168,18 @@ func buildConfig(rawConf cc.Configer) *Config {
 		Write:   localC.DurationAndOr("connect", "N>0", 100) * time.Millisecond,
 	}
 
-	return conf
+	return conf, nil
 }
 
-func (conf *Config) BuildRPCClientConf() *birpc.ClientConfig {
+func (conf *Config) BuildRPCClientConf(dial dialer.DialFunc, resolve func() ([]string, error)) *birpc.ClientConfig {
 	rpcconf := &birpc.ClientConfig{}
 	rpcconf.ID = conf.ID
 	rpcconf.Hash = conf.Hash
-	rpcconf.RemoteAddr = conf.ControlServer
+	rpcconf.Resolve = resolve
 	rpcconf.HeartbeatInterval = conf.HeartbeatInterval
 	rpcconf.Retrier = conf.Retrier
 	rpcconf.Timeout = conf.Timeout.Control
 	rpcconf.TLSConf = &tls.Config{}
+	rpcconf.Dial = dial
 	return rpcconf
 }
@@ -1,6 +1,7 @@
 package flower
 
 import (
+	"fmt"
 	"net"
 	"sync"
 	"time"
@@ -17,33 +18,72 @@ type Controler struct {
 	sync.RWMutex
 	sync.WaitGroup
 
-	conf    *Config
-	client  *birpc.Client
-	proxies map[string]*TCPProxy
-	logger  *zap.SugaredLogger
+	conf      *Config
+	transport *transport
+	client    *birpc.Client
+	proxies   map[string]*TCPProxy
+	opening   map[string]bool // The tunnels being registered, false if closed meanwhile
+	visitors  []*Visitor
+	declared  map[string]*msgpb.DeclaredTunnelResult // Indexed by tag
+	errors    *recentErrors
+	logger    *zap.SugaredLogger
+	startedAt time.Time
 }
 
 func NewControler(conf *Config) (*Controler, error) {
-	client, err := birpc.NewClient(conf.BuildRPCClientConf())
+	transport, err := newTransport(conf)
 	if err != nil {
 		return nil, err
 	}
-	return &Controler{
-		conf:    conf,
-		client:  client,
-		proxies: map[string]*TCPProxy{},
-		logger:  log.New("ctl[%s]", conf.Hash),
-	}, nil
+	logger := log.New("ctl[%s]", conf.Hash)
+	errs := &recentErrors{}
+	rpcconf := conf.BuildRPCClientConf(transport.dialControl, transport.resolveControl)
+	rpcconf.Retrier = conf.Retrier.WithHook(func(attempt int, err error, wait time.Duration) {
+		if wait == 0 { // Reported by the caller
+			return
+		}
+		logger.Warnf("Reconnect to control server failed(attempt %d): %v, retry in %v", attempt, err, wait)
+		errs.Add("control", err)
+	})
+	client, err := birpc.NewClient(rpcconf)
+	if err != nil {
+		return nil, err
+	}
+	c := &Controler{
+		conf:      conf,
+		transport: transport,
+		client:    client,
+		proxies:   map[string]*TCPProxy{},
+		opening:   map[string]bool{},
+		declared:  map[string]*msgpb.DeclaredTunnelResult{},
+		errors:    errs,
+		logger:    logger,
+		startedAt: time.Now(),
+	}
+	for _, vconf := range conf.Visitors {
+		visitor, err := NewVisitor(vconf, c)
+		if err != nil {
+			c.closeVisitors()
+			client.Close()
+			return nil, fmt.Errorf("visitor(%s): %v", vconf.Tunnel, err)
+		}
+		c.visitors = append(c.visitors, visitor)
+	}
+	return c, nil
 }
 
 func (c *Controler) Run() error {
 	errCh := make(chan error, 1)
 	go func() { errCh <- c.client.Run(c) }()
+	for _, visitor := range c.visitors {
+		go visitor.Serve()
+	}
 	sigCh := util.WatchSignals()
 
 	select {
 	case err := <-errCh:
 		if err != nil {
+			c.closeVisitors()
 			return err
 		}
 	case sig := <-sigCh:
@@ -51,6 +91,7 @@ func (c *Controler) Run() error {
 	}
 
 	c.client.Close()
+	c.closeVisitors()
 	c.logger.Info("Graceful shutdown..")
 	// graceful shutdown
 	done := make(chan struct{})
@@ -65,15 +106,60 @@ func (c *Controler) Run() error {
 	return nil
 }
 
+func (c *Controler) closeVisitors() {
+	for _, visitor := range c.visitors {
+		visitor.Close()
+	}
+}
+
 func (c *Controler) closeProxy(tunnelhash string) {
 	c.Lock()
 	if proxy, in := c.proxies[tunnelhash]; in {
 		delete(c.proxies, tunnelhash)
 		proxy.Close()
 	}
+	if _, in := c.opening[tunnelhash]; in {
+		c.opening[tunnelhash] = false
+	}
+	c.Unlock()
+}
+
+func (c *Controler) closeProxies() {
+	c.Lock()
+	for thash, prx := range c.proxies {
+		delete(c.proxies, thash) // it does not free up memory
+		prx.Close()
+	}
+	for thash := range c.opening {
+		c.opening[thash] = false
+	}
 	c.Unlock()
 }
 
+// drainProxies takes the proxies out, the ones opened next serve the new
+// connections, the in-flight streams go on over the previous ones.
+func (c *Controler) drainProxies() {
+	c.Lock()
+	for thash, prx := range c.proxies {
+		delete(c.proxies, thash)
+		prx.Drain(drainTimeout)
+	}
+	for thash := range c.opening {
+		c.opening[thash] = false
+	}
+	c.Unlock()
+}
+
+// removeProxy removes the proxy if it is not replaced by a new one.
+func (c *Controler) removeProxy(tunnelhash string, proxy *TCPProxy) {
+	c.Lock()
+	if c.proxies[tunnelhash] == proxy {
+		delete(c.proxies, tunnelhash)
+	}
+	c.Unlock()
+	proxy.Close()
+}
+
 func (c *Controler) HandlePingResponse(req *msgpb.PingResponse) {
 	c.logger.Debug("Received heartbeat response")
 }
@@ -87,24 +173,48 @@ func (c *Controler) HandleNewTunnelRequest(req *msgpb.NewTunnelRequest) *msgpb.N
 	}
 
 	c.Lock()
-	defer c.Unlock()
-
-	if _, in := c.proxies[req.TunnelHash]; in {
-		c.logger.Debugf("Tunnel %s already registered", req.TunnelHash)
+	if proxy, in := c.proxies[req.TunnelHash]; in {
+		if proxy.registryAddr == req.RegistryAddr {
+			c.Unlock()
+			c.logger.Debugf("Tunnel %s already registered", req.TunnelHash)
+			return resp
+		}
+		// Failed over to another control server, the registry of previous one is gone.
+		c.logger.Infof("Tunnel %s moved to registry %s", req.TunnelHash, req.RegistryAddr)
+		delete(c.proxies, req.TunnelHash)
+		proxy.Close()
+	}
+	if _, in := c.opening[req.TunnelHash]; in {
+		c.Unlock()
+		c.logger.Debugf("Tunnel %s is being registered", req.TunnelHash)
 		return resp
 	}
+	c.opening[req.TunnelHash] = true
+	c.Unlock()
 
+	// The registration may be retried, the other requests should not wait for it.
 	proxy, err := NewTCPProxy(req, c) // bad practice? fuck me..
+
+	c.Lock()
+	defer c.Unlock()
+	wanted := c.opening[req.TunnelHash]
+	delete(c.opening, req.TunnelHash)
 	if err != nil {
+		c.errors.Add("tunnel "+req.TunnelHash, err)
 		c.logger.Errorf("Failed to create local proxy(%8s): %s://%s", req.TunnelHash, req.Proto, req.ExportAddr)
 		resp.ErrCode = msgpb.ErrCodeBadRegistryAddr
 		return resp
 	}
+	if !wanted {
+		c.logger.Infof("Tunnel %s closed while registering", req.TunnelHash)
+		proxy.Close()
+		return resp
+	}
 	c.logger.Infof("New tunnel %s registered", req.TunnelHash)
 
 	c.proxies[req.TunnelHash] = proxy
 	go func() {
-		defer c.closeProxy(req.TunnelHash)
+		defer c.removeProxy(req.TunnelHash, proxy)
 		proxy.Serve()
 	}()
 	return resp
@@ -123,11 +233,74 @@ func (c *Controler) HandleCloseTunnelRequest(req *msgpb.CloseTunnelRequest) *msg
 	return resp
 }
 
+func (c *Controler) HandlePunchRequest(req *msgpb.PunchRequest) {
+	if req.ID != c.conf.ID || req.ClientHash != c.conf.Hash {
+		c.logger.Warnf("Bad punch request: %+v", req)
+		return
+	}
+	c.RLock()
+	proxy, in := c.proxies[req.TunnelHash]
+	c.RUnlock()
+	if !in {
+		c.logger.Warnf("No tunnel to punch: %s", req.TunnelHash)
+		return
+	}
+	proxy.Punch(req)
+}
+
+// HandleConnected declares the tunnels in config, sun creates the
+// missing ones and opens them as usual.
+func (c *Controler) HandleConnected() {
+	if len(c.conf.Tunnels) == 0 {
+		return
+	}
+	req := &msgpb.DeclareTunnelsRequest{
+		ID:         c.conf.ID,
+		ClientHash: c.conf.Hash,
+	}
+	for _, tconf := range c.conf.Tunnels {
+		req.Tunnels = append(req.Tunnels, &msgpb.DeclaredTunnel{
+			Tag:        tconf.Tag,
+			Proto:      tconf.Proto,
+			ExportAddr: tconf.ExportAddr,
+			ServerAddr: tconf.ServerAddr,
+		})
+	}
+	c.client.Send(req)
+}
+
+func (c *Controler) HandleDeclareTunnelsResponse(resp *msgpb.DeclareTunnelsResponse) {
+	c.Lock()
+	defer c.Unlock()
+	for _, result := range resp.Results {
+		if result.Accepted {
+			c.logger.Infof("Declared tunnel %s accepted: %s(%s)", result.Tag, result.TunnelHash, result.ServerAddr)
+		} else {
+			c.logger.Errorf("Declared tunnel %s rejected: %s", result.Tag, result.Reason)
+			c.errors.Add("declare "+result.Tag, fmt.Errorf("%s", result.Reason))
+		}
+		c.declared[result.Tag] = result
+	}
+}
+
 func (c *Controler) HandleShutdownRequest(req *msgpb.ShutdownRequest) bool {
 	if req.ID != c.conf.ID || req.ClientHash != c.conf.Hash {
 		c.logger.Warnf("Bad shutdown request: %+v", req)
 		return false
 	}
+	if req.Reconnect {
+		delay := time.Duration(req.ReconnectDelay) * time.Millisecond
+		if req.Restart {
+			c.logger.Infof("Control server is restarting, reconnect in %v", delay)
+		} else {
+			c.logger.Infof("Asked to reconnect to %q in %v, other control servers are tried if empty", req.ReconnectAddr, delay)
+		}
+		// The tunnels belong to the server going away, the one connected
+		// next opens them again, even if it listens on the same address,
+		// the in-flight streams are drained by the previous one.
+		c.drainProxies()
+		return false
+	}
 	c.logger.Infof("Shutdown request received")
 	return true
 }
@@ -141,6 +314,7 @@ func (c *Controler) HandleError(err error) {
 		return
 	}
 	c.logger.Errorf("Error: %+v", err)
+	c.errors.Add("control", err)
 	if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
 		return
 	}
@@ -152,10 +326,5 @@ func (c *Controler) HandleError(err error) {
 		if opErr.Err == syscall.ECONNABORTED {
 		}
 	*/
-	c.Lock()
-	for thash, prx := range c.proxies {
-		delete(c.proxies, thash) // it does not free up memory
-		prx.Close()
-	}
-	c.Unlock()
+	c.closeProxies()
 }
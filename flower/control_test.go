@@ -0,0 +1,209 @@
+package flower
+
+import (
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/yamux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/damnever/sunflower/log"
+	"github.com/damnever/sunflower/msg"
+	"github.com/damnever/sunflower/msg/msgpb"
+	"github.com/damnever/sunflower/pkg/retry"
+)
+
+// fakeRegistry accepts the tunnels, the sessions are sent to the channel.
+func fakeRegistry(t *testing.T) (string, <-chan *yamux.Session) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	t.Cleanup(func() { l.Close() })
+	sessions := make(chan *yamux.Session, 4)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			var req msgpb.TunnelHandshakeRequest
+			if err := msg.ReadTo(conn, &req); err != nil {
+				conn.Close()
+				continue
+			}
+			if err := msg.Write(conn, &msgpb.TunnelHandshakeResponse{}); err != nil {
+				conn.Close()
+				continue
+			}
+			session, err := yamux.Server(conn, yamux.DefaultConfig())
+			if err != nil {
+				conn.Close()
+				continue
+			}
+			t.Cleanup(func() { session.Close() })
+			sessions <- session
+		}
+	}()
+	return l.Addr().String(), sessions
+}
+
+func testControler() *Controler {
+	conf := &Config{ID: "id", Hash: "hash"}
+	conf.Timeout.Tunnel.Connect = time.Second
+	conf.Timeout.Tunnel.Read = time.Second
+	conf.Timeout.Tunnel.Write = time.Second
+	conf.Retrier = retry.NewExponential(10*time.Millisecond, 100*time.Millisecond, 3)
+	return &Controler{
+		conf:      conf,
+		transport: &transport{dial: net.DialTimeout},
+		proxies:   map[string]*TCPProxy{},
+		opening:   map[string]bool{},
+		declared:  map[string]*msgpb.DeclaredTunnelResult{},
+		errors:    &recentErrors{},
+		logger:    log.New("ctl[test]"),
+	}
+}
+
+func waitSession(t *testing.T, sessions <-chan *yamux.Session) *yamux.Session {
+	select {
+	case session := <-sessions:
+		return session
+	case <-time.After(3 * time.Second):
+		t.Fatal("tunnel is not registered")
+	}
+	return nil
+}
+
+func TestControlerMovesTunnelToNewRegistry(t *testing.T) {
+	regAddr1, sessions1 := fakeRegistry(t)
+	regAddr2, sessions2 := fakeRegistry(t)
+	ctl := testControler()
+	defer ctl.HandleError(errors.New("done")) // Closes the proxies
+
+	req := &msgpb.NewTunnelRequest{
+		ID:           "id",
+		ClientHash:   "hash",
+		TunnelHash:   "tunnel",
+		Proto:        "REVERSE",
+		ExportAddr:   "127.0.0.1:0",
+		RegistryAddr: regAddr1,
+	}
+	resp := ctl.HandleNewTunnelRequest(req)
+	require.Equal(t, msgpb.ErrCodeNull, resp.ErrCode)
+	session1 := waitSession(t, sessions1)
+	ctl.RLock()
+	proxy1 := ctl.proxies["tunnel"]
+	ctl.RUnlock()
+
+	// The same control server asks again.
+	resp = ctl.HandleNewTunnelRequest(req)
+	require.Equal(t, msgpb.ErrCodeNull, resp.ErrCode)
+	ctl.RLock()
+	assert.True(t, proxy1 == ctl.proxies["tunnel"])
+	ctl.RUnlock()
+
+	// Failed over to another control server.
+	req2 := *req
+	req2.RegistryAddr = regAddr2
+	resp = ctl.HandleNewTunnelRequest(&req2)
+	require.Equal(t, msgpb.ErrCodeNull, resp.ErrCode)
+	waitSession(t, sessions2)
+	select {
+	case <-session1.CloseChan():
+	case <-time.After(3 * time.Second):
+		t.Fatal("tunnel to previous registry is not closed")
+	}
+
+	time.Sleep(100 * time.Millisecond) // The previous proxy stops serving
+	ctl.RLock()
+	proxy2, in := ctl.proxies["tunnel"]
+	ctl.RUnlock()
+	require.True(t, in)
+	assert.False(t, proxy1 == proxy2)
+	assert.Equal(t, regAddr2, proxy2.Status().RegistryAddr)
+	assert.Equal(t, "closed", proxy1.Status().Session)
+}
+
+func TestControlerReopensTunnelAfterServerRestart(t *testing.T) {
+	regAddr, sessions := fakeRegistry(t)
+	ctl := testControler()
+	defer ctl.HandleError(errors.New("done")) // Closes the proxies
+
+	req := &msgpb.NewTunnelRequest{
+		ID:           "id",
+		ClientHash:   "hash",
+		TunnelHash:   "tunnel",
+		Proto:        "REVERSE",
+		ExportAddr:   "127.0.0.1:0",
+		RegistryAddr: regAddr,
+	}
+	require.Equal(t, msgpb.ErrCodeNull, ctl.HandleNewTunnelRequest(req).ErrCode)
+	session1 := waitSession(t, sessions)
+	ctl.RLock()
+	proxy1 := ctl.proxies["tunnel"]
+	ctl.RUnlock()
+
+	// A stream is open before restarting.
+	local, err := net.Dial("tcp", proxy1.reverse.Addr().String())
+	require.Nil(t, err)
+	defer local.Close()
+	stream, err := session1.AcceptStream()
+	require.Nil(t, err)
+	defer stream.Close()
+	assertPass := func() {
+		local.SetDeadline(time.Now().Add(3 * time.Second))
+		stream.SetDeadline(time.Now().Add(3 * time.Second))
+		buf := make([]byte, 4)
+		_, err := local.Write([]byte("ping"))
+		require.Nil(t, err)
+		_, err = io.ReadFull(stream, buf)
+		require.Nil(t, err)
+		assert.Equal(t, "ping", string(buf))
+		_, err = stream.Write([]byte("pong"))
+		require.Nil(t, err)
+		_, err = io.ReadFull(local, buf)
+		require.Nil(t, err)
+		assert.Equal(t, "pong", string(buf))
+	}
+	assertPass()
+
+	stop := ctl.HandleShutdownRequest(&msgpb.ShutdownRequest{
+		ID:         "id",
+		ClientHash: "hash",
+		Reconnect:  true,
+		Restart:    true,
+	})
+	require.False(t, stop)
+	ctl.RLock()
+	assert.Len(t, ctl.proxies, 0)
+	ctl.RUnlock()
+	assertPass()
+
+	// The new process listens on the same address.
+	require.Equal(t, msgpb.ErrCodeNull, ctl.HandleNewTunnelRequest(req).ErrCode)
+	waitSession(t, sessions)
+	ctl.RLock()
+	proxy2 := ctl.proxies["tunnel"]
+	ctl.RUnlock()
+	require.NotNil(t, proxy2)
+	assert.False(t, proxy1 == proxy2)
+	assertPass()
+	select {
+	case <-session1.CloseChan():
+		t.Fatal("tunnel to restarting server is closed while draining")
+	default:
+	}
+
+	// Closed once drained.
+	local.Close()
+	stream.Close()
+	select {
+	case <-session1.CloseChan():
+	case <-time.After(3 * time.Second):
+		t.Fatal("tunnel to restarting server is not closed after drained")
+	}
+	assert.Equal(t, "established", proxy2.Status().Session)
+}
@@ -0,0 +1,90 @@
+package flower
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/damnever/sunflower/msg/msgpb"
+)
+
+const (
+	dialErrorReportInterval = 1 * time.Second
+)
+
+type dialErrorCount struct {
+	count  uint32
+	reason string
+}
+
+// dialErrorReporter aggregates the failures of connecting to local service,
+// then reports them to server at most once per dialErrorReportInterval.
+type dialErrorReporter struct {
+	sync.Mutex
+	req     *msgpb.NewTunnelRequest
+	send    func(m interface{}) bool
+	counts  map[msgpb.DialErrorClass]*dialErrorCount
+	pending bool
+}
+
+func newDialErrorReporter(req *msgpb.NewTunnelRequest, send func(m interface{}) bool) *dialErrorReporter {
+	return &dialErrorReporter{
+		req:    req,
+		send:   send,
+		counts: map[msgpb.DialErrorClass]*dialErrorCount{},
+	}
+}
+
+func (r *dialErrorReporter) Add(err error) {
+	class := classifyDialError(err)
+	r.Lock()
+	defer r.Unlock()
+
+	cnt, in := r.counts[class]
+	if !in {
+		cnt = &dialErrorCount{}
+		r.counts[class] = cnt
+	}
+	cnt.count++
+	cnt.reason = err.Error()
+	if !r.pending {
+		r.pending = true
+		time.AfterFunc(dialErrorReportInterval, r.flush)
+	}
+}
+
+func (r *dialErrorReporter) flush() {
+	r.Lock()
+	counts := r.counts
+	r.counts = map[msgpb.DialErrorClass]*dialErrorCount{}
+	r.pending = false
+	r.Unlock()
+
+	for class, cnt := range counts {
+		r.send(&msgpb.DialErrorReport{
+			ID:         r.req.ID,
+			ClientHash: r.req.ClientHash,
+			TunnelHash: r.req.TunnelHash,
+			Class:      class,
+			Reason:     cnt.reason,
+			Count:      cnt.count,
+		})
+	}
+}
+
+func classifyDialError(err error) msgpb.DialErrorClass {
+	if opErr, ok := err.(*net.OpError); ok {
+		if _, ok := opErr.Err.(*net.DNSError); ok {
+			return msgpb.DialErrorDNS
+		}
+	}
+	if nerr, ok := err.(net.Error); ok && nerr.Timeout() {
+		return msgpb.DialErrorTimeout
+	}
+	// The messages differ from platforms, but all of them contain "refused".
+	if strings.Contains(err.Error(), "refused") {
+		return msgpb.DialErrorRefused
+	}
+	return msgpb.DialErrorUnknown
+}
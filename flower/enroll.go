@@ -0,0 +1,117 @@
+package flower
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kardianos/osext"
+)
+
+const defaultConfigName = "flower.yaml"
+
+// defaultConfigPath is where the credentials of an enrolled agent
+// are stored, it is next to the executable.
+func defaultConfigPath() string {
+	dir, err := osext.ExecutableFolder()
+	if err != nil {
+		return defaultConfigName
+	}
+	return filepath.Join(dir, defaultConfigName)
+}
+
+type enrollResponse struct {
+	ID      string `json:"id"`
+	Hash    string `json:"hash"`
+	Config  string `json:"config"`
+	Message string `json:"message"` // Not empty if failed
+}
+
+// enroll exchanges the enrollment token for the credentials of agent,
+// then stores them into confPath.
+func enroll(confPath string, args []string) error {
+	fs := flag.NewFlagSet("enroll", flag.ExitOnError)
+	server := fs.String("server", "", "Address of control panel, e.g. http://sun.example.com:7777")
+	token := fs.String("token", "", "Enrollment token created in control panel.")
+	force := fs.Bool("f", false, "Overwrite the existing credentials.")
+	fs.Parse(args)
+
+	if *server == "" || *token == "" {
+		fs.Usage()
+		return fmt.Errorf("both server and token are required")
+	}
+	endpoint := strings.TrimSuffix(*server, "/")
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "http://" + endpoint
+	}
+
+	if !*force {
+		if _, err := os.Stat(confPath); err == nil {
+			return fmt.Errorf("%s already exists, use -f to overwrite it", confPath)
+		}
+	}
+
+	cli := &http.Client{Timeout: 10 * time.Second}
+	resp, err := cli.PostForm(endpoint+"/api/enroll", url.Values{"token": {*token}})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	result := &enrollResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil || resp.StatusCode != http.StatusCreated {
+		if result.Message != "" {
+			return fmt.Errorf("%s", result.Message)
+		}
+		return fmt.Errorf("unexpected response: %s", resp.Status)
+	}
+
+	if err := writeConfig(confPath, result.Config, *force); err != nil {
+		return err
+	}
+	fmt.Printf("Enrolled as agent %s of %s, credentials are stored in %s\n", result.Hash, result.ID, confPath)
+	return nil
+}
+
+// writeConfig writes the config into a temporary file in the same directory,
+// then moves it to confPath, so the existing config is kept if anything fails.
+func writeConfig(confPath string, config string, overwrite bool) error {
+	tmpf, err := ioutil.TempFile(filepath.Dir(confPath), "."+filepath.Base(confPath))
+	if err != nil {
+		return err
+	}
+	tmpPath := tmpf.Name()
+	defer os.Remove(tmpPath) // No-op if renamed
+	if _, err := tmpf.WriteString(config); err != nil {
+		tmpf.Close()
+		return err
+	}
+	if err := tmpf.Sync(); err != nil {
+		tmpf.Close()
+		return err
+	}
+	if err := tmpf.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpPath, 0600); err != nil {
+		return err
+	}
+
+	if overwrite {
+		return os.Rename(tmpPath, confPath)
+	}
+	// Link fails if the config has been created meanwhile.
+	if err := os.Link(tmpPath, confPath); err != nil {
+		if os.IsExist(err) {
+			return fmt.Errorf("%s already exists, use -f to overwrite it", confPath)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package flower
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/damnever/sunflower/msg/msgpb"
+)
+
+const (
+	fileAddrPrefix      = "file://"
+	fileServerAuthRealm = `Basic realm="sunflower"`
+)
+
+var errFileServerClosed = errors.New("file server closed")
+
+// fileServer serves a local directory over HTTP, the connections are the
+// streams from sun, ranges are supported by http.FileServer.
+type fileServer struct {
+	server *http.Server
+	conns  *streamListener
+}
+
+// newFileServer creates a fileServer, the directory is written with slashes,
+// e.g. file:///srv/share, or file://C:/share on Windows.
+func newFileServer(req *msgpb.NewTunnelRequest) (*fileServer, error) {
+	dir := filepath.FromSlash(req.ExportAddr[len(fileAddrPrefix):])
+	if !filepath.IsAbs(dir) {
+		return nil, fmt.Errorf("directory %s must be an absolute path", dir)
+	}
+	var fs http.FileSystem = http.Dir(dir)
+	if !req.FileListing {
+		fs = noListingFileSystem{fs}
+	}
+	handler := http.FileServer(fs)
+	if req.BasicAuthUser != "" {
+		handler = basicAuth(handler, req.BasicAuthUser, req.BasicAuthPassword)
+	}
+	return &fileServer{
+		server: &http.Server{Handler: handler, ReadHeaderTimeout: 30 * time.Second},
+		conns:  newStreamListener(req.ExportAddr),
+	}, nil
+}
+
+func (fs *fileServer) Serve() {
+	fs.server.Serve(fs.conns)
+}
+
+// ServeConn serves the connection, it returns after the connection closed.
+func (fs *fileServer) ServeConn(conn net.Conn) {
+	fs.conns.serve(conn)
+}
+
+func (fs *fileServer) Close() error {
+	fs.conns.Close()
+	return fs.server.Close()
+}
+
+// noListingFileSystem forbids listing directories which have no index.html.
+type noListingFileSystem struct {
+	http.FileSystem
+}
+
+func (fs noListingFileSystem) Open(name string) (http.File, error) {
+	f, err := fs.FileSystem.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if stat.IsDir() {
+		index, err := fs.FileSystem.Open(path.Join(name, "index.html"))
+		if err != nil {
+			f.Close()
+			return nil, os.ErrPermission
+		}
+		index.Close()
+	}
+	return f, nil
+}
+
+func basicAuth(handler http.Handler, user, password string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u, p, ok := r.BasicAuth()
+		userOK := subtle.ConstantTimeCompare([]byte(u), []byte(user)) == 1
+		passwordOK := subtle.ConstantTimeCompare([]byte(p), []byte(password)) == 1
+		if !ok || !userOK || !passwordOK {
+			w.Header().Set("WWW-Authenticate", fileServerAuthRealm)
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// streamListener turns the streams into connections of a listener,
+// serve blocks until the connection closed by the consumer.
+type streamListener struct {
+	sync.Mutex
+	addr   streamAddr
+	connCh chan net.Conn
+	done   chan struct{}
+	closed bool
+}
+
+func newStreamListener(name string) *streamListener {
+	return &streamListener{
+		addr:   streamAddr(name),
+		connCh: make(chan net.Conn),
+		done:   make(chan struct{}),
+	}
+}
+
+func (sl *streamListener) serve(conn net.Conn) {
+	cc := &closeNotifyConn{Conn: conn, closed: make(chan struct{})}
+	select {
+	case sl.connCh <- cc:
+	case <-sl.done:
+		conn.Close()
+		return
+	}
+	<-cc.closed
+}
+
+func (sl *streamListener) Accept() (net.Conn, error) {
+	select {
+	case <-sl.done:
+		return nil, errFileServerClosed
+	case conn := <-sl.connCh:
+		return conn, nil
+	}
+}
+
+func (sl *streamListener) Addr() net.Addr {
+	return sl.addr
+}
+
+func (sl *streamListener) Close() error {
+	sl.Lock()
+	defer sl.Unlock()
+	if !sl.closed {
+		sl.closed = true
+		close(sl.done)
+	}
+	return nil
+}
+
+type closeNotifyConn struct {
+	net.Conn
+	once   sync.Once
+	closed chan struct{}
+}
+
+func (c *closeNotifyConn) Close() error {
+	err := c.Conn.Close()
+	c.once.Do(func() { close(c.closed) })
+	return err
+}
+
+type streamAddr string
+
+func (a streamAddr) Network() string { return "stream" }
+func (a streamAddr) String() string  { return string(a) }
@@ -0,0 +1,333 @@
+package flower
+
+import (
+	"bufio"
+	"context"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/damnever/sunflower/msg/msgpb"
+	"github.com/damnever/sunflower/pkg/allowlist"
+)
+
+const (
+	socks5Version       = 0x05
+	socks5AuthVersion   = 0x01
+	socks5MethodUserPwd = 0x02
+	socks5NoMethods     = 0xff
+	socks5CmdConnect    = 0x01
+	socks5AtypIPv4      = 0x01
+	socks5AtypDomain    = 0x03
+	socks5AtypIPv6      = 0x04
+
+	socks5Succeeded        = 0x00
+	socks5GeneralFailure   = 0x01
+	socks5NotAllowed       = 0x02
+	socks5HostUnreachable  = 0x04
+	socks5ConnRefused      = 0x05
+	socks5CmdNotSupported  = 0x07
+	socks5AtypNotSupported = 0x08
+
+	forwardHandshakeTimeout   = 10 * time.Second
+	forwardResolveTimeout     = 3 * time.Second
+	httpProxyAuthRealm        = `Basic realm="sunflower"`
+	httpConnectionEstablished = "HTTP/1.1 200 Connection Established\r\n\r\n"
+)
+
+var (
+	errForwardAuth       = errors.New("bad proxy credentials")
+	errForwardNotAllowed = errors.New("destination is not allowed")
+)
+
+// forwardProxy serves SOCKS5 and HTTP CONNECT requests from streams,
+// clients must authenticate with the credentials, and the destinations
+// are limited by the allowlist.
+type forwardProxy struct {
+	allow    *allowlist.Allowlist
+	user     string
+	password string
+}
+
+func newForwardProxy(req *msgpb.NewTunnelRequest) (*forwardProxy, error) {
+	allow, err := allowlist.Parse(req.AllowCIDRs, req.AllowPorts)
+	if err != nil {
+		return nil, err
+	}
+	return &forwardProxy{
+		allow:    allow,
+		user:     req.ProxyUser,
+		password: req.ProxyPassword,
+	}, nil
+}
+
+// forwardRequest is an accepted request, Reply must be called with
+// the result of connecting to Dest.
+type forwardRequest struct {
+	net.Conn // Reads the data buffered during handshake first
+	Dest     string
+	Reply    func(err error) error
+}
+
+// Accept reads the request from conn, and replies if the request is invalid.
+func (fp *forwardProxy) Accept(conn net.Conn) (*forwardRequest, error) {
+	conn.SetDeadline(time.Now().Add(forwardHandshakeTimeout))
+	defer conn.SetDeadline(time.Time{})
+
+	rd := bufio.NewReader(conn)
+	first, err := rd.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+	var req *forwardRequest
+	if first[0] == socks5Version {
+		req, err = fp.acceptSOCKS5(conn, rd)
+	} else {
+		req, err = fp.acceptHTTPConnect(conn, rd)
+	}
+	if err != nil {
+		return nil, err
+	}
+	req.Conn = &bufferedConn{Conn: conn, rd: rd}
+	return req, nil
+}
+
+func (fp *forwardProxy) authenticate(user, password string) bool {
+	userOK := subtle.ConstantTimeCompare([]byte(user), []byte(fp.user)) == 1
+	passwordOK := subtle.ConstantTimeCompare([]byte(password), []byte(fp.password)) == 1
+	return userOK && passwordOK
+}
+
+// resolve resolves the host and picks the first address which is allowed,
+// so that the connection goes to the address which has been checked.
+func (fp *forwardProxy) resolve(host, port string) (string, error) {
+	portn, err := strconv.Atoi(port)
+	if err != nil {
+		return "", err
+	}
+	var ips []net.IP
+	if ip := net.ParseIP(host); ip != nil {
+		ips = []net.IP{ip}
+	} else {
+		ctx, cancel := context.WithTimeout(context.Background(), forwardResolveTimeout)
+		defer cancel()
+		addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return "", err
+		}
+		for _, addr := range addrs {
+			ips = append(ips, addr.IP)
+		}
+	}
+	for _, ip := range ips {
+		if fp.allow.Allow(ip, portn) {
+			return net.JoinHostPort(ip.String(), port), nil
+		}
+	}
+	return "", errForwardNotAllowed
+}
+
+// SOCKS5, ref: RFC 1928 and RFC 1929.
+func (fp *forwardProxy) acceptSOCKS5(w io.Writer, rd *bufio.Reader) (*forwardRequest, error) {
+	// Methods
+	var header [2]byte
+	if _, err := io.ReadFull(rd, header[:]); err != nil {
+		return nil, err
+	}
+	methods := make([]byte, header[1])
+	if _, err := io.ReadFull(rd, methods); err != nil {
+		return nil, err
+	}
+	if !bytesContain(methods, socks5MethodUserPwd) {
+		w.Write([]byte{socks5Version, socks5NoMethods})
+		return nil, errForwardAuth
+	}
+	if _, err := w.Write([]byte{socks5Version, socks5MethodUserPwd}); err != nil {
+		return nil, err
+	}
+
+	// Username/Password authentication
+	if _, err := io.ReadFull(rd, header[:]); err != nil {
+		return nil, err
+	}
+	user := make([]byte, header[1])
+	if _, err := io.ReadFull(rd, user); err != nil {
+		return nil, err
+	}
+	plen, err := rd.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	password := make([]byte, plen)
+	if _, err := io.ReadFull(rd, password); err != nil {
+		return nil, err
+	}
+	if !fp.authenticate(string(user), string(password)) {
+		w.Write([]byte{socks5AuthVersion, 0x01})
+		return nil, errForwardAuth
+	}
+	if _, err := w.Write([]byte{socks5AuthVersion, 0x00}); err != nil {
+		return nil, err
+	}
+
+	// Request
+	var reqHeader [4]byte
+	if _, err := io.ReadFull(rd, reqHeader[:]); err != nil {
+		return nil, err
+	}
+	if reqHeader[1] != socks5CmdConnect {
+		writeSOCKS5Reply(w, socks5CmdNotSupported)
+		return nil, fmt.Errorf("unsupported SOCKS5 command: %d", reqHeader[1])
+	}
+	var host string
+	switch reqHeader[3] {
+	case socks5AtypIPv4, socks5AtypIPv6:
+		ip := make(net.IP, net.IPv4len)
+		if reqHeader[3] == socks5AtypIPv6 {
+			ip = make(net.IP, net.IPv6len)
+		}
+		if _, err := io.ReadFull(rd, ip); err != nil {
+			return nil, err
+		}
+		host = ip.String()
+	case socks5AtypDomain:
+		n, err := rd.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		domain := make([]byte, n)
+		if _, err := io.ReadFull(rd, domain); err != nil {
+			return nil, err
+		}
+		host = string(domain)
+	default:
+		writeSOCKS5Reply(w, socks5AtypNotSupported)
+		return nil, fmt.Errorf("unsupported SOCKS5 address type: %d", reqHeader[3])
+	}
+	var port uint16
+	if err := binary.Read(rd, binary.BigEndian, &port); err != nil {
+		return nil, err
+	}
+
+	dest, err := fp.resolve(host, strconv.Itoa(int(port)))
+	if err != nil {
+		if err == errForwardNotAllowed {
+			writeSOCKS5Reply(w, socks5NotAllowed)
+		} else {
+			writeSOCKS5Reply(w, socks5HostUnreachable)
+		}
+		return nil, err
+	}
+	return &forwardRequest{
+		Dest: dest,
+		Reply: func(err error) error {
+			rep := byte(socks5Succeeded)
+			if err != nil {
+				rep = socks5GeneralFailure
+				if strings.Contains(err.Error(), "refused") {
+					rep = socks5ConnRefused
+				}
+			}
+			return writeSOCKS5Reply(w, rep)
+		},
+	}, nil
+}
+
+func writeSOCKS5Reply(w io.Writer, rep byte) error {
+	// The bound address is meaningless here.
+	_, err := w.Write([]byte{socks5Version, rep, 0x00, socks5AtypIPv4, 0, 0, 0, 0, 0, 0})
+	return err
+}
+
+func bytesContain(bs []byte, b byte) bool {
+	for _, e := range bs {
+		if e == b {
+			return true
+		}
+	}
+	return false
+}
+
+func (fp *forwardProxy) acceptHTTPConnect(w io.Writer, rd *bufio.Reader) (*forwardRequest, error) {
+	req, err := http.ReadRequest(rd)
+	if err != nil {
+		return nil, err
+	}
+	if req.Method != http.MethodConnect {
+		writeHTTPStatus(w, http.StatusMethodNotAllowed, nil)
+		return nil, fmt.Errorf("unsupported HTTP method: %s", req.Method)
+	}
+	user, password, ok := parseProxyAuth(req.Header.Get("Proxy-Authorization"))
+	if !ok || !fp.authenticate(user, password) {
+		writeHTTPStatus(w, http.StatusProxyAuthRequired, http.Header{"Proxy-Authenticate": {httpProxyAuthRealm}})
+		return nil, errForwardAuth
+	}
+	host, port, err := net.SplitHostPort(req.Host)
+	if err != nil {
+		writeHTTPStatus(w, http.StatusBadRequest, nil)
+		return nil, err
+	}
+	dest, err := fp.resolve(host, port)
+	if err != nil {
+		if err == errForwardNotAllowed {
+			writeHTTPStatus(w, http.StatusForbidden, nil)
+		} else {
+			writeHTTPStatus(w, http.StatusBadGateway, nil)
+		}
+		return nil, err
+	}
+	return &forwardRequest{
+		Dest: dest,
+		Reply: func(err error) error {
+			if err != nil {
+				return writeHTTPStatus(w, http.StatusBadGateway, nil)
+			}
+			_, err = io.WriteString(w, httpConnectionEstablished)
+			return err
+		},
+	}, nil
+}
+
+func parseProxyAuth(auth string) (user, password string, ok bool) {
+	const prefix = "Basic "
+	if !strings.HasPrefix(auth, prefix) {
+		return
+	}
+	b, err := base64.StdEncoding.DecodeString(auth[len(prefix):])
+	if err != nil {
+		return
+	}
+	creds := string(b)
+	i := strings.IndexByte(creds, ':')
+	if i < 0 {
+		return
+	}
+	return creds[:i], creds[i+1:], true
+}
+
+func writeHTTPStatus(w io.Writer, code int, header http.Header) error {
+	resp := &http.Response{
+		StatusCode: code,
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     header,
+	}
+	return resp.Write(w)
+}
+
+type bufferedConn struct {
+	net.Conn
+	rd *bufio.Reader
+}
+
+func (bc *bufferedConn) Read(p []byte) (int, error) {
+	return bc.rd.Read(p)
+}
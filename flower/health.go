@@ -0,0 +1,114 @@
+package flower
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"time"
+)
+
+type reportFunc func(healthy bool, reason string)
+
+// healthChecker checks the local service periodically, by TCP connect or
+// HTTP GET if path is not empty, the result is reported only if it changed.
+type healthChecker struct {
+	addr     string
+	tlsConf  *tls.Config
+	scheme   string
+	httpHost string
+	path     string
+	interval time.Duration
+	timeout  time.Duration
+	client   *http.Client
+	report   reportFunc
+	closed   chan struct{}
+}
+
+func newHealthChecker(addr, path string, tlsConf *tls.Config, interval, timeout time.Duration, report reportFunc) *healthChecker {
+	network, address := splitLocalAddr(addr)
+	scheme := "http"
+	if tlsConf != nil {
+		scheme = "https"
+	}
+	httpHost := address
+	if network == "unix" {
+		httpHost = "localhost"
+	}
+	dialer := &net.Dialer{Timeout: timeout}
+	return &healthChecker{
+		addr:     addr,
+		tlsConf:  tlsConf,
+		scheme:   scheme,
+		httpHost: httpHost,
+		path:     path,
+		interval: interval,
+		timeout:  timeout,
+		client: &http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				DisableKeepAlives: true,
+				TLSClientConfig:   tlsConf,
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					return dialer.DialContext(ctx, network, address)
+				},
+			},
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse // Redirect means it is alive
+			},
+		},
+		report: report,
+		closed: make(chan struct{}),
+	}
+}
+
+func (hc *healthChecker) Run() {
+	ticker := time.NewTicker(hc.interval)
+	defer ticker.Stop()
+
+	reported, lastReason := false, ""
+	for {
+		reason := ""
+		if err := hc.check(); err != nil {
+			reason = err.Error()
+		}
+		if !reported || reason != lastReason {
+			hc.report(reason == "", reason)
+			reported, lastReason = true, reason
+		}
+
+		select {
+		case <-hc.closed:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (hc *healthChecker) check() error {
+	if hc.path == "" {
+		conn, err := dialLocal(hc.addr, hc.tlsConf, hc.timeout)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+
+	resp, err := hc.client.Get(fmt.Sprintf("%s://%s%s", hc.scheme, hc.httpHost, hc.path))
+	if err != nil {
+		return err
+	}
+	io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("GET %s: %s", hc.path, resp.Status)
+	}
+	return nil
+}
+
+func (hc *healthChecker) Close() {
+	close(hc.closed)
+}
@@ -0,0 +1,52 @@
+package flower
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/damnever/sunflower/msg/msgpb"
+)
+
+// newLocalTLSConfig builds the TLS config to connect to local service,
+// nil will be returned if TLS is not required.
+func newLocalTLSConfig(req *msgpb.NewTunnelRequest) (*tls.Config, error) {
+	if !req.LocalTLS {
+		return nil, nil
+	}
+	serverName := req.TLSServerName
+	if serverName == "" {
+		network, address := splitLocalAddr(req.ExportAddr)
+		if network == "unix" {
+			serverName = "localhost"
+		} else if host, _, err := net.SplitHostPort(address); err == nil {
+			serverName = host
+		}
+	}
+	conf := &tls.Config{
+		ServerName:         serverName,
+		InsecureSkipVerify: req.TLSSkipVerify,
+	}
+	if req.TLSCA != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(req.TLSCA)) {
+			return nil, errors.New("no valid certificate found in CA bundle")
+		}
+		conf.RootCAs = pool
+	}
+	return conf, nil
+}
+
+// tlsHandshake wraps conn in TLS, the handshake must finish within timeout.
+func tlsHandshake(conn net.Conn, conf *tls.Config, timeout time.Duration) (net.Conn, error) {
+	tlsConn := tls.Client(conn, conf)
+	tlsConn.SetDeadline(time.Now().Add(timeout))
+	if err := tlsConn.Handshake(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	tlsConn.SetDeadline(time.Time{})
+	return tlsConn, nil
+}
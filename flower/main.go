@@ -2,55 +2,171 @@ package flower
 
 import (
 	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
 
 	"github.com/damnever/cc"
 	"github.com/damnever/sunflower/log"
 	"github.com/damnever/sunflower/pkg/debug"
+	"github.com/damnever/sunflower/pkg/retry"
+	"github.com/damnever/sunflower/pkg/selfupdate"
+	"github.com/damnever/sunflower/pkg/util"
+	"github.com/damnever/sunflower/version"
 )
 
 var (
 	c = flag.String("c", "", "Path to client configuration file, useful for self build client.")
 )
 
+func init() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [status|enroll]\n\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "  status\n\tShow the status of running agent.\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  enroll -server <address> -token <token>\n\tEnroll with a token, the credentials are stored in %s or -c.\n\n", defaultConfigName)
+		flag.PrintDefaults()
+	}
+}
+
 func Run() {
 	flag.Parse()
 	logger := log.New("M")
 
-	var (
-		cconf cc.Configer
-		err   error
-	)
-	if *c != "" {
-		cconf, err = cc.NewConfigFromFile(*c)
-	} else {
-		var data []byte
-		if data, err = loadConfigFromExec(); err == nil {
-			cconf, err = cc.NewConfigFromYAML(data)
+	if flag.Arg(0) == "enroll" {
+		confPath := *c
+		if confPath == "" {
+			confPath = defaultConfigPath()
+		}
+		if err := enroll(confPath, flag.Args()[1:]); err != nil {
+			logger.Fatalf("Enroll failed: %v", err)
 		}
+		return
 	}
+
+	cconf, embeddedConf, err := loadConfig()
 	if err != nil {
 		logger.Fatalf("Load config failed: %v", err)
 	}
 	debugAddr := cconf.String("debug_addr")
-	conf := buildConfig(cconf)
+	conf, err := buildConfig(cconf)
+	if err != nil {
+		logger.Fatalf("Invalid config: %v", err)
+	}
 	cconf = nil
 
+	if cmd := flag.Arg(0); cmd != "" {
+		if cmd != "status" {
+			flag.Usage()
+			os.Exit(2)
+		}
+		if err := showStatus(conf.StatusAddr); err != nil {
+			logger.Fatalf("Show status failed: %v", err)
+		}
+		return
+	}
+
+	var debugServer, statusServer *http.Server
 	if debugAddr != "" {
-		debugServer := debug.NewServer(debugAddr)
+		debugServer = debug.NewServer(debugAddr)
 		go func() {
-			if err := debugServer.ListenAndServe(); err != nil {
+			if err := debugServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 				logger.Errorf("Start debug server failed: %v", err)
 			}
 		}()
 		defer debugServer.Close()
 	}
 
-	logger.Infof("The flower is blooming..")
-	ctl, err := NewControler(conf)
+	tryUpdate := func() {
+		logger.Infof("Incompatible with server, updating..")
+		// The new version listens on the same addresses.
+		for _, server := range []*http.Server{debugServer, statusServer} {
+			if server != nil {
+				server.Close()
+			}
+		}
+		if err := selfUpdate(conf, embeddedConf); err != nil {
+			logger.Fatalf("Self-update failed: %v", err)
+		}
+		if err := commitUpdate(); err != nil {
+			logger.Warnf("Remove previous version failed: %v", err)
+		}
+		logger.Infof("The new version takes over")
+		os.Exit(0)
+	}
+
+	logger.Infof("The flower(%s) is blooming..", version.Full())
+	var ctl *Controler
+	retrier := conf.Retrier.WithHook(func(attempt int, err error, wait time.Duration) {
+		if wait != 0 {
+			logger.Warnf("Connect to control server failed(attempt %d): %v, retry in %v", attempt, err, wait)
+		}
+	})
+	err = retrier.Run(func() (err error) {
+		if ctl, err = NewControler(conf); isRejected(err) {
+			return retry.Stop(err)
+		}
+		return err
+	})
+	// The new version exits on failure, the previous one rolls back.
+	updated := selfupdate.IsStarted()
+	if err == errBadVersion && !updated {
+		tryUpdate()
+	}
 	if err != nil {
 		logger.Fatalf("Init failed: %v", err)
 	}
+	if updated {
+		if err := selfupdate.Ready(); err != nil {
+			logger.Fatalf("Report self-update failed: %v", err)
+		}
+		logger.Infof("Self-updated")
+	}
+	if conf.StatusAddr != "" {
+		statusServer = newStatusServer(conf.StatusAddr, ctl)
+		go func() {
+			if err := statusServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				logger.Errorf("Start status server failed: %v", err)
+			}
+		}()
+		defer statusServer.Close()
+	}
 	if err = ctl.Run(); err != nil {
+		if err == errBadVersion {
+			tryUpdate()
+		}
 		logger.Fatalf("Stopped with: %v", err)
 	}
 }
+
+func showStatus(addr string) error {
+	if addr == "" {
+		return fmt.Errorf("status_addr is not configured")
+	}
+	status, err := fetchStatus(addr)
+	if err != nil {
+		return fmt.Errorf("is the agent running? %v", err)
+	}
+	printStatus(os.Stdout, status)
+	return nil
+}
+
+// loadConfig loads config from the file specified by -c, or the one
+// attached to executable, or the one stored by enrollment, the data
+// is returned as well if it is attached to executable.
+func loadConfig() (cc.Configer, []byte, error) {
+	if *c != "" {
+		cconf, err := cc.NewConfigFromFile(*c)
+		return cconf, nil, err
+	}
+	data, err := loadConfigFromExec()
+	if err == nil {
+		cconf, err := cc.NewConfigFromYAML(data)
+		return cconf, data, err
+	}
+	if confPath := defaultConfigPath(); util.FileExist(confPath) {
+		cconf, err := cc.NewConfigFromFile(confPath)
+		return cconf, nil, err
+	}
+	return nil, nil, err
+}
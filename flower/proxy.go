@@ -1,6 +1,9 @@
 package flower
 
 import (
+	"context"
+	"crypto/tls"
+	"fmt"
 	"net"
 	"strings"
 	"sync"
@@ -20,36 +23,130 @@ import (
 
 const (
 	localConnectTimeout = 1 * time.Second
+	unixAddrPrefix      = "unix://"
+	// The first registration is made while sun is waiting for the response,
+	// so it must give up soon, the later ones retry forever by default.
+	initialRegisterAttempts = 3
+	// The previous server closes the sessions once it has drained,
+	// the timeout only bounds the ones left behind.
+	drainTimeout       = time.Minute
+	drainCheckInterval = 100 * time.Millisecond
 )
 
-type registerFunc func() (*yamux.Session, error)
+type registerFunc func(retrier *retry.Retrier) (*yamux.Session, error)
 
 type TCPProxy struct {
 	sync.Mutex
-	ctl        *Controler
-	regSelf    registerFunc
-	logger     *zap.SugaredLogger
-	exportAddr string
-	session    *yamux.Session
-	closed     bool
+	ctl          *Controler
+	ctx          context.Context // Canceled after closed, stops re-registration
+	cancel       context.CancelFunc
+	regSelf      registerFunc
+	logger       *zap.SugaredLogger
+	tunnelHash   string
+	proto        string
+	exportAddr   string
+	localTLS     *tls.Config // Not nil if local service speaks TLS
+	registryAddr string
+	session      *yamux.Session
+	directs      map[*yamux.Session]struct{} // Peer-to-peer sessions with visitors
+	checker      *healthChecker
+	forward      *forwardProxy // Not nil if flower acts as a forward proxy
+	reverse      net.Listener  // Not nil if connections go to the server side
+	files        *fileServer   // Not nil if flower serves a local directory
+	dialErrs     *dialErrorReporter
+	health       string
+	closed       bool
 }
 
-func NewTCPProxy(req *msgpb.NewTunnelRequest, ctl *Controler) (*TCPProxy, error) {
+func NewTCPProxy(req *msgpb.NewTunnelRequest, ctl *Controler) (_ *TCPProxy, err error) {
 	logger := log.New("prx[%s://%s]", strings.ToLower(req.Proto), req.ExportAddr)
+	ctx, cancel := context.WithCancel(context.Background())
 	p := &TCPProxy{
-		ctl:        ctl,
-		logger:     logger,
-		exportAddr: req.ExportAddr,
-		closed:     false,
+		ctl:          ctl,
+		ctx:          ctx,
+		cancel:       cancel,
+		logger:       logger,
+		tunnelHash:   req.TunnelHash,
+		proto:        req.Proto,
+		exportAddr:   req.ExportAddr,
+		registryAddr: req.RegistryAddr,
+		directs:      map[*yamux.Session]struct{}{},
+		health:       "unknown",
+		closed:       false,
+	}
+	defer func() {
+		if err == nil {
+			return
+		}
+		cancel()
+		if p.reverse != nil {
+			p.reverse.Close()
+		}
+		if p.files != nil {
+			p.files.Close()
+		}
+	}()
+
+	if p.localTLS, err = newLocalTLSConfig(req); err != nil {
+		return nil, err
+	}
+	if req.Proto == "PROXY" {
+		if p.forward, err = newForwardProxy(req); err != nil {
+			return nil, err
+		}
+	}
+	if req.Proto == "REVERSE" {
+		if p.reverse, err = net.Listen(splitLocalAddr(req.ExportAddr)); err != nil {
+			return nil, err
+		}
+	}
+	if strings.HasPrefix(req.ExportAddr, fileAddrPrefix) {
+		if p.files, err = newFileServer(req); err != nil {
+			return nil, err
+		}
 	}
 
 	regSelf := p.tryRegisterProxyFunc(req, ctl.conf)
-	session, err := regSelf()
+	session, err := regSelf(ctl.conf.Retrier.WithMax(initialRegisterAttempts))
 	if err != nil {
 		return nil, err
 	}
 	p.session = session
 	p.regSelf = regSelf
+	p.dialErrs = newDialErrorReporter(req, ctl.client.Send)
+	if p.reverse != nil {
+		go p.serveReverse()
+		return p, nil
+	}
+	if p.files != nil {
+		go p.files.Serve()
+		return p, nil
+	}
+	if p.forward != nil { // Nothing to check
+		return p, nil
+	}
+
+	hcConf := ctl.conf.HealthCheck
+	p.checker = newHealthChecker(req.ExportAddr, req.HealthCheckPath, p.localTLS, hcConf.Interval, hcConf.Timeout,
+		func(healthy bool, reason string) {
+			p.Lock()
+			p.health = "healthy"
+			if !healthy {
+				p.health = "unhealthy: " + reason
+			}
+			p.Unlock()
+			if !healthy {
+				p.logger.Warnf("Local service is unhealthy: %s", reason)
+			}
+			ctl.client.Send(&msgpb.HealthReport{
+				ID:         req.ID,
+				ClientHash: req.ClientHash,
+				TunnelHash: req.TunnelHash,
+				Healthy:    healthy,
+				Reason:     reason,
+			})
+		})
+	go p.checker.Run()
 	return p, nil
 }
 
@@ -60,9 +157,81 @@ func (p *TCPProxy) Close() error {
 		return nil
 	}
 	p.closed = true
+	p.cancel()
+	if p.checker != nil {
+		p.checker.Close()
+	}
+	if p.reverse != nil {
+		p.reverse.Close()
+	}
+	if p.files != nil {
+		p.files.Close()
+	}
+	for session := range p.directs {
+		session.Close()
+	}
 	return p.session.Close()
 }
 
+// Drain stops serving new connections and re-registering, but the session
+// is kept until the in-flight streams are done or timeout, so that a new
+// proxy of the same tunnel can be opened meanwhile.
+func (p *TCPProxy) Drain(timeout time.Duration) {
+	p.Lock()
+	if p.closed {
+		p.Unlock()
+		return
+	}
+	p.cancel()
+	if p.checker != nil {
+		p.checker.Close()
+		p.checker = nil
+	}
+	if p.reverse != nil {
+		p.reverse.Close() // The new one listens on the same address
+	}
+	session := p.session
+	p.Unlock()
+
+	go func() {
+		deadline := time.Now().Add(timeout)
+		ticker := time.NewTicker(drainCheckInterval)
+		defer ticker.Stop()
+		for session.NumStreams() > 0 && !session.IsClosed() && time.Now().Before(deadline) {
+			<-ticker.C
+		}
+		p.Close()
+	}()
+}
+
+// Status returns the state of proxy.
+func (p *TCPProxy) Status() ProxyStatus {
+	p.Lock()
+	defer p.Unlock()
+	st := ProxyStatus{
+		TunnelHash:   p.tunnelHash,
+		Proto:        p.proto,
+		ExportAddr:   p.exportAddr,
+		RegistryAddr: p.registryAddr,
+		Session:      "established",
+		Streams:      p.session.NumStreams(),
+		Directs:      len(p.directs),
+		Health:       p.health,
+	}
+	if p.checker == nil {
+		st.Health = "-"
+	}
+	if p.closed {
+		st.Session = "closed"
+	} else if p.session.IsClosed() {
+		st.Session = "reconnecting"
+	}
+	for session := range p.directs {
+		st.Streams += session.NumStreams()
+	}
+	return st
+}
+
 func (p *TCPProxy) isclosed() bool {
 	p.Lock()
 	defer p.Unlock()
@@ -89,11 +258,13 @@ func (p *TCPProxy) Serve() {
 			break
 		}
 		p.logger.Errorf("Got error: %v, try reconnecting..", err)
+		p.ctl.errors.Add("tunnel "+p.tunnelHash, err)
 
-		sess, fatalErr := p.regSelf()
+		sess, fatalErr := p.regSelf(p.ctl.conf.Retrier)
 		if fatalErr != nil {
-			if fatalErr != retry.ErrCanceled {
+			if fatalErr != context.Canceled {
 				p.logger.Errorf("Reconnect failed: %v", fatalErr)
+				p.ctl.errors.Add("tunnel "+p.tunnelHash, fatalErr)
 			}
 			break
 		}
@@ -119,11 +290,24 @@ func (p *TCPProxy) handleStream(stream *yamux.Stream) {
 		}
 	}()
 
+	if p.forward != nil {
+		p.handleForwardStream(streamID, stream)
+		return
+	}
+	if p.files != nil {
+		p.logger.Infof("[%v] Serving files: %v", streamID, stream.RemoteAddr())
+		p.files.ServeConn(stream)
+		p.logger.Infof("[%v] Stream closed", streamID)
+		return
+	}
+
 	timeout := p.ctl.conf.Timeout.Local.Connect
-	localConn, err := net.DialTimeout("tcp", p.exportAddr, timeout)
+	localConn, err := dialLocal(p.exportAddr, p.localTLS, timeout)
 	if err != nil {
 		stream.Close()
 		p.logger.Errorf("[%v] Failed to connect to %v: %v", streamID, p.exportAddr, err)
+		p.dialErrs.Add(err)
+		p.ctl.errors.Add("tunnel "+p.tunnelHash, err)
 		return
 	}
 
@@ -132,51 +316,144 @@ func (p *TCPProxy) handleStream(stream *yamux.Stream) {
 	p.logger.Infof("[%v] Linked stream closed", streamID)
 }
 
+// serveReverse opens a stream for each local connection, sun links
+// the stream to the target address.
+func (p *TCPProxy) serveReverse() {
+	for {
+		conn, err := p.reverse.Accept()
+		if err != nil {
+			if nerr, ok := err.(net.Error); ok && nerr.Temporary() {
+				continue
+			}
+			if p.ctx.Err() == nil { // Not closed or draining
+				p.logger.Errorf("Accept failed: %v", err)
+			}
+			return
+		}
+		p.ctl.Add(1)
+		go p.handleReverseConn(conn)
+	}
+}
+
+func (p *TCPProxy) handleReverseConn(conn net.Conn) {
+	defer p.ctl.Done()
+	defer func() {
+		if e := recover(); e != nil {
+			p.logger.Panicf("Panic: %v", e)
+		}
+	}()
+
+	p.Lock()
+	session := p.session
+	p.Unlock()
+	stream, err := session.OpenStream()
+	if err != nil {
+		conn.Close()
+		p.logger.Errorf("Open stream failed: %v", err)
+		return
+	}
+
+	streamID := stream.StreamID()
+	p.logger.Infof("[%v] Linking stream: %v<->%v", streamID, conn.RemoteAddr(), stream.RemoteAddr())
+	connutil.LinkStream(conn, stream)
+	p.logger.Infof("[%v] Linked stream closed", streamID)
+}
+
+func (p *TCPProxy) handleForwardStream(streamID uint32, stream *yamux.Stream) {
+	req, err := p.forward.Accept(stream)
+	if err != nil {
+		stream.Close()
+		p.logger.Warnf("[%v] Bad proxy request: %v", streamID, err)
+		return
+	}
+
+	timeout := p.ctl.conf.Timeout.Local.Connect
+	remoteConn, err := net.DialTimeout("tcp", req.Dest, timeout)
+	if rerr := req.Reply(err); err != nil || rerr != nil {
+		stream.Close()
+		if err != nil {
+			p.logger.Errorf("[%v] Failed to connect to %v: %v", streamID, req.Dest, err)
+			p.dialErrs.Add(err)
+		} else {
+			remoteConn.Close()
+		}
+		return
+	}
+
+	p.logger.Infof("[%v] Linking stream: %v<->%v", streamID, remoteConn.RemoteAddr(), stream.RemoteAddr())
+	connutil.LinkStream(req.Conn, remoteConn)
+	p.logger.Infof("[%v] Linked stream closed", streamID)
+}
+
 func (p *TCPProxy) tryRegisterProxyFunc(req *msgpb.NewTunnelRequest, conf *Config) registerFunc {
 	cliID := req.ID
 	cliHash := req.ClientHash
 	tunnelHash := req.TunnelHash
 	registryAddr := req.RegistryAddr
-	retrier := conf.Retrier
 	timeout := conf.Timeout.Tunnel
 
-	return func() (session *yamux.Session, err error) {
-		retrier.Run(func() error {
-			if p.isclosed() {
-				err = retry.ErrCanceled // the error..
-				return err
-			}
+	register := func() (*yamux.Session, error) {
+		conn, err := p.ctl.transport.dialRegistry(registryAddr, timeout.Connect)
+		if err != nil {
+			return nil, fmt.Errorf("connect to registry: %v", err)
+		}
 
-			var conn net.Conn
-			conn, err = net.DialTimeout("tcp", registryAddr, timeout.Connect)
-			if err != nil {
-				p.logger.Errorf("Connect to registry failed: %v", err)
-				return err
-			}
+		err = doHandshake(conn, timeout.Read, timeout.Write, &msgpb.TunnelHandshakeRequest{
+			ID:         cliID,
+			ClientHash: cliHash,
+			TunnelHash: tunnelHash,
+		})
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("handshake: %v", err)
+		}
 
-			err = doHandshake(conn, timeout.Read, timeout.Write, &msgpb.TunnelHandshakeRequest{
-				ID:         cliID,
-				ClientHash: cliHash,
-				TunnelHash: tunnelHash,
-			})
-			if err != nil {
-				conn.Close()
-				p.logger.Errorf("Handshake failed: %v", err)
-				return err
-			}
+		conn.SetDeadline(time.Time{}) // Clear deadline
+		session, err := yamux.Client(conn, yamux.DefaultConfig())
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("create session: %v", err)
+		}
+		return session, nil
+	}
 
-			conn.SetDeadline(time.Time{}) // Clear deadline
-			if session, err = yamux.Client(conn, yamux.DefaultConfig()); err != nil {
-				conn.Close()
-				p.logger.Errorf("Create session failed: %v", err)
-				return err
+	return func(retrier *retry.Retrier) (session *yamux.Session, err error) {
+		retrier = retrier.WithHook(func(attempt int, err error, wait time.Duration) {
+			if wait == 0 { // Reported by the caller
+				return
 			}
-			return nil
+			p.logger.Errorf("Register failed(attempt %d): %v, retry in %v", attempt, err, wait)
+			p.ctl.errors.Add("tunnel "+tunnelHash, err)
+		})
+		err = retrier.RunContext(p.ctx, func(context.Context) error {
+			var rerr error
+			session, rerr = register()
+			return rerr
 		})
 		return
 	}
 }
 
+// splitLocalAddr splits the local address into network and address,
+// it is either host:port or unix:///path/to/socket.
+func splitLocalAddr(addr string) (network, address string) {
+	if strings.HasPrefix(addr, unixAddrPrefix) {
+		return "unix", addr[len(unixAddrPrefix):]
+	}
+	return "tcp", addr
+}
+
+// dialLocal connects to local service, over TLS if tlsConf is not nil,
+// timeout applies to both connect and handshake.
+func dialLocal(addr string, tlsConf *tls.Config, timeout time.Duration) (net.Conn, error) {
+	network, address := splitLocalAddr(addr)
+	conn, err := net.DialTimeout(network, address, timeout)
+	if err != nil || tlsConf == nil {
+		return conn, err
+	}
+	return tlsHandshake(conn, tlsConf, timeout)
+}
+
 func doHandshake(conn net.Conn, rTimeout, wTimeout time.Duration, req *msgpb.TunnelHandshakeRequest) error {
 	conn.SetWriteDeadline(time.Now().Add(wTimeout))
 	if err := msg.Write(conn, req); err != nil {
@@ -0,0 +1,185 @@
+package flower
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/hashicorp/yamux"
+
+	"github.com/damnever/sunflower/msg"
+	"github.com/damnever/sunflower/msg/msgpb"
+	"github.com/damnever/sunflower/pkg/punch"
+)
+
+const (
+	punchRendezvousTimeout = 10 * time.Second
+	punchTimeout           = 5 * time.Second
+	punchRetryInterval     = 1 * time.Minute
+)
+
+var (
+	errPunchNotClaimed  = fmt.Errorf("another connection has been chosen")
+	errProxyClosed      = fmt.Errorf("proxy closed")
+	errPunchUnsupported = fmt.Errorf("peer-to-peer mode is not supported over WebSocket")
+)
+
+// Punch tries to connect with the visitor directly, streams from the direct
+// session are handled as the same as the ones relayed by server.
+func (p *TCPProxy) Punch(req *msgpb.PunchRequest) {
+	report := &msgpb.PunchReport{
+		ID:         req.ID,
+		ClientHash: req.ClientHash,
+		TunnelHash: req.TunnelHash,
+	}
+	session, err := p.punch(req)
+	if err == nil {
+		err = p.addDirect(session)
+	}
+	if err != nil {
+		p.logger.Warnf("Punch to visitor %s failed: %v", req.PeerAddr, err)
+		report.Reason = err.Error()
+		p.ctl.client.Send(report)
+		return
+	}
+	report.Direct = true
+	p.ctl.client.Send(report)
+
+	p.logger.Infof("Direct session with visitor %s established", session.RemoteAddr())
+	defer p.removeDirect(session)
+	for {
+		stream, err := session.AcceptStream()
+		if err != nil {
+			break
+		}
+		p.ctl.Add(1)
+		go p.handleStream(stream)
+	}
+	p.logger.Infof("Direct session with visitor %s closed", session.RemoteAddr())
+}
+
+func (p *TCPProxy) punch(req *msgpb.PunchRequest) (*yamux.Session, error) {
+	if p.ctl.transport.overWebSocket() {
+		return nil, errPunchUnsupported
+	}
+	timeout := p.ctl.conf.Timeout.Tunnel
+	conn, err := punch.Dial(p.registryAddr, timeout.Connect)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	err = doHandshake(conn, timeout.Read, timeout.Write, &msgpb.TunnelHandshakeRequest{
+		ID:         req.ID,
+		ClientHash: req.ClientHash,
+		TunnelHash: req.TunnelHash,
+		Punch:      true,
+		Nonce:      req.Nonce,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dconn, err := punch.Punch(conn.LocalAddr().String(), req.PeerAddr, punchTimeout,
+		func(conn net.Conn, claim func() bool) error {
+			var hello msgpb.TunnelHandshakeRequest
+			if err := msg.ReadTo(conn, &hello); err != nil {
+				return err
+			}
+			resp := msgpb.TunnelHandshakeResponse{}
+			if hello.TunnelHash != req.TunnelHash || hello.Nonce != req.Nonce {
+				resp.ErrCode = msgpb.ErrCodeBadClient
+			} else if !claim() {
+				return errPunchNotClaimed
+			}
+			if err := msg.Write(conn, resp); err != nil {
+				return err
+			}
+			return msg.CodeToError(resp.ErrCode)
+		})
+	if err != nil {
+		return nil, err
+	}
+	session, err := yamux.Server(dconn, yamux.DefaultConfig())
+	if err != nil {
+		dconn.Close()
+		return nil, err
+	}
+	return session, nil
+}
+
+func (p *TCPProxy) addDirect(session *yamux.Session) error {
+	p.Lock()
+	defer p.Unlock()
+	if p.closed {
+		session.Close()
+		return errProxyClosed
+	}
+	p.directs[session] = struct{}{}
+	return nil
+}
+
+func (p *TCPProxy) removeDirect(session *yamux.Session) {
+	p.Lock()
+	delete(p.directs, session)
+	p.Unlock()
+	session.Close()
+}
+
+// connectDirect asks the registry to rendezvous with the serving agent,
+// then punches to it, the nonce is used to prove who we are.
+func (v *Visitor) connectDirect(regAddr string) (*yamux.Session, error) {
+	timeout := v.ctl.conf.Timeout.Tunnel
+	conn, err := punch.Dial(regAddr, timeout.Connect)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	conn.SetWriteDeadline(time.Now().Add(timeout.Write))
+	err = msg.Write(conn, &msgpb.TunnelHandshakeRequest{
+		ID:         v.ctl.conf.ID,
+		ClientHash: v.ctl.conf.Hash,
+		TunnelHash: v.conf.Tunnel,
+		Visitor:    true,
+		SecretKey:  v.conf.SecretKey,
+		Punch:      true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var resp msgpb.TunnelHandshakeResponse
+	conn.SetReadDeadline(time.Now().Add(punchRendezvousTimeout))
+	if err := msg.ReadTo(conn, &resp); err != nil {
+		return nil, err
+	}
+	if err := msg.CodeToError(resp.ErrCode); err != nil {
+		return nil, err
+	}
+
+	dconn, err := punch.Punch(conn.LocalAddr().String(), resp.PeerAddr, punchTimeout,
+		func(conn net.Conn, claim func() bool) error {
+			err := msg.Write(conn, &msgpb.TunnelHandshakeRequest{
+				ID:         v.ctl.conf.ID,
+				ClientHash: v.ctl.conf.Hash,
+				TunnelHash: v.conf.Tunnel,
+				Nonce:      resp.Nonce,
+			})
+			if err != nil {
+				return err
+			}
+			var hresp msgpb.TunnelHandshakeResponse
+			if err := msg.ReadTo(conn, &hresp); err != nil {
+				return err
+			}
+			return msg.CodeToError(hresp.ErrCode)
+		})
+	if err != nil {
+		return nil, err
+	}
+	session, err := yamux.Client(dconn, yamux.DefaultConfig())
+	if err != nil {
+		dconn.Close()
+		return nil, err
+	}
+	return session, nil
+}
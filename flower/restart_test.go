@@ -0,0 +1,206 @@
+// +build !windows
+
+package flower
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/damnever/sunflower/pkg/retry"
+	"github.com/damnever/sunflower/pkg/util"
+	"github.com/damnever/sunflower/sun/storage"
+	"github.com/damnever/sunflower/sun/web"
+)
+
+const restartTestConfig = `datadir: %s
+domain: sunflower.test
+proxy_ip: 127.0.0.1
+graceful_shutdown: 30
+control:
+    addr: 127.0.0.1:%d
+muxreg:
+    http_addr: 127.0.0.1:%d
+web:
+    addr: 127.0.0.1:%d
+`
+
+// buildSun builds sun into dir, the test is skipped if go is not found.
+func buildSun(t *testing.T, dir string) string {
+	gobin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go is not found")
+	}
+	exePath := filepath.Join(dir, "sun")
+	out, err := exec.Command(gobin, "build", "-o", exePath, "github.com/damnever/sunflower/cmd/sun").CombinedOutput()
+	require.Nil(t, err, string(out))
+	return exePath
+}
+
+func freePort(t *testing.T) int {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+func serveEcho(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					conn.Write([]byte(line))
+				}
+			}()
+		}
+	}()
+	return l.Addr().String()
+}
+
+func echo(conn net.Conn, line string) error {
+	conn.SetDeadline(time.Now().Add(3 * time.Second))
+	if _, err := conn.Write([]byte(line + "\n")); err != nil {
+		return err
+	}
+	resp, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if resp != line+"\n" {
+		return fmt.Errorf("unexpected response: %q", resp)
+	}
+	return nil
+}
+
+// dialEcho connects to the tunnel until it echoes, or timeout.
+func dialEcho(addr string, timeout time.Duration) (net.Conn, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		conn, err := net.DialTimeout("tcp", addr, time.Second)
+		if err == nil {
+			if err = echo(conn, "hello"); err == nil {
+				return conn, nil
+			}
+			conn.Close()
+		}
+		if time.Now().After(deadline) {
+			return nil, err
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func TestStreamSurvivesServerRestart(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds and runs sun")
+	}
+	dir, err := ioutil.TempDir("", "flower")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+	exePath := buildSun(t, dir)
+	confPath := filepath.Join(dir, "sun.server.yaml")
+	datadir := filepath.Join(dir, "data")
+	controlPort := freePort(t)
+	controlAddr := fmt.Sprintf("127.0.0.1:%d", controlPort)
+	conf := fmt.Sprintf(restartTestConfig, datadir, controlPort, freePort(t), freePort(t))
+	require.Nil(t, ioutil.WriteFile(confPath, []byte(conf), 0644))
+
+	// An agent with a TCP tunnel to the echo server.
+	db, err := storage.New(datadir)
+	require.Nil(t, err)
+	password, err := util.EncryptPasswd([]byte("password"))
+	require.Nil(t, err)
+	require.Nil(t, db.CreateUser("admin", password, "admin@sunflower.test", true))
+	require.Nil(t, db.CreateAgent("admin", "agent", "test"))
+	tunnel, err := web.CreateTunnel(&web.Config{BindIPs: []string{"127.0.0.1"}}, db, "admin", "agent", map[string]string{
+		"proto":       "tcp",
+		"export_addr": serveEcho(t),
+		"server_addr": strconv.Itoa(freePort(t)),
+	})
+	require.Nil(t, err)
+	require.Nil(t, db.Close())
+
+	cmd := exec.Command(exePath, "-c", confPath)
+	cmd.Dir = dir
+	// The restarted one is in the same group, they are killed together.
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	require.Nil(t, cmd.Start())
+	defer syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+
+	fconf := &Config{
+		ID:                "admin",
+		Hash:              "agent",
+		ControlServers:    []string{controlAddr},
+		HeartbeatInterval: time.Second,
+		Retrier:           retry.NewExponential(100*time.Millisecond, time.Second, retry.Forever),
+	}
+	fconf.Timeout.GracefulShutdown = time.Second
+	fconf.Timeout.Control = util.TimeoutConfig{Connect: time.Second, Read: 5 * time.Second, Write: time.Second}
+	fconf.Timeout.Tunnel = util.TimeoutConfig{Connect: time.Second, Read: 5 * time.Second, Write: time.Second}
+	fconf.Timeout.Local = util.TimeoutConfig{Connect: time.Second, Read: time.Second, Write: time.Second}
+	fconf.HealthCheck.Interval = time.Minute
+	fconf.HealthCheck.Timeout = time.Second
+	var ctl *Controler
+	require.Nil(t, fconf.Retrier.WithMax(50).Run(func() (err error) {
+		ctl, err = NewControler(fconf)
+		return err
+	}))
+	go ctl.client.Run(ctl)
+	defer ctl.client.Close()
+
+	conn, err := dialEcho(tunnel.ServerAddr, 10*time.Second)
+	require.Nil(t, err)
+	defer conn.Close()
+
+	require.Nil(t, syscall.Kill(cmd.Process.Pid, syscall.SIGUSR2))
+	// The agent reconnects to the new process meanwhile.
+	time.Sleep(time.Second)
+
+	// The stream is drained by the previous process.
+	for i := 0; i < 5; i++ {
+		require.Nil(t, echo(conn, fmt.Sprintf("ping %d", i)))
+		time.Sleep(100 * time.Millisecond)
+	}
+	select {
+	case err := <-exited:
+		t.Fatalf("the previous process exited while draining: %v", err)
+	default:
+	}
+
+	conn.Close()
+	select {
+	case err := <-exited:
+		assert.Nil(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("the previous process does not exit after drained")
+	}
+	conn, err = dialEcho(tunnel.ServerAddr, 10*time.Second)
+	require.Nil(t, err)
+	conn.Close()
+}
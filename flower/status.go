@@ -0,0 +1,236 @@
+package flower
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/damnever/sunflower/birpc"
+	"github.com/damnever/sunflower/msg/msgpb"
+	"github.com/damnever/sunflower/pkg/dialer"
+	"github.com/damnever/sunflower/pkg/util"
+	"github.com/damnever/sunflower/version"
+)
+
+const (
+	defaultStatusAddr = "127.0.0.1:22223"
+	maxRecentErrors   = 20
+	statusPath        = "/status"
+)
+
+// Status is the snapshot of agent served by the local status server.
+type Status struct {
+	Version      string                        `json:"version"`
+	StartedAt    time.Time                     `json:"started_at"`
+	Control      birpc.ClientStatus            `json:"control"`
+	Proxies      []ProxyStatus                 `json:"proxies"`
+	Declared     []*msgpb.DeclaredTunnelResult `json:"declared"`
+	RecentErrors []ErrorRecord                 `json:"recent_errors"`
+	Config       ConfigStatus                  `json:"config"`
+}
+
+// ProxyStatus describes a tunnel served by agent.
+type ProxyStatus struct {
+	TunnelHash   string `json:"tunnel_hash"`
+	Proto        string `json:"proto"`
+	ExportAddr   string `json:"export_addr"`
+	RegistryAddr string `json:"registry_addr"`
+	Session      string `json:"session"` // established, reconnecting or closed
+	Streams      int    `json:"streams"` // Including the ones of direct sessions
+	Directs      int    `json:"directs"` // Peer-to-peer sessions with visitors
+	Health       string `json:"health"`
+}
+
+// ConfigStatus is the effective configuration, secrets are masked.
+type ConfigStatus struct {
+	ID                string            `json:"id"`
+	ControlServers    []string          `json:"control_servers"`
+	WebSocketServer   string            `json:"websocket_server"`
+	StatusAddr        string            `json:"status_addr"`
+	UpdateServer      string            `json:"update_server"`
+	Proxy             string            `json:"proxy"`
+	HeartbeatInterval string            `json:"heartbeat_interval"`
+	Timeout           map[string]string `json:"timeout"`
+	HealthCheck       map[string]string `json:"health_check"`
+	Visitors          []VisitorConfig   `json:"visitors"`
+	Tunnels           []TunnelConfig    `json:"tunnels"`
+}
+
+type ErrorRecord struct {
+	Time    time.Time `json:"time"`
+	Source  string    `json:"source"`
+	Message string    `json:"message"`
+}
+
+// recentErrors keeps the latest maxRecentErrors errors.
+type recentErrors struct {
+	sync.Mutex
+	records []ErrorRecord
+}
+
+func (r *recentErrors) Add(source string, err error) {
+	r.Lock()
+	defer r.Unlock()
+	if len(r.records) == maxRecentErrors {
+		r.records = append(r.records[:0], r.records[1:]...)
+	}
+	r.records = append(r.records, ErrorRecord{
+		Time:    time.Now(),
+		Source:  source,
+		Message: err.Error(),
+	})
+}
+
+func (r *recentErrors) List() []ErrorRecord {
+	r.Lock()
+	defer r.Unlock()
+	return append([]ErrorRecord{}, r.records...)
+}
+
+func (conf *Config) status() ConfigStatus {
+	visitors := make([]VisitorConfig, 0, len(conf.Visitors))
+	for _, v := range conf.Visitors {
+		v.SecretKey = "******"
+		visitors = append(visitors, v)
+	}
+	return ConfigStatus{
+		ID:                conf.ID,
+		ControlServers:    conf.ControlServers,
+		WebSocketServer:   conf.WebSocketServer,
+		StatusAddr:        conf.StatusAddr,
+		UpdateServer:      conf.UpdateServer,
+		Proxy:             dialer.Redact(conf.Proxy),
+		HeartbeatInterval: conf.HeartbeatInterval.String(),
+		Timeout: map[string]string{
+			"graceful_shutdown": conf.Timeout.GracefulShutdown.String(),
+			"control":           fmtTimeout(conf.Timeout.Control),
+			"tunnel":            fmtTimeout(conf.Timeout.Tunnel),
+			"local":             fmtTimeout(conf.Timeout.Local),
+		},
+		HealthCheck: map[string]string{
+			"interval": conf.HealthCheck.Interval.String(),
+			"timeout":  conf.HealthCheck.Timeout.String(),
+		},
+		Visitors: visitors,
+		Tunnels:  conf.Tunnels,
+	}
+}
+
+func fmtTimeout(t util.TimeoutConfig) string {
+	return fmt.Sprintf("connect=%v read=%v write=%v", t.Connect, t.Read, t.Write)
+}
+
+// Status returns the snapshot of agent.
+func (c *Controler) Status() Status {
+	c.RLock()
+	proxies := make([]ProxyStatus, 0, len(c.proxies))
+	for _, proxy := range c.proxies {
+		proxies = append(proxies, proxy.Status())
+	}
+	declared := make([]*msgpb.DeclaredTunnelResult, 0, len(c.declared))
+	for _, result := range c.declared {
+		declared = append(declared, result)
+	}
+	c.RUnlock()
+	sort.Slice(proxies, func(i, j int) bool { return proxies[i].TunnelHash < proxies[j].TunnelHash })
+	sort.Slice(declared, func(i, j int) bool { return declared[i].Tag < declared[j].Tag })
+
+	return Status{
+		Version:      version.Full(),
+		StartedAt:    c.startedAt,
+		Control:      c.client.Status(),
+		Proxies:      proxies,
+		Declared:     declared,
+		RecentErrors: c.errors.List(),
+		Config:       c.conf.status(),
+	}
+}
+
+// newStatusServer serves the status of agent as JSON, it is meant
+// to be listened on loopback address.
+func newStatusServer(addr string, ctl *Controler) *http.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc(statusPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.Encode(ctl.Status())
+	})
+	return &http.Server{Addr: addr, Handler: mux}
+}
+
+// fetchStatus queries the status server of running agent.
+func fetchStatus(addr string) (*Status, error) {
+	cli := &http.Client{Timeout: 3 * time.Second}
+	resp, err := cli.Get(fmt.Sprintf("http://%s%s", addr, statusPath))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+	status := &Status{}
+	if err := json.NewDecoder(resp.Body).Decode(status); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+func printStatus(w io.Writer, status *Status) {
+	ctl := status.Control
+	state := "disconnected"
+	if ctl.Connected {
+		state = fmt.Sprintf("connected since %s", ctl.ConnectedAt.Format(time.RFC3339))
+	}
+	fmt.Fprintf(w, "Version:    %s (up %s)\n", status.Version, time.Since(status.StartedAt).Truncate(time.Second))
+	fmt.Fprintf(w, "Agent:      %s\n", status.Config.ID)
+	fmt.Fprintf(w, "Control:    %s, %s\n", ctl.RemoteAddr, state)
+	if proxy := status.Config.Proxy; proxy != "" && proxy != dialer.Direct {
+		fmt.Fprintf(w, "Proxy:      %s\n", proxy)
+	}
+	fmt.Fprintf(w, "RTT:        %v\n", ctl.RTT)
+	fmt.Fprintf(w, "Reconnects: %d\n", ctl.Reconnects)
+
+	fmt.Fprintf(w, "\nProxies:\n")
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "  TUNNEL\tPROTO\tEXPORT\tSESSION\tSTREAMS\tDIRECTS\tHEALTH")
+	for _, p := range status.Proxies {
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\t%d\t%d\t%s\n",
+			p.TunnelHash, p.Proto, p.ExportAddr, p.Session, p.Streams, p.Directs, p.Health)
+	}
+	tw.Flush()
+
+	if len(status.Declared) > 0 {
+		fmt.Fprintf(w, "\nDeclared tunnels:\n")
+		tw = tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "  TAG\tTUNNEL\tSERVER\tRESULT")
+		for _, d := range status.Declared {
+			result := "accepted"
+			if !d.Accepted {
+				result = "rejected: " + d.Reason
+			}
+			fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\n", d.Tag, d.TunnelHash, d.ServerAddr, result)
+		}
+		tw.Flush()
+	}
+
+	fmt.Fprintf(w, "\nRecent errors:\n")
+	for _, e := range status.RecentErrors {
+		fmt.Fprintf(w, "  %s [%s] %s\n", e.Time.Format(time.RFC3339), e.Source, strings.TrimSpace(e.Message))
+	}
+
+	fmt.Fprintf(w, "\nConfig:\n")
+	data, _ := json.MarshalIndent(status.Config, "  ", "  ")
+	fmt.Fprintf(w, "  %s\n", data)
+}
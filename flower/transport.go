@@ -0,0 +1,100 @@
+package flower
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/damnever/sunflower/pkg/dialer"
+	"github.com/damnever/sunflower/pkg/wsconn"
+)
+
+const (
+	wsControlPath = "/ws/control"
+	wsTunnelPath  = "/ws/tunnel"
+	srvPrefix     = "srv://"
+)
+
+// transport connects to the control server and the tunnel registry of sun,
+// either over TCP directly or over WebSocket on the web port of sun.
+type transport struct {
+	dial      dialer.DialFunc // Connects to server, maybe through proxy
+	controls  []string        // The control servers in order of preference
+	websocket string          // The base URL if over WebSocket
+}
+
+func newTransport(conf *Config) (*transport, error) {
+	dial, err := dialer.New(conf.Proxy)
+	if err != nil {
+		return nil, fmt.Errorf("proxy: %v", err)
+	}
+	t := &transport{dial: dial, controls: conf.ControlServers}
+	if conf.WebSocketServer != "" {
+		u, err := url.Parse(conf.WebSocketServer)
+		if err != nil {
+			return nil, fmt.Errorf("websocket server: %v", err)
+		}
+		if u.Scheme != "ws" && u.Scheme != "wss" {
+			return nil, fmt.Errorf("websocket server: unsupported scheme: %s", u.Scheme)
+		}
+		t.websocket = strings.TrimSuffix(conf.WebSocketServer, "/")
+	}
+	return t, nil
+}
+
+// overWebSocket returns true if the connections are carried by WebSocket,
+// peer-to-peer mode is not available since the registry is unreachable.
+func (t *transport) overWebSocket() bool {
+	return t.websocket != ""
+}
+
+// resolveControl returns the addresses of control servers in order of preference,
+// it is used as birpc.ClientConfig.Resolve, the srv:// ones are looked up
+// from DNS SRV records every time, so the changes of records take effect on reconnecting.
+func (t *transport) resolveControl() ([]string, error) {
+	if t.overWebSocket() {
+		return []string{t.websocket + wsControlPath}, nil
+	}
+	addrs := make([]string, 0, len(t.controls))
+	var lastErr error
+	for _, server := range t.controls {
+		if !strings.HasPrefix(server, srvPrefix) {
+			addrs = append(addrs, server)
+			continue
+		}
+		// Sorted by priority and randomized by weight.
+		_, records, err := net.LookupSRV("", "", server[len(srvPrefix):])
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		for _, r := range records {
+			host := strings.TrimSuffix(r.Target, ".")
+			addrs = append(addrs, net.JoinHostPort(host, strconv.Itoa(int(r.Port))))
+		}
+	}
+	if len(addrs) == 0 && lastErr != nil {
+		return nil, lastErr
+	}
+	return addrs, nil
+}
+
+// dialControl connects to the control server, it is used as birpc.ClientConfig.Dial,
+// the address is the WebSocket endpoint if over WebSocket.
+func (t *transport) dialControl(network, address string, timeout time.Duration) (net.Conn, error) {
+	if t.overWebSocket() {
+		return wsconn.Dial(address, t.dial, timeout)
+	}
+	return t.dial(network, address, timeout)
+}
+
+// dialRegistry connects to the tunnel registry told by the control server.
+func (t *transport) dialRegistry(registryAddr string, timeout time.Duration) (net.Conn, error) {
+	if t.overWebSocket() {
+		return wsconn.Dial(t.websocket+wsTunnelPath, t.dial, timeout)
+	}
+	return t.dial("tcp", registryAddr, timeout)
+}
@@ -0,0 +1,173 @@
+package flower
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/kardianos/osext"
+
+	"github.com/damnever/sunflower/msg"
+	"github.com/damnever/sunflower/msg/msgpb"
+	"github.com/damnever/sunflower/pkg/dialer"
+	"github.com/damnever/sunflower/pkg/selfupdate"
+	"github.com/damnever/sunflower/pkg/util"
+)
+
+// updateTimeout is how long the new executable has to handshake with
+// server, the previous one is restored if it fails or exits within it.
+const updateTimeout = time.Minute
+
+// The server rejects the agent itself, it makes no sense to reconnect.
+var (
+	errBadClient  = msg.CodeToError(msgpb.ErrCodeBadClient)
+	errBadVersion = msg.CodeToError(msgpb.ErrCodeBadVersion)
+)
+
+// isRejected reports whether err is the handshake rejected by server,
+// not a network error.
+func isRejected(err error) bool {
+	return err == errBadClient || err == errBadVersion
+}
+
+// failedUpdatePath returns the marker created by rollback, no more
+// self-update is tried until it is removed, or it loops forever.
+func failedUpdatePath(exePath string) string {
+	return exePath + ".update-failed"
+}
+
+// selfUpdate replaces the executable with the one downloaded from
+// update server, then starts it, the embedded config is kept. It returns
+// nil once the new one handshakes with server, or it rolls back.
+func selfUpdate(conf *Config, embeddedConf []byte) error {
+	if conf.UpdateServer == "" || conf.UpdateKey == nil {
+		return fmt.Errorf("self-update is not configured")
+	}
+	exePath, err := osext.Executable()
+	if err != nil {
+		return err
+	}
+	if marker := failedUpdatePath(exePath); util.FileExist(marker) {
+		return fmt.Errorf("the previous self-update failed, remove %s to try again", marker)
+	}
+	data, err := downloadUpdate(conf)
+	if err != nil {
+		return err
+	}
+	if embeddedConf != nil {
+		if data, err = attachConfig(data, embeddedConf); err != nil {
+			return err
+		}
+	}
+	if err := selfupdate.Replace(exePath, data); err != nil {
+		return err
+	}
+	if err := selfupdate.Start(exePath, os.Args[1:], os.Environ(), updateTimeout); err != nil {
+		if rerr := rollbackUpdate(exePath, err); rerr != nil {
+			return fmt.Errorf("%v, and rollback failed: %v", err, rerr)
+		}
+		return fmt.Errorf("%v, rolled back", err)
+	}
+	return nil
+}
+
+// rollbackUpdate restores the previous executable, the marker is left
+// so that the previous one does not update again.
+func rollbackUpdate(exePath string, cause error) error {
+	marker := fmt.Sprintf("%s: %v\n", time.Now().Format(time.RFC3339), cause)
+	if err := ioutil.WriteFile(failedUpdatePath(exePath), []byte(marker), 0644); err != nil {
+		return err
+	}
+	return selfupdate.Rollback(exePath)
+}
+
+// commitUpdate removes the previous executable.
+func commitUpdate() error {
+	exePath, err := osext.Executable()
+	if err != nil {
+		return err
+	}
+	return selfupdate.Commit(exePath)
+}
+
+func downloadUpdate(conf *Config) ([]byte, error) {
+	params := url.Values{
+		"GOOS":   {runtime.GOOS},
+		"GOARCH": {runtime.GOARCH},
+		"GOARM":  {goarm()},
+	}
+	endpoint := fmt.Sprintf("%s/api/update?%s", strings.TrimSuffix(conf.UpdateServer, "/"), params.Encode())
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(selfupdate.HeaderAgentID, conf.ID)
+	req.Header.Set(selfupdate.HeaderAgentHash, conf.Hash)
+
+	cli := &http.Client{Timeout: 5 * time.Minute}
+	if conf.Proxy != "" && conf.Proxy != dialer.Direct {
+		proxyURL, err := url.Parse(conf.Proxy)
+		if err != nil {
+			return nil, err
+		}
+		cli.Transport = &http.Transport{Proxy: http.ProxyURL(proxyURL)}
+	}
+	resp, err := cli.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download failed: %s", resp.Status)
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err := selfupdate.Verify(conf.UpdateKey, data, resp.Header.Get(selfupdate.HeaderSignature)); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// attachConfig attaches the config at the end of executable,
+// the same as control panel does, see loadConfigFromExec.
+func attachConfig(bin, conf []byte) ([]byte, error) {
+	buf := bytes.NewBuffer(bin)
+	zipw := zip.NewWriter(buf)
+	w, err := zipw.Create(defaultConfigName)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(conf); err != nil {
+		return nil, err
+	}
+	if err := zipw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// goarm returns the ARM version the executable built with, it is
+// required to pick the right executable on server side.
+func goarm() string {
+	if runtime.GOARCH != "arm" {
+		return ""
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			if setting.Key == "GOARM" && setting.Value != "" {
+				return setting.Value[:1]
+			}
+		}
+	}
+	return "7"
+}
@@ -0,0 +1,179 @@
+package flower
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/yamux"
+	"go.uber.org/zap"
+
+	"github.com/damnever/sunflower/log"
+	"github.com/damnever/sunflower/msg/msgpb"
+	connutil "github.com/damnever/sunflower/pkg/conn"
+)
+
+var (
+	errVisitorClosed     = fmt.Errorf("visitor closed")
+	errNoRegistryAddress = fmt.Errorf("registry address is unknown")
+)
+
+// Visitor listens on a local address, connections are bridged to the
+// secret tunnel through the registry of server, the session to registry
+// is established lazily and re-established if it is broken.
+type Visitor struct {
+	sync.Mutex
+	ctl     *Controler
+	conf    VisitorConfig
+	logger  *zap.SugaredLogger
+	l       net.Listener
+	session *yamux.Session
+	failedT time.Time // The last time punching failed
+	closed  bool
+}
+
+func NewVisitor(conf VisitorConfig, ctl *Controler) (*Visitor, error) {
+	if conf.Tunnel == "" || conf.BindAddr == "" {
+		return nil, fmt.Errorf("both tunnel and bind_addr are required for visitor")
+	}
+	l, err := net.Listen("tcp", conf.BindAddr)
+	if err != nil {
+		return nil, err
+	}
+	return &Visitor{
+		ctl:    ctl,
+		conf:   conf,
+		logger: log.New("vst[%s]", conf.Tunnel),
+		l:      l,
+	}, nil
+}
+
+func (v *Visitor) Serve() {
+	v.logger.Infof("Listening on %s", v.l.Addr())
+	for {
+		conn, err := v.l.Accept()
+		if err != nil {
+			if nerr, ok := err.(net.Error); ok && nerr.Temporary() {
+				continue
+			}
+			break
+		}
+		v.ctl.Add(1)
+		go v.handleConn(conn)
+	}
+	v.logger.Info("Stopped")
+}
+
+func (v *Visitor) handleConn(conn net.Conn) {
+	defer v.ctl.Done()
+	defer func() {
+		if e := recover(); e != nil {
+			v.logger.Panicf("Panic: %v", e)
+		}
+	}()
+
+	stream, err := v.openStream()
+	if err != nil {
+		conn.Close()
+		v.logger.Errorf("Open stream failed: %v", err)
+		return
+	}
+	streamID := stream.StreamID()
+	v.logger.Infof("[%v] Linking stream: %v<->%v", streamID, conn.RemoteAddr(), stream.RemoteAddr())
+	connutil.LinkStream(conn, stream)
+	v.logger.Infof("[%v] Linked stream closed", streamID)
+}
+
+func (v *Visitor) openStream() (*yamux.Stream, error) {
+	session, err := v.getSession()
+	if err != nil {
+		return nil, err
+	}
+	stream, err := session.OpenStream()
+	if err == nil {
+		return stream, nil
+	}
+	// The session may be broken, try it again with a new one.
+	session.Close()
+	if session, err = v.getSession(); err != nil {
+		return nil, err
+	}
+	return session.OpenStream()
+}
+
+func (v *Visitor) getSession() (*yamux.Session, error) {
+	v.Lock()
+	defer v.Unlock()
+	if v.closed {
+		return nil, errVisitorClosed
+	}
+	if v.session != nil && !v.session.IsClosed() {
+		return v.session, nil
+	}
+	session, err := v.connect()
+	if err != nil {
+		return nil, err
+	}
+	v.session = session
+	return session, nil
+}
+
+// connect tries peer-to-peer mode first if it is enabled,
+// it falls back to relay mode if punching failed.
+func (v *Visitor) connect() (*yamux.Session, error) {
+	regAddr := v.ctl.client.RegistryAddr()
+	if regAddr == "" {
+		return nil, errNoRegistryAddress
+	}
+	if v.conf.Direct && !v.ctl.transport.overWebSocket() && time.Since(v.failedT) > punchRetryInterval {
+		session, err := v.connectDirect(regAddr)
+		if err == nil {
+			v.logger.Infof("Connected with peer %s directly", session.RemoteAddr())
+			return session, nil
+		}
+		v.failedT = time.Now()
+		v.logger.Warnf("Punch failed, fall back to relay: %v", err)
+	}
+	return v.connectRelay(regAddr)
+}
+
+func (v *Visitor) connectRelay(regAddr string) (*yamux.Session, error) {
+	timeout := v.ctl.conf.Timeout.Tunnel
+	conn, err := v.ctl.transport.dialRegistry(regAddr, timeout.Connect)
+	if err != nil {
+		return nil, err
+	}
+	err = doHandshake(conn, timeout.Read, timeout.Write, &msgpb.TunnelHandshakeRequest{
+		ID:         v.ctl.conf.ID,
+		ClientHash: v.ctl.conf.Hash,
+		TunnelHash: v.conf.Tunnel,
+		Visitor:    true,
+		SecretKey:  v.conf.SecretKey,
+	})
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	conn.SetDeadline(time.Time{}) // Clear deadline
+	session, err := yamux.Client(conn, yamux.DefaultConfig())
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return session, nil
+}
+
+func (v *Visitor) Close() error {
+	v.Lock()
+	defer v.Unlock()
+	if v.closed {
+		return nil
+	}
+	v.closed = true
+	if v.session != nil {
+		v.session.Close()
+	}
+	return v.l.Close()
+}
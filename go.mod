@@ -1,14 +1,11 @@
 module github.com/damnever/sunflower
 
+go 1.27.1
+
 require (
 	github.com/daaku/go.zipexe v0.0.0-20150329023125-a5fe2436ffcb
 	github.com/damnever/cc v0.1.0
-	github.com/davecgh/go-spew v1.1.0 // indirect
-	github.com/dgrijalva/jwt-go v3.1.0+incompatible // indirect
-	github.com/dsnet/compress v0.0.0-20171208185109-cc9eb1d7ad76 // indirect
 	github.com/gogo/protobuf v1.2.0
-	github.com/gorilla/context v0.0.0-20160226214623-1ea25387ff6f // indirect
-	github.com/gorilla/securecookie v0.0.0-20160422134519-667fe4e3466a // indirect
 	github.com/gorilla/sessions v0.0.0-20160922145804-ca9ada445741
 	github.com/hashicorp/yamux v0.0.0-20171219165022-683f49123a33
 	github.com/howeyc/gopass v0.0.0-20170109162249-bf9dde6d0d2c
@@ -16,23 +13,31 @@ require (
 	github.com/kardianos/osext v0.0.0-20170510131534-ae77be60afb1
 	github.com/labstack/echo v0.0.0-20171123015859-0473c51f1dbd
 	github.com/labstack/echo-contrib v0.0.0-20170921210119-4036746d4b33
-	github.com/labstack/gommon v0.0.0-20170925052817-57409ada9da0 // indirect
-	github.com/mattn/go-colorable v0.0.9 // indirect
 	github.com/mattn/go-isatty v0.0.3
 	github.com/mattn/go-sqlite3 v1.4.0
 	github.com/mholt/archiver v2.0.0+incompatible
-	github.com/nwaples/rardecode v0.0.0-20171029023500-e06696f847ae // indirect
 	github.com/pkg/errors v0.8.0
-	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/stretchr/testify v1.1.4
+	go.uber.org/zap v1.7.1
+	golang.org/x/crypto v0.0.0-20171219041129-d585fd2cc919
+	golang.org/x/net v0.0.0-20171212005608-d866cfc389ce
+	golang.org/x/sys v0.0.0-20171216171702-571f7bbbe08d
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/dgrijalva/jwt-go v3.1.0+incompatible // indirect
+	github.com/dsnet/compress v0.0.0-20171208185109-cc9eb1d7ad76 // indirect
+	github.com/gorilla/context v0.0.0-20160226214623-1ea25387ff6f // indirect
+	github.com/gorilla/securecookie v0.0.0-20160422134519-667fe4e3466a // indirect
+	github.com/labstack/gommon v0.0.0-20170925052817-57409ada9da0 // indirect
+	github.com/mattn/go-colorable v0.0.9 // indirect
+	github.com/nwaples/rardecode v0.0.0-20171029023500-e06696f847ae // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/ulikunitz/xz v0.5.4 // indirect
 	github.com/valyala/bytebufferpool v1.0.0 // indirect
 	github.com/valyala/fasttemplate v0.0.0-20170224212429-dcecefd839c4 // indirect
 	go.uber.org/atomic v1.3.1 // indirect
 	go.uber.org/multierr v1.1.0 // indirect
-	go.uber.org/zap v1.7.1
-	golang.org/x/crypto v0.0.0-20171219041129-d585fd2cc919
-	golang.org/x/net v0.0.0-20171212005608-d866cfc389ce // indirect
-	golang.org/x/sys v0.0.0-20171216171702-571f7bbbe08d // indirect
 	gopkg.in/yaml.v2 v2.0.0-20171116090243-287cf08546ab // indirect
 )
@@ -28,6 +28,8 @@ var (
 		msgpb.ErrCodeNoSuchTunnel:        fmt.Errorf("no such tunnel"),
 		msgpb.ErrCodeDuplicateAgent:      fmt.Errorf("duplicate agent"),
 		msgpb.ErrCodeInternalServerError: fmt.Errorf("internal server error"),
+		msgpb.ErrCodeBadSecretKey:        fmt.Errorf("bad secret key"),
+		msgpb.ErrCodePunchFailed:         fmt.Errorf("punch failed"),
 	}
 )
 
@@ -35,6 +37,28 @@ func CodeToError(errCode msgpb.ErrCode) error {
 	return errCodeMap[errCode]
 }
 
+// HeadSize is the size of the length prefix plus the protobuf tag
+// of message body, which identifies the type of message.
+const HeadSize = 3
+
+// The tags of oneof fields in msgpb.Message, field number << 3 | wire type 2.
+const (
+	handshakeRequestTag       = 1<<3 | 2
+	tunnelHandshakeRequestTag = 3<<3 | 2
+)
+
+// IsHandshakeRequest reports whether the first HeadSize bytes of
+// a connection are the head of a HandshakeRequest.
+func IsHandshakeRequest(head []byte) bool {
+	return len(head) >= HeadSize && head[2] == handshakeRequestTag
+}
+
+// IsTunnelHandshakeRequest reports whether the first HeadSize bytes of
+// a connection are the head of a TunnelHandshakeRequest.
+func IsTunnelHandshakeRequest(head []byte) bool {
+	return len(head) >= HeadSize && head[2] == tunnelHandshakeRequestTag
+}
+
 func Write(w net.Conn, v interface{}) error {
 	m, err := toMessage(v)
 	if err != nil {
@@ -116,6 +140,31 @@ func toMessage(v interface{}) (*msgpb.Message, error) {
 		m.Body = &msgpb.Message_ShutdownRequest{ShutdownRequest: x}
 	case msgpb.ShutdownRequest:
 		m.Body = &msgpb.Message_ShutdownRequest{ShutdownRequest: &x}
+	case *msgpb.PunchRequest:
+		m.Body = &msgpb.Message_PunchRequest{PunchRequest: x}
+	case msgpb.PunchRequest:
+		m.Body = &msgpb.Message_PunchRequest{PunchRequest: &x}
+
+	case *msgpb.HealthReport:
+		m.Body = &msgpb.Message_HealthReport{HealthReport: x}
+	case msgpb.HealthReport:
+		m.Body = &msgpb.Message_HealthReport{HealthReport: &x}
+	case *msgpb.DialErrorReport:
+		m.Body = &msgpb.Message_DialErrorReport{DialErrorReport: x}
+	case msgpb.DialErrorReport:
+		m.Body = &msgpb.Message_DialErrorReport{DialErrorReport: &x}
+	case *msgpb.PunchReport:
+		m.Body = &msgpb.Message_PunchReport{PunchReport: x}
+	case msgpb.PunchReport:
+		m.Body = &msgpb.Message_PunchReport{PunchReport: &x}
+	case *msgpb.DeclareTunnelsRequest:
+		m.Body = &msgpb.Message_DeclareTunnelsRequest{DeclareTunnelsRequest: x}
+	case msgpb.DeclareTunnelsRequest:
+		m.Body = &msgpb.Message_DeclareTunnelsRequest{DeclareTunnelsRequest: &x}
+	case *msgpb.DeclareTunnelsResponse:
+		m.Body = &msgpb.Message_DeclareTunnelsResponse{DeclareTunnelsResponse: x}
+	case msgpb.DeclareTunnelsResponse:
+		m.Body = &msgpb.Message_DeclareTunnelsResponse{DeclareTunnelsResponse: &x}
 
 	default:
 		return nil, errors.WithStack(ErrUnknownMessageType)
@@ -196,5 +245,24 @@ func fromMessage(m *msgpb.Message) (interface{}, error) {
 	if v := m.GetShutdownRequest(); v != nil {
 		return v, nil
 	}
+	if v := m.GetPunchRequest(); v != nil {
+		return v, nil
+	}
+
+	if v := m.GetHealthReport(); v != nil {
+		return v, nil
+	}
+	if v := m.GetDialErrorReport(); v != nil {
+		return v, nil
+	}
+	if v := m.GetPunchReport(); v != nil {
+		return v, nil
+	}
+	if v := m.GetDeclareTunnelsRequest(); v != nil {
+		return v, nil
+	}
+	if v := m.GetDeclareTunnelsResponse(); v != nil {
+		return v, nil
+	}
 	return nil, errors.WithStack(ErrUnknownMessageType)
 }
@@ -19,6 +19,14 @@
 		CloseTunnelRequest
 		CloseTunnelResponse
 		ShutdownRequest
+		PunchRequest
+		DeclaredTunnelResult
+		DeclareTunnelsResponse
+		HealthReport
+		DialErrorReport
+		PunchReport
+		DeclaredTunnel
+		DeclareTunnelsRequest
 		Message
 */
 package msgpb
@@ -57,6 +65,8 @@ const (
 	ErrCodeNoSuchTunnel        ErrCode = 5
 	ErrCodeDuplicateAgent      ErrCode = 6
 	ErrCodeInternalServerError ErrCode = 7
+	ErrCodeBadSecretKey        ErrCode = 8
+	ErrCodePunchFailed         ErrCode = 9
 )
 
 var ErrCode_name = map[int32]string{
@@ -68,6 +78,8 @@ var ErrCode_name = map[int32]string{
 	5: "ErrCodeNoSuchTunnel",
 	6: "ErrCodeDuplicateAgent",
 	7: "ErrCodeInternalServerError",
+	8: "ErrCodeBadSecretKey",
+	9: "ErrCodePunchFailed",
 }
 var ErrCode_value = map[string]int32{
 	"ErrCodeNull":                0,
@@ -78,10 +90,36 @@ var ErrCode_value = map[string]int32{
 	"ErrCodeNoSuchTunnel":        5,
 	"ErrCodeDuplicateAgent":      6,
 	"ErrCodeInternalServerError": 7,
+	"ErrCodeBadSecretKey":        8,
+	"ErrCodePunchFailed":         9,
 }
 
 func (ErrCode) EnumDescriptor() ([]byte, []int) { return fileDescriptorMsg, []int{0} }
 
+type DialErrorClass int32
+
+const (
+	DialErrorUnknown DialErrorClass = 0
+	DialErrorRefused DialErrorClass = 1
+	DialErrorTimeout DialErrorClass = 2
+	DialErrorDNS     DialErrorClass = 3
+)
+
+var DialErrorClass_name = map[int32]string{
+	0: "DialErrorUnknown",
+	1: "DialErrorRefused",
+	2: "DialErrorTimeout",
+	3: "DialErrorDNS",
+}
+var DialErrorClass_value = map[string]int32{
+	"DialErrorUnknown": 0,
+	"DialErrorRefused": 1,
+	"DialErrorTimeout": 2,
+	"DialErrorDNS":     3,
+}
+
+func (DialErrorClass) EnumDescriptor() ([]byte, []int) { return fileDescriptorMsg, []int{1} }
+
 // client <-> server
 // - control
 type HandshakeRequest struct {
@@ -96,7 +134,8 @@ func (*HandshakeRequest) ProtoMessage()               {}
 func (*HandshakeRequest) Descriptor() ([]byte, []int) { return fileDescriptorMsg, []int{0} }
 
 type HandshakeResponse struct {
-	ErrCode ErrCode `protobuf:"varint,1,opt,name=err_code,json=errCode,proto3,enum=msgpb.ErrCode" json:"err_code,omitempty"`
+	ErrCode      ErrCode `protobuf:"varint,1,opt,name=err_code,json=errCode,proto3,enum=msgpb.ErrCode" json:"err_code,omitempty"`
+	RegistryAddr string  `protobuf:"bytes,2,opt,name=registry_addr,json=registryAddr,proto3" json:"registry_addr,omitempty"`
 }
 
 func (m *HandshakeResponse) Reset()                    { *m = HandshakeResponse{} }
@@ -122,6 +161,13 @@ type TunnelHandshakeRequest struct {
 	ID         string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	ClientHash string `protobuf:"bytes,2,opt,name=client_hash,json=clientHash,proto3" json:"client_hash,omitempty"`
 	TunnelHash string `protobuf:"bytes,3,opt,name=tunnel_hash,json=tunnelHash,proto3" json:"tunnel_hash,omitempty"`
+	// The visitor of secret tunnel, id and client_hash belong to itself.
+	Visitor   bool   `protobuf:"varint,4,opt,name=visitor,proto3" json:"visitor,omitempty"`
+	SecretKey string `protobuf:"bytes,5,opt,name=secret_key,json=secretKey,proto3" json:"secret_key,omitempty"`
+	// Rendezvous for hole punching, the serving agent must provide
+	// the nonce which it got from PunchRequest.
+	Punch bool   `protobuf:"varint,6,opt,name=punch,proto3" json:"punch,omitempty"`
+	Nonce string `protobuf:"bytes,7,opt,name=nonce,proto3" json:"nonce,omitempty"`
 }
 
 func (m *TunnelHandshakeRequest) Reset()                    { *m = TunnelHandshakeRequest{} }
@@ -130,6 +176,10 @@ func (*TunnelHandshakeRequest) Descriptor() ([]byte, []int) { return fileDescrip
 
 type TunnelHandshakeResponse struct {
 	ErrCode ErrCode `protobuf:"varint,1,opt,name=err_code,json=errCode,proto3,enum=msgpb.ErrCode" json:"err_code,omitempty"`
+	// Rendezvous for hole punching, the address of the other peer
+	// observed by server, and the nonce the visitor must provide to peer.
+	PeerAddr string `protobuf:"bytes,2,opt,name=peer_addr,json=peerAddr,proto3" json:"peer_addr,omitempty"`
+	Nonce    string `protobuf:"bytes,3,opt,name=nonce,proto3" json:"nonce,omitempty"`
 }
 
 func (m *TunnelHandshakeResponse) Reset()                    { *m = TunnelHandshakeResponse{} }
@@ -138,12 +188,34 @@ func (*TunnelHandshakeResponse) Descriptor() ([]byte, []int) { return fileDescri
 
 // server <-> client
 type NewTunnelRequest struct {
-	ID           string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	ClientHash   string `protobuf:"bytes,2,opt,name=client_hash,json=clientHash,proto3" json:"client_hash,omitempty"`
-	TunnelHash   string `protobuf:"bytes,3,opt,name=tunnel_hash,json=tunnelHash,proto3" json:"tunnel_hash,omitempty"`
-	Proto        string `protobuf:"bytes,4,opt,name=proto,proto3" json:"proto,omitempty"`
-	ExportAddr   string `protobuf:"bytes,5,opt,name=export_addr,json=exportAddr,proto3" json:"export_addr,omitempty"`
-	RegistryAddr string `protobuf:"bytes,6,opt,name=registry_addr,json=registryAddr,proto3" json:"registry_addr,omitempty"`
+	ID              string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	ClientHash      string `protobuf:"bytes,2,opt,name=client_hash,json=clientHash,proto3" json:"client_hash,omitempty"`
+	TunnelHash      string `protobuf:"bytes,3,opt,name=tunnel_hash,json=tunnelHash,proto3" json:"tunnel_hash,omitempty"`
+	Proto           string `protobuf:"bytes,4,opt,name=proto,proto3" json:"proto,omitempty"`
+	ExportAddr      string `protobuf:"bytes,5,opt,name=export_addr,json=exportAddr,proto3" json:"export_addr,omitempty"`
+	RegistryAddr    string `protobuf:"bytes,6,opt,name=registry_addr,json=registryAddr,proto3" json:"registry_addr,omitempty"`
+	HealthCheckPath string `protobuf:"bytes,7,opt,name=health_check_path,json=healthCheckPath,proto3" json:"health_check_path,omitempty"`
+	// HTTP GET if not empty, otherwise TCP connect
+	// PROXY only, the destinations are limited by allowlist,
+	// clients must authenticate with the user and password.
+	AllowCIDRs string `protobuf:"bytes,8,opt,name=allow_cidrs,json=allowCidrs,proto3" json:"allow_cidrs,omitempty"`
+	// Comma separated
+	AllowPorts string `protobuf:"bytes,9,opt,name=allow_ports,json=allowPorts,proto3" json:"allow_ports,omitempty"`
+	// Comma separated, all ports are allowed if empty
+	ProxyUser     string `protobuf:"bytes,10,opt,name=proxy_user,json=proxyUser,proto3" json:"proxy_user,omitempty"`
+	ProxyPassword string `protobuf:"bytes,11,opt,name=proxy_password,json=proxyPassword,proto3" json:"proxy_password,omitempty"`
+	// Wraps the connections to local service in TLS.
+	LocalTLS      bool   `protobuf:"varint,12,opt,name=local_tls,json=localTls,proto3" json:"local_tls,omitempty"`
+	TLSServerName string `protobuf:"bytes,13,opt,name=tls_server_name,json=tlsServerName,proto3" json:"tls_server_name,omitempty"`
+	// Overrides the host of export_addr
+	TLSSkipVerify bool   `protobuf:"varint,14,opt,name=tls_skip_verify,json=tlsSkipVerify,proto3" json:"tls_skip_verify,omitempty"`
+	TLSCA         string `protobuf:"bytes,15,opt,name=tls_ca,json=tlsCa,proto3" json:"tls_ca,omitempty"`
+	// PEM encoded, the system ones are used if empty
+	// file:// only, flower serves the directory over HTTP.
+	FileListing   bool   `protobuf:"varint,16,opt,name=file_listing,json=fileListing,proto3" json:"file_listing,omitempty"`
+	BasicAuthUser string `protobuf:"bytes,17,opt,name=basic_auth_user,json=basicAuthUser,proto3" json:"basic_auth_user,omitempty"`
+	// Basic auth is disabled if empty
+	BasicAuthPassword string `protobuf:"bytes,18,opt,name=basic_auth_password,json=basicAuthPassword,proto3" json:"basic_auth_password,omitempty"`
 }
 
 func (m *NewTunnelRequest) Reset()                    { *m = NewTunnelRequest{} }
@@ -181,12 +253,114 @@ func (*CloseTunnelResponse) Descriptor() ([]byte, []int) { return fileDescriptor
 type ShutdownRequest struct {
 	ID         string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	ClientHash string `protobuf:"bytes,2,opt,name=client_hash,json=clientHash,proto3" json:"client_hash,omitempty"`
+	// Reconnects instead of exiting, to reconnect_addr if not empty,
+	// otherwise to another control server if any.
+	Reconnect     bool   `protobuf:"varint,3,opt,name=reconnect,proto3" json:"reconnect,omitempty"`
+	ReconnectAddr string `protobuf:"bytes,4,opt,name=reconnect_addr,json=reconnectAddr,proto3" json:"reconnect_addr,omitempty"`
+	// In milliseconds, waits before reconnecting.
+	ReconnectDelay uint32 `protobuf:"varint,5,opt,name=reconnect_delay,json=reconnectDelay,proto3" json:"reconnect_delay,omitempty"`
+	// The server is restarting, reconnects to the same one.
+	Restart bool `protobuf:"varint,6,opt,name=restart,proto3" json:"restart,omitempty"`
 }
 
 func (m *ShutdownRequest) Reset()                    { *m = ShutdownRequest{} }
 func (*ShutdownRequest) ProtoMessage()               {}
 func (*ShutdownRequest) Descriptor() ([]byte, []int) { return fileDescriptorMsg, []int{10} }
 
+type PunchRequest struct {
+	ID         string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	ClientHash string `protobuf:"bytes,2,opt,name=client_hash,json=clientHash,proto3" json:"client_hash,omitempty"`
+	TunnelHash string `protobuf:"bytes,3,opt,name=tunnel_hash,json=tunnelHash,proto3" json:"tunnel_hash,omitempty"`
+	PeerAddr   string `protobuf:"bytes,4,opt,name=peer_addr,json=peerAddr,proto3" json:"peer_addr,omitempty"`
+	// The visitor address observed by server
+	Nonce string `protobuf:"bytes,5,opt,name=nonce,proto3" json:"nonce,omitempty"`
+}
+
+func (m *PunchRequest) Reset()                    { *m = PunchRequest{} }
+func (*PunchRequest) ProtoMessage()               {}
+func (*PunchRequest) Descriptor() ([]byte, []int) { return fileDescriptorMsg, []int{11} }
+
+type DeclaredTunnelResult struct {
+	Tag        string `protobuf:"bytes,1,opt,name=tag,proto3" json:"tag,omitempty"`
+	Accepted   bool   `protobuf:"varint,2,opt,name=accepted,proto3" json:"accepted,omitempty"`
+	TunnelHash string `protobuf:"bytes,3,opt,name=tunnel_hash,json=tunnelHash,proto3" json:"tunnel_hash,omitempty"`
+	ServerAddr string `protobuf:"bytes,4,opt,name=server_addr,json=serverAddr,proto3" json:"server_addr,omitempty"`
+	Reason     string `protobuf:"bytes,5,opt,name=reason,proto3" json:"reason,omitempty"`
+}
+
+func (m *DeclaredTunnelResult) Reset()                    { *m = DeclaredTunnelResult{} }
+func (*DeclaredTunnelResult) ProtoMessage()               {}
+func (*DeclaredTunnelResult) Descriptor() ([]byte, []int) { return fileDescriptorMsg, []int{12} }
+
+type DeclareTunnelsResponse struct {
+	Results []*DeclaredTunnelResult `protobuf:"bytes,1,rep,name=results" json:"results,omitempty"`
+}
+
+func (m *DeclareTunnelsResponse) Reset()                    { *m = DeclareTunnelsResponse{} }
+func (*DeclareTunnelsResponse) ProtoMessage()               {}
+func (*DeclareTunnelsResponse) Descriptor() ([]byte, []int) { return fileDescriptorMsg, []int{13} }
+
+// client -> server
+type HealthReport struct {
+	ID         string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	ClientHash string `protobuf:"bytes,2,opt,name=client_hash,json=clientHash,proto3" json:"client_hash,omitempty"`
+	TunnelHash string `protobuf:"bytes,3,opt,name=tunnel_hash,json=tunnelHash,proto3" json:"tunnel_hash,omitempty"`
+	Healthy    bool   `protobuf:"varint,4,opt,name=healthy,proto3" json:"healthy,omitempty"`
+	Reason     string `protobuf:"bytes,5,opt,name=reason,proto3" json:"reason,omitempty"`
+}
+
+func (m *HealthReport) Reset()                    { *m = HealthReport{} }
+func (*HealthReport) ProtoMessage()               {}
+func (*HealthReport) Descriptor() ([]byte, []int) { return fileDescriptorMsg, []int{14} }
+
+type DialErrorReport struct {
+	ID         string         `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	ClientHash string         `protobuf:"bytes,2,opt,name=client_hash,json=clientHash,proto3" json:"client_hash,omitempty"`
+	TunnelHash string         `protobuf:"bytes,3,opt,name=tunnel_hash,json=tunnelHash,proto3" json:"tunnel_hash,omitempty"`
+	Class      DialErrorClass `protobuf:"varint,4,opt,name=class,proto3,enum=msgpb.DialErrorClass" json:"class,omitempty"`
+	Reason     string         `protobuf:"bytes,5,opt,name=reason,proto3" json:"reason,omitempty"`
+	Count      uint32         `protobuf:"varint,6,opt,name=count,proto3" json:"count,omitempty"`
+}
+
+func (m *DialErrorReport) Reset()                    { *m = DialErrorReport{} }
+func (*DialErrorReport) ProtoMessage()               {}
+func (*DialErrorReport) Descriptor() ([]byte, []int) { return fileDescriptorMsg, []int{15} }
+
+type PunchReport struct {
+	ID         string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	ClientHash string `protobuf:"bytes,2,opt,name=client_hash,json=clientHash,proto3" json:"client_hash,omitempty"`
+	TunnelHash string `protobuf:"bytes,3,opt,name=tunnel_hash,json=tunnelHash,proto3" json:"tunnel_hash,omitempty"`
+	Direct     bool   `protobuf:"varint,4,opt,name=direct,proto3" json:"direct,omitempty"`
+	// Falls back to relay if false
+	Reason string `protobuf:"bytes,5,opt,name=reason,proto3" json:"reason,omitempty"`
+}
+
+func (m *PunchReport) Reset()                    { *m = PunchReport{} }
+func (*PunchReport) ProtoMessage()               {}
+func (*PunchReport) Descriptor() ([]byte, []int) { return fileDescriptorMsg, []int{16} }
+
+// The tunnels declared in the config of agent, the same tag means the same tunnel.
+type DeclaredTunnel struct {
+	Tag        string `protobuf:"bytes,1,opt,name=tag,proto3" json:"tag,omitempty"`
+	Proto      string `protobuf:"bytes,2,opt,name=proto,proto3" json:"proto,omitempty"`
+	ExportAddr string `protobuf:"bytes,3,opt,name=export_addr,json=exportAddr,proto3" json:"export_addr,omitempty"`
+	ServerAddr string `protobuf:"bytes,4,opt,name=server_addr,json=serverAddr,proto3" json:"server_addr,omitempty"`
+}
+
+func (m *DeclaredTunnel) Reset()                    { *m = DeclaredTunnel{} }
+func (*DeclaredTunnel) ProtoMessage()               {}
+func (*DeclaredTunnel) Descriptor() ([]byte, []int) { return fileDescriptorMsg, []int{17} }
+
+type DeclareTunnelsRequest struct {
+	ID         string            `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	ClientHash string            `protobuf:"bytes,2,opt,name=client_hash,json=clientHash,proto3" json:"client_hash,omitempty"`
+	Tunnels    []*DeclaredTunnel `protobuf:"bytes,3,rep,name=tunnels" json:"tunnels,omitempty"`
+}
+
+func (m *DeclareTunnelsRequest) Reset()                    { *m = DeclareTunnelsRequest{} }
+func (*DeclareTunnelsRequest) ProtoMessage()               {}
+func (*DeclareTunnelsRequest) Descriptor() ([]byte, []int) { return fileDescriptorMsg, []int{18} }
+
 type Message struct {
 	// Types that are valid to be assigned to Body:
 	//	*Message_HandshakeRequest
@@ -200,12 +374,18 @@ type Message struct {
 	//	*Message_CloseTunnelRequest
 	//	*Message_CloseTunnelResponse
 	//	*Message_ShutdownRequest
+	//	*Message_HealthReport
+	//	*Message_DialErrorReport
+	//	*Message_PunchRequest
+	//	*Message_PunchReport
+	//	*Message_DeclareTunnelsRequest
+	//	*Message_DeclareTunnelsResponse
 	Body isMessage_Body `protobuf_oneof:"body"`
 }
 
 func (m *Message) Reset()                    { *m = Message{} }
 func (*Message) ProtoMessage()               {}
-func (*Message) Descriptor() ([]byte, []int) { return fileDescriptorMsg, []int{11} }
+func (*Message) Descriptor() ([]byte, []int) { return fileDescriptorMsg, []int{19} }
 
 type isMessage_Body interface {
 	isMessage_Body()
@@ -247,6 +427,24 @@ type Message_CloseTunnelResponse struct {
 type Message_ShutdownRequest struct {
 	ShutdownRequest *ShutdownRequest `protobuf:"bytes,11,opt,name=shutdown_request,json=shutdownRequest,oneof"`
 }
+type Message_HealthReport struct {
+	HealthReport *HealthReport `protobuf:"bytes,12,opt,name=health_report,json=healthReport,oneof"`
+}
+type Message_DialErrorReport struct {
+	DialErrorReport *DialErrorReport `protobuf:"bytes,13,opt,name=dial_error_report,json=dialErrorReport,oneof"`
+}
+type Message_PunchRequest struct {
+	PunchRequest *PunchRequest `protobuf:"bytes,14,opt,name=punch_request,json=punchRequest,oneof"`
+}
+type Message_PunchReport struct {
+	PunchReport *PunchReport `protobuf:"bytes,15,opt,name=punch_report,json=punchReport,oneof"`
+}
+type Message_DeclareTunnelsRequest struct {
+	DeclareTunnelsRequest *DeclareTunnelsRequest `protobuf:"bytes,16,opt,name=declare_tunnels_request,json=declareTunnelsRequest,oneof"`
+}
+type Message_DeclareTunnelsResponse struct {
+	DeclareTunnelsResponse *DeclareTunnelsResponse `protobuf:"bytes,17,opt,name=declare_tunnels_response,json=declareTunnelsResponse,oneof"`
+}
 
 func (*Message_HandshakeRequest) isMessage_Body()        {}
 func (*Message_HandshakeResponse) isMessage_Body()       {}
@@ -259,6 +457,12 @@ func (*Message_NewTunnelResponse) isMessage_Body()       {}
 func (*Message_CloseTunnelRequest) isMessage_Body()      {}
 func (*Message_CloseTunnelResponse) isMessage_Body()     {}
 func (*Message_ShutdownRequest) isMessage_Body()         {}
+func (*Message_HealthReport) isMessage_Body()            {}
+func (*Message_DialErrorReport) isMessage_Body()         {}
+func (*Message_PunchRequest) isMessage_Body()            {}
+func (*Message_PunchReport) isMessage_Body()             {}
+func (*Message_DeclareTunnelsRequest) isMessage_Body()   {}
+func (*Message_DeclareTunnelsResponse) isMessage_Body()  {}
 
 func (m *Message) GetBody() isMessage_Body {
 	if m != nil {
@@ -344,6 +548,48 @@ func (m *Message) GetShutdownRequest() *ShutdownRequest {
 	return nil
 }
 
+func (m *Message) GetHealthReport() *HealthReport {
+	if x, ok := m.GetBody().(*Message_HealthReport); ok {
+		return x.HealthReport
+	}
+	return nil
+}
+
+func (m *Message) GetDialErrorReport() *DialErrorReport {
+	if x, ok := m.GetBody().(*Message_DialErrorReport); ok {
+		return x.DialErrorReport
+	}
+	return nil
+}
+
+func (m *Message) GetPunchRequest() *PunchRequest {
+	if x, ok := m.GetBody().(*Message_PunchRequest); ok {
+		return x.PunchRequest
+	}
+	return nil
+}
+
+func (m *Message) GetPunchReport() *PunchReport {
+	if x, ok := m.GetBody().(*Message_PunchReport); ok {
+		return x.PunchReport
+	}
+	return nil
+}
+
+func (m *Message) GetDeclareTunnelsRequest() *DeclareTunnelsRequest {
+	if x, ok := m.GetBody().(*Message_DeclareTunnelsRequest); ok {
+		return x.DeclareTunnelsRequest
+	}
+	return nil
+}
+
+func (m *Message) GetDeclareTunnelsResponse() *DeclareTunnelsResponse {
+	if x, ok := m.GetBody().(*Message_DeclareTunnelsResponse); ok {
+		return x.DeclareTunnelsResponse
+	}
+	return nil
+}
+
 // XXX_OneofFuncs is for the internal use of the proto package.
 func (*Message) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
 	return _Message_OneofMarshaler, _Message_OneofUnmarshaler, _Message_OneofSizer, []interface{}{
@@ -358,6 +604,12 @@ func (*Message) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error
 		(*Message_CloseTunnelRequest)(nil),
 		(*Message_CloseTunnelResponse)(nil),
 		(*Message_ShutdownRequest)(nil),
+		(*Message_HealthReport)(nil),
+		(*Message_DialErrorReport)(nil),
+		(*Message_PunchRequest)(nil),
+		(*Message_PunchReport)(nil),
+		(*Message_DeclareTunnelsRequest)(nil),
+		(*Message_DeclareTunnelsResponse)(nil),
 	}
 }
 
@@ -420,6 +672,36 @@ func _Message_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
 		if err := b.EncodeMessage(x.ShutdownRequest); err != nil {
 			return err
 		}
+	case *Message_HealthReport:
+		_ = b.EncodeVarint(12<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.HealthReport); err != nil {
+			return err
+		}
+	case *Message_DialErrorReport:
+		_ = b.EncodeVarint(13<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.DialErrorReport); err != nil {
+			return err
+		}
+	case *Message_PunchRequest:
+		_ = b.EncodeVarint(14<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.PunchRequest); err != nil {
+			return err
+		}
+	case *Message_PunchReport:
+		_ = b.EncodeVarint(15<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.PunchReport); err != nil {
+			return err
+		}
+	case *Message_DeclareTunnelsRequest:
+		_ = b.EncodeVarint(16<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.DeclareTunnelsRequest); err != nil {
+			return err
+		}
+	case *Message_DeclareTunnelsResponse:
+		_ = b.EncodeVarint(17<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.DeclareTunnelsResponse); err != nil {
+			return err
+		}
 	case nil:
 	default:
 		return fmt.Errorf("Message.Body has unexpected type %T", x)
@@ -518,6 +800,54 @@ func _Message_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffer
 		err := b.DecodeMessage(msg)
 		m.Body = &Message_ShutdownRequest{msg}
 		return true, err
+	case 12: // body.health_report
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(HealthReport)
+		err := b.DecodeMessage(msg)
+		m.Body = &Message_HealthReport{msg}
+		return true, err
+	case 13: // body.dial_error_report
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(DialErrorReport)
+		err := b.DecodeMessage(msg)
+		m.Body = &Message_DialErrorReport{msg}
+		return true, err
+	case 14: // body.punch_request
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(PunchRequest)
+		err := b.DecodeMessage(msg)
+		m.Body = &Message_PunchRequest{msg}
+		return true, err
+	case 15: // body.punch_report
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(PunchReport)
+		err := b.DecodeMessage(msg)
+		m.Body = &Message_PunchReport{msg}
+		return true, err
+	case 16: // body.declare_tunnels_request
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(DeclareTunnelsRequest)
+		err := b.DecodeMessage(msg)
+		m.Body = &Message_DeclareTunnelsRequest{msg}
+		return true, err
+	case 17: // body.declare_tunnels_response
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(DeclareTunnelsResponse)
+		err := b.DecodeMessage(msg)
+		m.Body = &Message_DeclareTunnelsResponse{msg}
+		return true, err
 	default:
 		return false, nil
 	}
@@ -582,6 +912,36 @@ func _Message_OneofSizer(msg proto.Message) (n int) {
 		n += proto.SizeVarint(11<<3 | proto.WireBytes)
 		n += proto.SizeVarint(uint64(s))
 		n += s
+	case *Message_HealthReport:
+		s := proto.Size(x.HealthReport)
+		n += proto.SizeVarint(12<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Message_DialErrorReport:
+		s := proto.Size(x.DialErrorReport)
+		n += proto.SizeVarint(13<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Message_PunchRequest:
+		s := proto.Size(x.PunchRequest)
+		n += proto.SizeVarint(14<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Message_PunchReport:
+		s := proto.Size(x.PunchReport)
+		n += proto.SizeVarint(15<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Message_DeclareTunnelsRequest:
+		s := proto.Size(x.DeclareTunnelsRequest)
+		n += proto.SizeVarint(16<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Message_DeclareTunnelsResponse:
+		s := proto.Size(x.DeclareTunnelsResponse)
+		n += proto.SizeVarint(17<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
 	case nil:
 	default:
 		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
@@ -601,8 +961,17 @@ func init() {
 	proto.RegisterType((*CloseTunnelRequest)(nil), "msgpb.CloseTunnelRequest")
 	proto.RegisterType((*CloseTunnelResponse)(nil), "msgpb.CloseTunnelResponse")
 	proto.RegisterType((*ShutdownRequest)(nil), "msgpb.ShutdownRequest")
+	proto.RegisterType((*PunchRequest)(nil), "msgpb.PunchRequest")
+	proto.RegisterType((*DeclaredTunnelResult)(nil), "msgpb.DeclaredTunnelResult")
+	proto.RegisterType((*DeclareTunnelsResponse)(nil), "msgpb.DeclareTunnelsResponse")
+	proto.RegisterType((*HealthReport)(nil), "msgpb.HealthReport")
+	proto.RegisterType((*DialErrorReport)(nil), "msgpb.DialErrorReport")
+	proto.RegisterType((*PunchReport)(nil), "msgpb.PunchReport")
+	proto.RegisterType((*DeclaredTunnel)(nil), "msgpb.DeclaredTunnel")
+	proto.RegisterType((*DeclareTunnelsRequest)(nil), "msgpb.DeclareTunnelsRequest")
 	proto.RegisterType((*Message)(nil), "msgpb.Message")
 	proto.RegisterEnum("msgpb.ErrCode", ErrCode_name, ErrCode_value)
+	proto.RegisterEnum("msgpb.DialErrorClass", DialErrorClass_name, DialErrorClass_value)
 }
 func (x ErrCode) String() string {
 	s, ok := ErrCode_name[int32(x)]
@@ -611,6 +980,13 @@ func (x ErrCode) String() string {
 	}
 	return strconv.Itoa(int(x))
 }
+func (x DialErrorClass) String() string {
+	s, ok := DialErrorClass_name[int32(x)]
+	if ok {
+		return s
+	}
+	return strconv.Itoa(int(x))
+}
 func (this *HandshakeRequest) Equal(that interface{}) bool {
 	if that == nil {
 		if this == nil {
@@ -678,6 +1054,9 @@ func (this *HandshakeResponse) Equal(that interface{}) bool {
 	if this.ErrCode != that1.ErrCode {
 		return false
 	}
+	if this.RegistryAddr != that1.RegistryAddr {
+		return false
+	}
 	return true
 }
 func (this *PingRequest) Equal(that interface{}) bool {
@@ -768,6 +1147,18 @@ func (this *TunnelHandshakeRequest) Equal(that interface{}) bool {
 	if this.TunnelHash != that1.TunnelHash {
 		return false
 	}
+	if this.Visitor != that1.Visitor {
+		return false
+	}
+	if this.SecretKey != that1.SecretKey {
+		return false
+	}
+	if this.Punch != that1.Punch {
+		return false
+	}
+	if this.Nonce != that1.Nonce {
+		return false
+	}
 	return true
 }
 func (this *TunnelHandshakeResponse) Equal(that interface{}) bool {
@@ -798,6 +1189,12 @@ func (this *TunnelHandshakeResponse) Equal(that interface{}) bool {
 	if this.ErrCode != that1.ErrCode {
 		return false
 	}
+	if this.PeerAddr != that1.PeerAddr {
+		return false
+	}
+	if this.Nonce != that1.Nonce {
+		return false
+	}
 	return true
 }
 func (this *NewTunnelRequest) Equal(that interface{}) bool {
@@ -843,6 +1240,42 @@ func (this *NewTunnelRequest) Equal(that interface{}) bool {
 	if this.RegistryAddr != that1.RegistryAddr {
 		return false
 	}
+	if this.HealthCheckPath != that1.HealthCheckPath {
+		return false
+	}
+	if this.AllowCIDRs != that1.AllowCIDRs {
+		return false
+	}
+	if this.AllowPorts != that1.AllowPorts {
+		return false
+	}
+	if this.ProxyUser != that1.ProxyUser {
+		return false
+	}
+	if this.ProxyPassword != that1.ProxyPassword {
+		return false
+	}
+	if this.LocalTLS != that1.LocalTLS {
+		return false
+	}
+	if this.TLSServerName != that1.TLSServerName {
+		return false
+	}
+	if this.TLSSkipVerify != that1.TLSSkipVerify {
+		return false
+	}
+	if this.TLSCA != that1.TLSCA {
+		return false
+	}
+	if this.FileListing != that1.FileListing {
+		return false
+	}
+	if this.BasicAuthUser != that1.BasicAuthUser {
+		return false
+	}
+	if this.BasicAuthPassword != that1.BasicAuthPassword {
+		return false
+	}
 	return true
 }
 func (this *NewTunnelResponse) Equal(that interface{}) bool {
@@ -978,9 +1411,21 @@ func (this *ShutdownRequest) Equal(that interface{}) bool {
 	if this.ClientHash != that1.ClientHash {
 		return false
 	}
+	if this.Reconnect != that1.Reconnect {
+		return false
+	}
+	if this.ReconnectAddr != that1.ReconnectAddr {
+		return false
+	}
+	if this.ReconnectDelay != that1.ReconnectDelay {
+		return false
+	}
+	if this.Restart != that1.Restart {
+		return false
+	}
 	return true
 }
-func (this *Message) Equal(that interface{}) bool {
+func (this *PunchRequest) Equal(that interface{}) bool {
 	if that == nil {
 		if this == nil {
 			return true
@@ -988,9 +1433,9 @@ func (this *Message) Equal(that interface{}) bool {
 		return false
 	}
 
-	that1, ok := that.(*Message)
+	that1, ok := that.(*PunchRequest)
 	if !ok {
-		that2, ok := that.(Message)
+		that2, ok := that.(PunchRequest)
 		if ok {
 			that1 = &that2
 		} else {
@@ -1005,18 +1450,24 @@ func (this *Message) Equal(that interface{}) bool {
 	} else if this == nil {
 		return false
 	}
-	if that1.Body == nil {
-		if this.Body != nil {
-			return false
-		}
-	} else if this.Body == nil {
+	if this.ID != that1.ID {
 		return false
-	} else if !this.Body.Equal(that1.Body) {
+	}
+	if this.ClientHash != that1.ClientHash {
+		return false
+	}
+	if this.TunnelHash != that1.TunnelHash {
+		return false
+	}
+	if this.PeerAddr != that1.PeerAddr {
+		return false
+	}
+	if this.Nonce != that1.Nonce {
 		return false
 	}
 	return true
 }
-func (this *Message_HandshakeRequest) Equal(that interface{}) bool {
+func (this *DeclaredTunnelResult) Equal(that interface{}) bool {
 	if that == nil {
 		if this == nil {
 			return true
@@ -1024,9 +1475,9 @@ func (this *Message_HandshakeRequest) Equal(that interface{}) bool {
 		return false
 	}
 
-	that1, ok := that.(*Message_HandshakeRequest)
+	that1, ok := that.(*DeclaredTunnelResult)
 	if !ok {
-		that2, ok := that.(Message_HandshakeRequest)
+		that2, ok := that.(DeclaredTunnelResult)
 		if ok {
 			that1 = &that2
 		} else {
@@ -1041,12 +1492,24 @@ func (this *Message_HandshakeRequest) Equal(that interface{}) bool {
 	} else if this == nil {
 		return false
 	}
-	if !this.HandshakeRequest.Equal(that1.HandshakeRequest) {
+	if this.Tag != that1.Tag {
+		return false
+	}
+	if this.Accepted != that1.Accepted {
+		return false
+	}
+	if this.TunnelHash != that1.TunnelHash {
+		return false
+	}
+	if this.ServerAddr != that1.ServerAddr {
+		return false
+	}
+	if this.Reason != that1.Reason {
 		return false
 	}
 	return true
 }
-func (this *Message_HandshakeResponse) Equal(that interface{}) bool {
+func (this *DeclareTunnelsResponse) Equal(that interface{}) bool {
 	if that == nil {
 		if this == nil {
 			return true
@@ -1054,9 +1517,9 @@ func (this *Message_HandshakeResponse) Equal(that interface{}) bool {
 		return false
 	}
 
-	that1, ok := that.(*Message_HandshakeResponse)
+	that1, ok := that.(*DeclareTunnelsResponse)
 	if !ok {
-		that2, ok := that.(Message_HandshakeResponse)
+		that2, ok := that.(DeclareTunnelsResponse)
 		if ok {
 			that1 = &that2
 		} else {
@@ -1071,12 +1534,17 @@ func (this *Message_HandshakeResponse) Equal(that interface{}) bool {
 	} else if this == nil {
 		return false
 	}
-	if !this.HandshakeResponse.Equal(that1.HandshakeResponse) {
+	if len(this.Results) != len(that1.Results) {
 		return false
 	}
+	for i := range this.Results {
+		if !this.Results[i].Equal(that1.Results[i]) {
+			return false
+		}
+	}
 	return true
 }
-func (this *Message_TunnelHandshakeRequest) Equal(that interface{}) bool {
+func (this *HealthReport) Equal(that interface{}) bool {
 	if that == nil {
 		if this == nil {
 			return true
@@ -1084,9 +1552,9 @@ func (this *Message_TunnelHandshakeRequest) Equal(that interface{}) bool {
 		return false
 	}
 
-	that1, ok := that.(*Message_TunnelHandshakeRequest)
+	that1, ok := that.(*HealthReport)
 	if !ok {
-		that2, ok := that.(Message_TunnelHandshakeRequest)
+		that2, ok := that.(HealthReport)
 		if ok {
 			that1 = &that2
 		} else {
@@ -1101,12 +1569,24 @@ func (this *Message_TunnelHandshakeRequest) Equal(that interface{}) bool {
 	} else if this == nil {
 		return false
 	}
-	if !this.TunnelHandshakeRequest.Equal(that1.TunnelHandshakeRequest) {
+	if this.ID != that1.ID {
+		return false
+	}
+	if this.ClientHash != that1.ClientHash {
+		return false
+	}
+	if this.TunnelHash != that1.TunnelHash {
+		return false
+	}
+	if this.Healthy != that1.Healthy {
+		return false
+	}
+	if this.Reason != that1.Reason {
 		return false
 	}
 	return true
 }
-func (this *Message_TunnelHandshakeResponse) Equal(that interface{}) bool {
+func (this *DialErrorReport) Equal(that interface{}) bool {
 	if that == nil {
 		if this == nil {
 			return true
@@ -1114,9 +1594,9 @@ func (this *Message_TunnelHandshakeResponse) Equal(that interface{}) bool {
 		return false
 	}
 
-	that1, ok := that.(*Message_TunnelHandshakeResponse)
+	that1, ok := that.(*DialErrorReport)
 	if !ok {
-		that2, ok := that.(Message_TunnelHandshakeResponse)
+		that2, ok := that.(DialErrorReport)
 		if ok {
 			that1 = &that2
 		} else {
@@ -1131,12 +1611,27 @@ func (this *Message_TunnelHandshakeResponse) Equal(that interface{}) bool {
 	} else if this == nil {
 		return false
 	}
-	if !this.TunnelHandshakeResponse.Equal(that1.TunnelHandshakeResponse) {
+	if this.ID != that1.ID {
+		return false
+	}
+	if this.ClientHash != that1.ClientHash {
+		return false
+	}
+	if this.TunnelHash != that1.TunnelHash {
+		return false
+	}
+	if this.Class != that1.Class {
+		return false
+	}
+	if this.Reason != that1.Reason {
+		return false
+	}
+	if this.Count != that1.Count {
 		return false
 	}
 	return true
 }
-func (this *Message_PingRequest) Equal(that interface{}) bool {
+func (this *PunchReport) Equal(that interface{}) bool {
 	if that == nil {
 		if this == nil {
 			return true
@@ -1144,9 +1639,9 @@ func (this *Message_PingRequest) Equal(that interface{}) bool {
 		return false
 	}
 
-	that1, ok := that.(*Message_PingRequest)
+	that1, ok := that.(*PunchReport)
 	if !ok {
-		that2, ok := that.(Message_PingRequest)
+		that2, ok := that.(PunchReport)
 		if ok {
 			that1 = &that2
 		} else {
@@ -1161,12 +1656,24 @@ func (this *Message_PingRequest) Equal(that interface{}) bool {
 	} else if this == nil {
 		return false
 	}
-	if !this.PingRequest.Equal(that1.PingRequest) {
+	if this.ID != that1.ID {
 		return false
 	}
-	return true
+	if this.ClientHash != that1.ClientHash {
+		return false
+	}
+	if this.TunnelHash != that1.TunnelHash {
+		return false
+	}
+	if this.Direct != that1.Direct {
+		return false
+	}
+	if this.Reason != that1.Reason {
+		return false
+	}
+	return true
 }
-func (this *Message_PingResponse) Equal(that interface{}) bool {
+func (this *DeclaredTunnel) Equal(that interface{}) bool {
 	if that == nil {
 		if this == nil {
 			return true
@@ -1174,9 +1681,9 @@ func (this *Message_PingResponse) Equal(that interface{}) bool {
 		return false
 	}
 
-	that1, ok := that.(*Message_PingResponse)
+	that1, ok := that.(*DeclaredTunnel)
 	if !ok {
-		that2, ok := that.(Message_PingResponse)
+		that2, ok := that.(DeclaredTunnel)
 		if ok {
 			that1 = &that2
 		} else {
@@ -1191,12 +1698,21 @@ func (this *Message_PingResponse) Equal(that interface{}) bool {
 	} else if this == nil {
 		return false
 	}
-	if !this.PingResponse.Equal(that1.PingResponse) {
+	if this.Tag != that1.Tag {
+		return false
+	}
+	if this.Proto != that1.Proto {
+		return false
+	}
+	if this.ExportAddr != that1.ExportAddr {
+		return false
+	}
+	if this.ServerAddr != that1.ServerAddr {
 		return false
 	}
 	return true
 }
-func (this *Message_NewTunnelRequest) Equal(that interface{}) bool {
+func (this *DeclareTunnelsRequest) Equal(that interface{}) bool {
 	if that == nil {
 		if this == nil {
 			return true
@@ -1204,9 +1720,9 @@ func (this *Message_NewTunnelRequest) Equal(that interface{}) bool {
 		return false
 	}
 
-	that1, ok := that.(*Message_NewTunnelRequest)
+	that1, ok := that.(*DeclareTunnelsRequest)
 	if !ok {
-		that2, ok := that.(Message_NewTunnelRequest)
+		that2, ok := that.(DeclareTunnelsRequest)
 		if ok {
 			that1 = &that2
 		} else {
@@ -1221,12 +1737,23 @@ func (this *Message_NewTunnelRequest) Equal(that interface{}) bool {
 	} else if this == nil {
 		return false
 	}
-	if !this.NewTunnelRequest.Equal(that1.NewTunnelRequest) {
+	if this.ID != that1.ID {
+		return false
+	}
+	if this.ClientHash != that1.ClientHash {
+		return false
+	}
+	if len(this.Tunnels) != len(that1.Tunnels) {
 		return false
 	}
+	for i := range this.Tunnels {
+		if !this.Tunnels[i].Equal(that1.Tunnels[i]) {
+			return false
+		}
+	}
 	return true
 }
-func (this *Message_NewTunnelResponse) Equal(that interface{}) bool {
+func (this *Message) Equal(that interface{}) bool {
 	if that == nil {
 		if this == nil {
 			return true
@@ -1234,9 +1761,9 @@ func (this *Message_NewTunnelResponse) Equal(that interface{}) bool {
 		return false
 	}
 
-	that1, ok := that.(*Message_NewTunnelResponse)
+	that1, ok := that.(*Message)
 	if !ok {
-		that2, ok := that.(Message_NewTunnelResponse)
+		that2, ok := that.(Message)
 		if ok {
 			that1 = &that2
 		} else {
@@ -1251,12 +1778,18 @@ func (this *Message_NewTunnelResponse) Equal(that interface{}) bool {
 	} else if this == nil {
 		return false
 	}
-	if !this.NewTunnelResponse.Equal(that1.NewTunnelResponse) {
+	if that1.Body == nil {
+		if this.Body != nil {
+			return false
+		}
+	} else if this.Body == nil {
+		return false
+	} else if !this.Body.Equal(that1.Body) {
 		return false
 	}
 	return true
 }
-func (this *Message_CloseTunnelRequest) Equal(that interface{}) bool {
+func (this *Message_HandshakeRequest) Equal(that interface{}) bool {
 	if that == nil {
 		if this == nil {
 			return true
@@ -1264,9 +1797,9 @@ func (this *Message_CloseTunnelRequest) Equal(that interface{}) bool {
 		return false
 	}
 
-	that1, ok := that.(*Message_CloseTunnelRequest)
+	that1, ok := that.(*Message_HandshakeRequest)
 	if !ok {
-		that2, ok := that.(Message_CloseTunnelRequest)
+		that2, ok := that.(Message_HandshakeRequest)
 		if ok {
 			that1 = &that2
 		} else {
@@ -1281,12 +1814,12 @@ func (this *Message_CloseTunnelRequest) Equal(that interface{}) bool {
 	} else if this == nil {
 		return false
 	}
-	if !this.CloseTunnelRequest.Equal(that1.CloseTunnelRequest) {
+	if !this.HandshakeRequest.Equal(that1.HandshakeRequest) {
 		return false
 	}
 	return true
 }
-func (this *Message_CloseTunnelResponse) Equal(that interface{}) bool {
+func (this *Message_HandshakeResponse) Equal(that interface{}) bool {
 	if that == nil {
 		if this == nil {
 			return true
@@ -1294,9 +1827,9 @@ func (this *Message_CloseTunnelResponse) Equal(that interface{}) bool {
 		return false
 	}
 
-	that1, ok := that.(*Message_CloseTunnelResponse)
+	that1, ok := that.(*Message_HandshakeResponse)
 	if !ok {
-		that2, ok := that.(Message_CloseTunnelResponse)
+		that2, ok := that.(Message_HandshakeResponse)
 		if ok {
 			that1 = &that2
 		} else {
@@ -1311,12 +1844,12 @@ func (this *Message_CloseTunnelResponse) Equal(that interface{}) bool {
 	} else if this == nil {
 		return false
 	}
-	if !this.CloseTunnelResponse.Equal(that1.CloseTunnelResponse) {
+	if !this.HandshakeResponse.Equal(that1.HandshakeResponse) {
 		return false
 	}
 	return true
 }
-func (this *Message_ShutdownRequest) Equal(that interface{}) bool {
+func (this *Message_TunnelHandshakeRequest) Equal(that interface{}) bool {
 	if that == nil {
 		if this == nil {
 			return true
@@ -1324,9 +1857,9 @@ func (this *Message_ShutdownRequest) Equal(that interface{}) bool {
 		return false
 	}
 
-	that1, ok := that.(*Message_ShutdownRequest)
+	that1, ok := that.(*Message_TunnelHandshakeRequest)
 	if !ok {
-		that2, ok := that.(Message_ShutdownRequest)
+		that2, ok := that.(Message_TunnelHandshakeRequest)
 		if ok {
 			that1 = &that2
 		} else {
@@ -1341,1318 +1874,4858 @@ func (this *Message_ShutdownRequest) Equal(that interface{}) bool {
 	} else if this == nil {
 		return false
 	}
-	if !this.ShutdownRequest.Equal(that1.ShutdownRequest) {
+	if !this.TunnelHandshakeRequest.Equal(that1.TunnelHandshakeRequest) {
 		return false
 	}
 	return true
 }
-func (this *HandshakeRequest) GoString() string {
-	if this == nil {
-		return "nil"
+func (this *Message_TunnelHandshakeResponse) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
 	}
-	s := make([]string, 0, 8)
-	s = append(s, "&msgpb.HandshakeRequest{")
-	s = append(s, "ID: "+fmt.Sprintf("%#v", this.ID)+",\n")
-	s = append(s, "Hash: "+fmt.Sprintf("%#v", this.Hash)+",\n")
-	s = append(s, "Version: "+fmt.Sprintf("%#v", this.Version)+",\n")
-	s = append(s, "Device: "+fmt.Sprintf("%#v", this.Device)+",\n")
-	s = append(s, "}")
-	return strings.Join(s, "")
-}
-func (this *HandshakeResponse) GoString() string {
-	if this == nil {
-		return "nil"
+
+	that1, ok := that.(*Message_TunnelHandshakeResponse)
+	if !ok {
+		that2, ok := that.(Message_TunnelHandshakeResponse)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
 	}
-	s := make([]string, 0, 5)
-	s = append(s, "&msgpb.HandshakeResponse{")
-	s = append(s, "ErrCode: "+fmt.Sprintf("%#v", this.ErrCode)+",\n")
-	s = append(s, "}")
-	return strings.Join(s, "")
-}
-func (this *PingRequest) GoString() string {
-	if this == nil {
-		return "nil"
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
 	}
-	s := make([]string, 0, 4)
-	s = append(s, "&msgpb.PingRequest{")
-	s = append(s, "}")
-	return strings.Join(s, "")
-}
-func (this *PingResponse) GoString() string {
-	if this == nil {
-		return "nil"
+	if !this.TunnelHandshakeResponse.Equal(that1.TunnelHandshakeResponse) {
+		return false
 	}
-	s := make([]string, 0, 4)
-	s = append(s, "&msgpb.PingResponse{")
-	s = append(s, "}")
-	return strings.Join(s, "")
+	return true
 }
-func (this *TunnelHandshakeRequest) GoString() string {
-	if this == nil {
-		return "nil"
+func (this *Message_PingRequest) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
 	}
-	s := make([]string, 0, 7)
-	s = append(s, "&msgpb.TunnelHandshakeRequest{")
-	s = append(s, "ID: "+fmt.Sprintf("%#v", this.ID)+",\n")
-	s = append(s, "ClientHash: "+fmt.Sprintf("%#v", this.ClientHash)+",\n")
-	s = append(s, "TunnelHash: "+fmt.Sprintf("%#v", this.TunnelHash)+",\n")
-	s = append(s, "}")
-	return strings.Join(s, "")
-}
-func (this *TunnelHandshakeResponse) GoString() string {
-	if this == nil {
-		return "nil"
+
+	that1, ok := that.(*Message_PingRequest)
+	if !ok {
+		that2, ok := that.(Message_PingRequest)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
 	}
-	s := make([]string, 0, 5)
-	s = append(s, "&msgpb.TunnelHandshakeResponse{")
-	s = append(s, "ErrCode: "+fmt.Sprintf("%#v", this.ErrCode)+",\n")
-	s = append(s, "}")
-	return strings.Join(s, "")
-}
-func (this *NewTunnelRequest) GoString() string {
-	if this == nil {
-		return "nil"
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
 	}
-	s := make([]string, 0, 10)
-	s = append(s, "&msgpb.NewTunnelRequest{")
-	s = append(s, "ID: "+fmt.Sprintf("%#v", this.ID)+",\n")
-	s = append(s, "ClientHash: "+fmt.Sprintf("%#v", this.ClientHash)+",\n")
-	s = append(s, "TunnelHash: "+fmt.Sprintf("%#v", this.TunnelHash)+",\n")
-	s = append(s, "Proto: "+fmt.Sprintf("%#v", this.Proto)+",\n")
-	s = append(s, "ExportAddr: "+fmt.Sprintf("%#v", this.ExportAddr)+",\n")
-	s = append(s, "RegistryAddr: "+fmt.Sprintf("%#v", this.RegistryAddr)+",\n")
-	s = append(s, "}")
-	return strings.Join(s, "")
-}
-func (this *NewTunnelResponse) GoString() string {
-	if this == nil {
-		return "nil"
+	if !this.PingRequest.Equal(that1.PingRequest) {
+		return false
 	}
-	s := make([]string, 0, 6)
-	s = append(s, "&msgpb.NewTunnelResponse{")
-	s = append(s, "TunnelHash: "+fmt.Sprintf("%#v", this.TunnelHash)+",\n")
-	s = append(s, "ErrCode: "+fmt.Sprintf("%#v", this.ErrCode)+",\n")
-	s = append(s, "}")
-	return strings.Join(s, "")
+	return true
 }
-func (this *CloseTunnelRequest) GoString() string {
-	if this == nil {
-		return "nil"
+func (this *Message_PingResponse) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
 	}
-	s := make([]string, 0, 7)
-	s = append(s, "&msgpb.CloseTunnelRequest{")
-	s = append(s, "ID: "+fmt.Sprintf("%#v", this.ID)+",\n")
-	s = append(s, "ClientHash: "+fmt.Sprintf("%#v", this.ClientHash)+",\n")
-	s = append(s, "TunnelHash: "+fmt.Sprintf("%#v", this.TunnelHash)+",\n")
-	s = append(s, "}")
-	return strings.Join(s, "")
-}
-func (this *CloseTunnelResponse) GoString() string {
-	if this == nil {
-		return "nil"
+
+	that1, ok := that.(*Message_PingResponse)
+	if !ok {
+		that2, ok := that.(Message_PingResponse)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
 	}
-	s := make([]string, 0, 6)
-	s = append(s, "&msgpb.CloseTunnelResponse{")
-	s = append(s, "TunnelHash: "+fmt.Sprintf("%#v", this.TunnelHash)+",\n")
-	s = append(s, "ErrCode: "+fmt.Sprintf("%#v", this.ErrCode)+",\n")
-	s = append(s, "}")
-	return strings.Join(s, "")
-}
-func (this *ShutdownRequest) GoString() string {
-	if this == nil {
-		return "nil"
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
 	}
-	s := make([]string, 0, 6)
-	s = append(s, "&msgpb.ShutdownRequest{")
-	s = append(s, "ID: "+fmt.Sprintf("%#v", this.ID)+",\n")
-	s = append(s, "ClientHash: "+fmt.Sprintf("%#v", this.ClientHash)+",\n")
-	s = append(s, "}")
-	return strings.Join(s, "")
-}
-func (this *Message) GoString() string {
-	if this == nil {
-		return "nil"
-	}
-	s := make([]string, 0, 15)
-	s = append(s, "&msgpb.Message{")
-	if this.Body != nil {
-		s = append(s, "Body: "+fmt.Sprintf("%#v", this.Body)+",\n")
+	if !this.PingResponse.Equal(that1.PingResponse) {
+		return false
 	}
-	s = append(s, "}")
-	return strings.Join(s, "")
+	return true
 }
-func (this *Message_HandshakeRequest) GoString() string {
-	if this == nil {
-		return "nil"
+func (this *Message_NewTunnelRequest) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
 	}
-	s := strings.Join([]string{`&msgpb.Message_HandshakeRequest{` +
-		`HandshakeRequest:` + fmt.Sprintf("%#v", this.HandshakeRequest) + `}`}, ", ")
-	return s
-}
-func (this *Message_HandshakeResponse) GoString() string {
-	if this == nil {
-		return "nil"
+
+	that1, ok := that.(*Message_NewTunnelRequest)
+	if !ok {
+		that2, ok := that.(Message_NewTunnelRequest)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
 	}
-	s := strings.Join([]string{`&msgpb.Message_HandshakeResponse{` +
-		`HandshakeResponse:` + fmt.Sprintf("%#v", this.HandshakeResponse) + `}`}, ", ")
-	return s
-}
-func (this *Message_TunnelHandshakeRequest) GoString() string {
-	if this == nil {
-		return "nil"
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
 	}
-	s := strings.Join([]string{`&msgpb.Message_TunnelHandshakeRequest{` +
-		`TunnelHandshakeRequest:` + fmt.Sprintf("%#v", this.TunnelHandshakeRequest) + `}`}, ", ")
-	return s
-}
-func (this *Message_TunnelHandshakeResponse) GoString() string {
-	if this == nil {
-		return "nil"
+	if !this.NewTunnelRequest.Equal(that1.NewTunnelRequest) {
+		return false
 	}
-	s := strings.Join([]string{`&msgpb.Message_TunnelHandshakeResponse{` +
-		`TunnelHandshakeResponse:` + fmt.Sprintf("%#v", this.TunnelHandshakeResponse) + `}`}, ", ")
-	return s
+	return true
 }
-func (this *Message_PingRequest) GoString() string {
-	if this == nil {
-		return "nil"
+func (this *Message_NewTunnelResponse) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
 	}
-	s := strings.Join([]string{`&msgpb.Message_PingRequest{` +
-		`PingRequest:` + fmt.Sprintf("%#v", this.PingRequest) + `}`}, ", ")
-	return s
-}
-func (this *Message_PingResponse) GoString() string {
-	if this == nil {
-		return "nil"
+
+	that1, ok := that.(*Message_NewTunnelResponse)
+	if !ok {
+		that2, ok := that.(Message_NewTunnelResponse)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
 	}
-	s := strings.Join([]string{`&msgpb.Message_PingResponse{` +
-		`PingResponse:` + fmt.Sprintf("%#v", this.PingResponse) + `}`}, ", ")
-	return s
-}
-func (this *Message_NewTunnelRequest) GoString() string {
-	if this == nil {
-		return "nil"
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
 	}
-	s := strings.Join([]string{`&msgpb.Message_NewTunnelRequest{` +
-		`NewTunnelRequest:` + fmt.Sprintf("%#v", this.NewTunnelRequest) + `}`}, ", ")
-	return s
-}
-func (this *Message_NewTunnelResponse) GoString() string {
-	if this == nil {
-		return "nil"
+	if !this.NewTunnelResponse.Equal(that1.NewTunnelResponse) {
+		return false
 	}
-	s := strings.Join([]string{`&msgpb.Message_NewTunnelResponse{` +
-		`NewTunnelResponse:` + fmt.Sprintf("%#v", this.NewTunnelResponse) + `}`}, ", ")
-	return s
+	return true
 }
-func (this *Message_CloseTunnelRequest) GoString() string {
-	if this == nil {
-		return "nil"
+func (this *Message_CloseTunnelRequest) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
 	}
-	s := strings.Join([]string{`&msgpb.Message_CloseTunnelRequest{` +
-		`CloseTunnelRequest:` + fmt.Sprintf("%#v", this.CloseTunnelRequest) + `}`}, ", ")
-	return s
-}
-func (this *Message_CloseTunnelResponse) GoString() string {
-	if this == nil {
-		return "nil"
+
+	that1, ok := that.(*Message_CloseTunnelRequest)
+	if !ok {
+		that2, ok := that.(Message_CloseTunnelRequest)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
 	}
-	s := strings.Join([]string{`&msgpb.Message_CloseTunnelResponse{` +
-		`CloseTunnelResponse:` + fmt.Sprintf("%#v", this.CloseTunnelResponse) + `}`}, ", ")
-	return s
-}
-func (this *Message_ShutdownRequest) GoString() string {
-	if this == nil {
-		return "nil"
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
 	}
-	s := strings.Join([]string{`&msgpb.Message_ShutdownRequest{` +
-		`ShutdownRequest:` + fmt.Sprintf("%#v", this.ShutdownRequest) + `}`}, ", ")
-	return s
-}
-func valueToGoStringMsg(v interface{}, typ string) string {
-	rv := reflect.ValueOf(v)
-	if rv.IsNil() {
-		return "nil"
+	if !this.CloseTunnelRequest.Equal(that1.CloseTunnelRequest) {
+		return false
 	}
-	pv := reflect.Indirect(rv).Interface()
-	return fmt.Sprintf("func(v %v) *%v { return &v } ( %#v )", typ, typ, pv)
+	return true
 }
-func (m *HandshakeRequest) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
-	if err != nil {
-		return nil, err
+func (this *Message_CloseTunnelResponse) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
 	}
-	return dAtA[:n], nil
-}
 
-func (m *HandshakeRequest) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if len(m.ID) > 0 {
-		dAtA[i] = 0xa
-		i++
-		i = encodeVarintMsg(dAtA, i, uint64(len(m.ID)))
-		i += copy(dAtA[i:], m.ID)
-	}
-	if len(m.Hash) > 0 {
-		dAtA[i] = 0x12
-		i++
-		i = encodeVarintMsg(dAtA, i, uint64(len(m.Hash)))
-		i += copy(dAtA[i:], m.Hash)
-	}
-	if len(m.Version) > 0 {
-		dAtA[i] = 0x1a
-		i++
-		i = encodeVarintMsg(dAtA, i, uint64(len(m.Version)))
-		i += copy(dAtA[i:], m.Version)
+	that1, ok := that.(*Message_CloseTunnelResponse)
+	if !ok {
+		that2, ok := that.(Message_CloseTunnelResponse)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
 	}
-	if len(m.Device) > 0 {
-		dAtA[i] = 0x22
-		i++
-		i = encodeVarintMsg(dAtA, i, uint64(len(m.Device)))
-		i += copy(dAtA[i:], m.Device)
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
 	}
-	return i, nil
-}
-
-func (m *HandshakeResponse) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
-	if err != nil {
-		return nil, err
+	if !this.CloseTunnelResponse.Equal(that1.CloseTunnelResponse) {
+		return false
 	}
-	return dAtA[:n], nil
+	return true
 }
-
-func (m *HandshakeResponse) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if m.ErrCode != 0 {
-		dAtA[i] = 0x8
-		i++
-		i = encodeVarintMsg(dAtA, i, uint64(m.ErrCode))
+func (this *Message_ShutdownRequest) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
 	}
-	return i, nil
-}
 
-func (m *PingRequest) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
-	if err != nil {
-		return nil, err
+	that1, ok := that.(*Message_ShutdownRequest)
+	if !ok {
+		that2, ok := that.(Message_ShutdownRequest)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
 	}
-	return dAtA[:n], nil
-}
-
-func (m *PingRequest) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	return i, nil
-}
-
-func (m *PingResponse) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
-	if err != nil {
-		return nil, err
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
 	}
-	return dAtA[:n], nil
-}
-
-func (m *PingResponse) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	return i, nil
-}
-
-func (m *TunnelHandshakeRequest) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
-	if err != nil {
-		return nil, err
+	if !this.ShutdownRequest.Equal(that1.ShutdownRequest) {
+		return false
 	}
-	return dAtA[:n], nil
+	return true
 }
-
-func (m *TunnelHandshakeRequest) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if len(m.ID) > 0 {
-		dAtA[i] = 0xa
-		i++
-		i = encodeVarintMsg(dAtA, i, uint64(len(m.ID)))
-		i += copy(dAtA[i:], m.ID)
+func (this *Message_HealthReport) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
 	}
-	if len(m.ClientHash) > 0 {
-		dAtA[i] = 0x12
-		i++
-		i = encodeVarintMsg(dAtA, i, uint64(len(m.ClientHash)))
-		i += copy(dAtA[i:], m.ClientHash)
+
+	that1, ok := that.(*Message_HealthReport)
+	if !ok {
+		that2, ok := that.(Message_HealthReport)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
 	}
-	if len(m.TunnelHash) > 0 {
-		dAtA[i] = 0x1a
-		i++
-		i = encodeVarintMsg(dAtA, i, uint64(len(m.TunnelHash)))
-		i += copy(dAtA[i:], m.TunnelHash)
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
 	}
-	return i, nil
-}
-
-func (m *TunnelHandshakeResponse) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
-	if err != nil {
-		return nil, err
+	if !this.HealthReport.Equal(that1.HealthReport) {
+		return false
 	}
-	return dAtA[:n], nil
+	return true
 }
-
-func (m *TunnelHandshakeResponse) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if m.ErrCode != 0 {
-		dAtA[i] = 0x8
-		i++
-		i = encodeVarintMsg(dAtA, i, uint64(m.ErrCode))
+func (this *Message_DialErrorReport) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
 	}
-	return i, nil
-}
 
-func (m *NewTunnelRequest) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
-	if err != nil {
-		return nil, err
+	that1, ok := that.(*Message_DialErrorReport)
+	if !ok {
+		that2, ok := that.(Message_DialErrorReport)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
 	}
-	return dAtA[:n], nil
-}
-
-func (m *NewTunnelRequest) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if len(m.ID) > 0 {
-		dAtA[i] = 0xa
-		i++
-		i = encodeVarintMsg(dAtA, i, uint64(len(m.ID)))
-		i += copy(dAtA[i:], m.ID)
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
 	}
-	if len(m.ClientHash) > 0 {
-		dAtA[i] = 0x12
-		i++
-		i = encodeVarintMsg(dAtA, i, uint64(len(m.ClientHash)))
-		i += copy(dAtA[i:], m.ClientHash)
+	if !this.DialErrorReport.Equal(that1.DialErrorReport) {
+		return false
 	}
-	if len(m.TunnelHash) > 0 {
-		dAtA[i] = 0x1a
-		i++
-		i = encodeVarintMsg(dAtA, i, uint64(len(m.TunnelHash)))
-		i += copy(dAtA[i:], m.TunnelHash)
+	return true
+}
+func (this *Message_PunchRequest) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
 	}
-	if len(m.Proto) > 0 {
-		dAtA[i] = 0x22
-		i++
-		i = encodeVarintMsg(dAtA, i, uint64(len(m.Proto)))
-		i += copy(dAtA[i:], m.Proto)
+
+	that1, ok := that.(*Message_PunchRequest)
+	if !ok {
+		that2, ok := that.(Message_PunchRequest)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
 	}
-	if len(m.ExportAddr) > 0 {
-		dAtA[i] = 0x2a
-		i++
-		i = encodeVarintMsg(dAtA, i, uint64(len(m.ExportAddr)))
-		i += copy(dAtA[i:], m.ExportAddr)
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
 	}
-	if len(m.RegistryAddr) > 0 {
-		dAtA[i] = 0x32
-		i++
-		i = encodeVarintMsg(dAtA, i, uint64(len(m.RegistryAddr)))
-		i += copy(dAtA[i:], m.RegistryAddr)
+	if !this.PunchRequest.Equal(that1.PunchRequest) {
+		return false
 	}
-	return i, nil
+	return true
 }
-
-func (m *NewTunnelResponse) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
-	if err != nil {
-		return nil, err
+func (this *Message_PunchReport) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
 	}
-	return dAtA[:n], nil
-}
 
-func (m *NewTunnelResponse) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if len(m.TunnelHash) > 0 {
-		dAtA[i] = 0xa
-		i++
-		i = encodeVarintMsg(dAtA, i, uint64(len(m.TunnelHash)))
-		i += copy(dAtA[i:], m.TunnelHash)
+	that1, ok := that.(*Message_PunchReport)
+	if !ok {
+		that2, ok := that.(Message_PunchReport)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
 	}
-	if m.ErrCode != 0 {
-		dAtA[i] = 0x10
-		i++
-		i = encodeVarintMsg(dAtA, i, uint64(m.ErrCode))
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
 	}
-	return i, nil
+	if !this.PunchReport.Equal(that1.PunchReport) {
+		return false
+	}
+	return true
 }
-
-func (m *CloseTunnelRequest) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
-	if err != nil {
-		return nil, err
+func (this *Message_DeclareTunnelsRequest) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
 	}
-	return dAtA[:n], nil
-}
 
-func (m *CloseTunnelRequest) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if len(m.ID) > 0 {
-		dAtA[i] = 0xa
-		i++
-		i = encodeVarintMsg(dAtA, i, uint64(len(m.ID)))
-		i += copy(dAtA[i:], m.ID)
+	that1, ok := that.(*Message_DeclareTunnelsRequest)
+	if !ok {
+		that2, ok := that.(Message_DeclareTunnelsRequest)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
 	}
-	if len(m.ClientHash) > 0 {
-		dAtA[i] = 0x12
-		i++
-		i = encodeVarintMsg(dAtA, i, uint64(len(m.ClientHash)))
-		i += copy(dAtA[i:], m.ClientHash)
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
 	}
-	if len(m.TunnelHash) > 0 {
-		dAtA[i] = 0x1a
-		i++
-		i = encodeVarintMsg(dAtA, i, uint64(len(m.TunnelHash)))
-		i += copy(dAtA[i:], m.TunnelHash)
+	if !this.DeclareTunnelsRequest.Equal(that1.DeclareTunnelsRequest) {
+		return false
 	}
-	return i, nil
+	return true
 }
-
-func (m *CloseTunnelResponse) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
-	if err != nil {
-		return nil, err
+func (this *Message_DeclareTunnelsResponse) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
 	}
-	return dAtA[:n], nil
-}
 
-func (m *CloseTunnelResponse) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if len(m.TunnelHash) > 0 {
-		dAtA[i] = 0xa
-		i++
-		i = encodeVarintMsg(dAtA, i, uint64(len(m.TunnelHash)))
-		i += copy(dAtA[i:], m.TunnelHash)
+	that1, ok := that.(*Message_DeclareTunnelsResponse)
+	if !ok {
+		that2, ok := that.(Message_DeclareTunnelsResponse)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
 	}
-	if m.ErrCode != 0 {
-		dAtA[i] = 0x10
-		i++
-		i = encodeVarintMsg(dAtA, i, uint64(m.ErrCode))
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
 	}
-	return i, nil
-}
-
-func (m *ShutdownRequest) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
-	if err != nil {
-		return nil, err
+	if !this.DeclareTunnelsResponse.Equal(that1.DeclareTunnelsResponse) {
+		return false
 	}
-	return dAtA[:n], nil
+	return true
 }
-
-func (m *ShutdownRequest) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if len(m.ID) > 0 {
-		dAtA[i] = 0xa
-		i++
-		i = encodeVarintMsg(dAtA, i, uint64(len(m.ID)))
-		i += copy(dAtA[i:], m.ID)
-	}
-	if len(m.ClientHash) > 0 {
-		dAtA[i] = 0x12
-		i++
-		i = encodeVarintMsg(dAtA, i, uint64(len(m.ClientHash)))
-		i += copy(dAtA[i:], m.ClientHash)
+func (this *HandshakeRequest) GoString() string {
+	if this == nil {
+		return "nil"
 	}
-	return i, nil
+	s := make([]string, 0, 8)
+	s = append(s, "&msgpb.HandshakeRequest{")
+	s = append(s, "ID: "+fmt.Sprintf("%#v", this.ID)+",\n")
+	s = append(s, "Hash: "+fmt.Sprintf("%#v", this.Hash)+",\n")
+	s = append(s, "Version: "+fmt.Sprintf("%#v", this.Version)+",\n")
+	s = append(s, "Device: "+fmt.Sprintf("%#v", this.Device)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
 }
-
-func (m *Message) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
-	if err != nil {
-		return nil, err
+func (this *HandshakeResponse) GoString() string {
+	if this == nil {
+		return "nil"
 	}
-	return dAtA[:n], nil
+	s := make([]string, 0, 6)
+	s = append(s, "&msgpb.HandshakeResponse{")
+	s = append(s, "ErrCode: "+fmt.Sprintf("%#v", this.ErrCode)+",\n")
+	s = append(s, "RegistryAddr: "+fmt.Sprintf("%#v", this.RegistryAddr)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
 }
-
-func (m *Message) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if m.Body != nil {
-		nn1, err := m.Body.MarshalTo(dAtA[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += nn1
+func (this *PingRequest) GoString() string {
+	if this == nil {
+		return "nil"
 	}
-	return i, nil
+	s := make([]string, 0, 4)
+	s = append(s, "&msgpb.PingRequest{")
+	s = append(s, "}")
+	return strings.Join(s, "")
 }
-
-func (m *Message_HandshakeRequest) MarshalTo(dAtA []byte) (int, error) {
-	i := 0
-	if m.HandshakeRequest != nil {
-		dAtA[i] = 0xa
-		i++
-		i = encodeVarintMsg(dAtA, i, uint64(m.HandshakeRequest.Size()))
-		n2, err := m.HandshakeRequest.MarshalTo(dAtA[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n2
+func (this *PingResponse) GoString() string {
+	if this == nil {
+		return "nil"
 	}
-	return i, nil
+	s := make([]string, 0, 4)
+	s = append(s, "&msgpb.PingResponse{")
+	s = append(s, "}")
+	return strings.Join(s, "")
 }
-func (m *Message_HandshakeResponse) MarshalTo(dAtA []byte) (int, error) {
-	i := 0
-	if m.HandshakeResponse != nil {
-		dAtA[i] = 0x12
-		i++
-		i = encodeVarintMsg(dAtA, i, uint64(m.HandshakeResponse.Size()))
-		n3, err := m.HandshakeResponse.MarshalTo(dAtA[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n3
+func (this *TunnelHandshakeRequest) GoString() string {
+	if this == nil {
+		return "nil"
 	}
-	return i, nil
+	s := make([]string, 0, 11)
+	s = append(s, "&msgpb.TunnelHandshakeRequest{")
+	s = append(s, "ID: "+fmt.Sprintf("%#v", this.ID)+",\n")
+	s = append(s, "ClientHash: "+fmt.Sprintf("%#v", this.ClientHash)+",\n")
+	s = append(s, "TunnelHash: "+fmt.Sprintf("%#v", this.TunnelHash)+",\n")
+	s = append(s, "Visitor: "+fmt.Sprintf("%#v", this.Visitor)+",\n")
+	s = append(s, "SecretKey: "+fmt.Sprintf("%#v", this.SecretKey)+",\n")
+	s = append(s, "Punch: "+fmt.Sprintf("%#v", this.Punch)+",\n")
+	s = append(s, "Nonce: "+fmt.Sprintf("%#v", this.Nonce)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
 }
-func (m *Message_TunnelHandshakeRequest) MarshalTo(dAtA []byte) (int, error) {
-	i := 0
-	if m.TunnelHandshakeRequest != nil {
-		dAtA[i] = 0x1a
-		i++
-		i = encodeVarintMsg(dAtA, i, uint64(m.TunnelHandshakeRequest.Size()))
-		n4, err := m.TunnelHandshakeRequest.MarshalTo(dAtA[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n4
+func (this *TunnelHandshakeResponse) GoString() string {
+	if this == nil {
+		return "nil"
 	}
-	return i, nil
+	s := make([]string, 0, 7)
+	s = append(s, "&msgpb.TunnelHandshakeResponse{")
+	s = append(s, "ErrCode: "+fmt.Sprintf("%#v", this.ErrCode)+",\n")
+	s = append(s, "PeerAddr: "+fmt.Sprintf("%#v", this.PeerAddr)+",\n")
+	s = append(s, "Nonce: "+fmt.Sprintf("%#v", this.Nonce)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
 }
-func (m *Message_TunnelHandshakeResponse) MarshalTo(dAtA []byte) (int, error) {
-	i := 0
-	if m.TunnelHandshakeResponse != nil {
-		dAtA[i] = 0x22
-		i++
-		i = encodeVarintMsg(dAtA, i, uint64(m.TunnelHandshakeResponse.Size()))
-		n5, err := m.TunnelHandshakeResponse.MarshalTo(dAtA[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n5
+func (this *NewTunnelRequest) GoString() string {
+	if this == nil {
+		return "nil"
 	}
-	return i, nil
+	s := make([]string, 0, 22)
+	s = append(s, "&msgpb.NewTunnelRequest{")
+	s = append(s, "ID: "+fmt.Sprintf("%#v", this.ID)+",\n")
+	s = append(s, "ClientHash: "+fmt.Sprintf("%#v", this.ClientHash)+",\n")
+	s = append(s, "TunnelHash: "+fmt.Sprintf("%#v", this.TunnelHash)+",\n")
+	s = append(s, "Proto: "+fmt.Sprintf("%#v", this.Proto)+",\n")
+	s = append(s, "ExportAddr: "+fmt.Sprintf("%#v", this.ExportAddr)+",\n")
+	s = append(s, "RegistryAddr: "+fmt.Sprintf("%#v", this.RegistryAddr)+",\n")
+	s = append(s, "HealthCheckPath: "+fmt.Sprintf("%#v", this.HealthCheckPath)+",\n")
+	s = append(s, "AllowCIDRs: "+fmt.Sprintf("%#v", this.AllowCIDRs)+",\n")
+	s = append(s, "AllowPorts: "+fmt.Sprintf("%#v", this.AllowPorts)+",\n")
+	s = append(s, "ProxyUser: "+fmt.Sprintf("%#v", this.ProxyUser)+",\n")
+	s = append(s, "ProxyPassword: "+fmt.Sprintf("%#v", this.ProxyPassword)+",\n")
+	s = append(s, "LocalTLS: "+fmt.Sprintf("%#v", this.LocalTLS)+",\n")
+	s = append(s, "TLSServerName: "+fmt.Sprintf("%#v", this.TLSServerName)+",\n")
+	s = append(s, "TLSSkipVerify: "+fmt.Sprintf("%#v", this.TLSSkipVerify)+",\n")
+	s = append(s, "TLSCA: "+fmt.Sprintf("%#v", this.TLSCA)+",\n")
+	s = append(s, "FileListing: "+fmt.Sprintf("%#v", this.FileListing)+",\n")
+	s = append(s, "BasicAuthUser: "+fmt.Sprintf("%#v", this.BasicAuthUser)+",\n")
+	s = append(s, "BasicAuthPassword: "+fmt.Sprintf("%#v", this.BasicAuthPassword)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
 }
-func (m *Message_PingRequest) MarshalTo(dAtA []byte) (int, error) {
-	i := 0
-	if m.PingRequest != nil {
-		dAtA[i] = 0x2a
-		i++
-		i = encodeVarintMsg(dAtA, i, uint64(m.PingRequest.Size()))
-		n6, err := m.PingRequest.MarshalTo(dAtA[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n6
+func (this *NewTunnelResponse) GoString() string {
+	if this == nil {
+		return "nil"
 	}
-	return i, nil
+	s := make([]string, 0, 6)
+	s = append(s, "&msgpb.NewTunnelResponse{")
+	s = append(s, "TunnelHash: "+fmt.Sprintf("%#v", this.TunnelHash)+",\n")
+	s = append(s, "ErrCode: "+fmt.Sprintf("%#v", this.ErrCode)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
 }
-func (m *Message_PingResponse) MarshalTo(dAtA []byte) (int, error) {
-	i := 0
-	if m.PingResponse != nil {
-		dAtA[i] = 0x32
-		i++
-		i = encodeVarintMsg(dAtA, i, uint64(m.PingResponse.Size()))
-		n7, err := m.PingResponse.MarshalTo(dAtA[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n7
+func (this *CloseTunnelRequest) GoString() string {
+	if this == nil {
+		return "nil"
 	}
-	return i, nil
+	s := make([]string, 0, 7)
+	s = append(s, "&msgpb.CloseTunnelRequest{")
+	s = append(s, "ID: "+fmt.Sprintf("%#v", this.ID)+",\n")
+	s = append(s, "ClientHash: "+fmt.Sprintf("%#v", this.ClientHash)+",\n")
+	s = append(s, "TunnelHash: "+fmt.Sprintf("%#v", this.TunnelHash)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
 }
-func (m *Message_NewTunnelRequest) MarshalTo(dAtA []byte) (int, error) {
-	i := 0
-	if m.NewTunnelRequest != nil {
-		dAtA[i] = 0x3a
-		i++
-		i = encodeVarintMsg(dAtA, i, uint64(m.NewTunnelRequest.Size()))
-		n8, err := m.NewTunnelRequest.MarshalTo(dAtA[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n8
+func (this *CloseTunnelResponse) GoString() string {
+	if this == nil {
+		return "nil"
 	}
-	return i, nil
+	s := make([]string, 0, 6)
+	s = append(s, "&msgpb.CloseTunnelResponse{")
+	s = append(s, "TunnelHash: "+fmt.Sprintf("%#v", this.TunnelHash)+",\n")
+	s = append(s, "ErrCode: "+fmt.Sprintf("%#v", this.ErrCode)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
 }
-func (m *Message_NewTunnelResponse) MarshalTo(dAtA []byte) (int, error) {
-	i := 0
-	if m.NewTunnelResponse != nil {
-		dAtA[i] = 0x42
-		i++
-		i = encodeVarintMsg(dAtA, i, uint64(m.NewTunnelResponse.Size()))
-		n9, err := m.NewTunnelResponse.MarshalTo(dAtA[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n9
+func (this *ShutdownRequest) GoString() string {
+	if this == nil {
+		return "nil"
 	}
-	return i, nil
+	s := make([]string, 0, 10)
+	s = append(s, "&msgpb.ShutdownRequest{")
+	s = append(s, "ID: "+fmt.Sprintf("%#v", this.ID)+",\n")
+	s = append(s, "ClientHash: "+fmt.Sprintf("%#v", this.ClientHash)+",\n")
+	s = append(s, "Reconnect: "+fmt.Sprintf("%#v", this.Reconnect)+",\n")
+	s = append(s, "ReconnectAddr: "+fmt.Sprintf("%#v", this.ReconnectAddr)+",\n")
+	s = append(s, "ReconnectDelay: "+fmt.Sprintf("%#v", this.ReconnectDelay)+",\n")
+	s = append(s, "Restart: "+fmt.Sprintf("%#v", this.Restart)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
 }
-func (m *Message_CloseTunnelRequest) MarshalTo(dAtA []byte) (int, error) {
-	i := 0
-	if m.CloseTunnelRequest != nil {
-		dAtA[i] = 0x4a
-		i++
-		i = encodeVarintMsg(dAtA, i, uint64(m.CloseTunnelRequest.Size()))
-		n10, err := m.CloseTunnelRequest.MarshalTo(dAtA[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n10
+func (this *PunchRequest) GoString() string {
+	if this == nil {
+		return "nil"
 	}
-	return i, nil
+	s := make([]string, 0, 9)
+	s = append(s, "&msgpb.PunchRequest{")
+	s = append(s, "ID: "+fmt.Sprintf("%#v", this.ID)+",\n")
+	s = append(s, "ClientHash: "+fmt.Sprintf("%#v", this.ClientHash)+",\n")
+	s = append(s, "TunnelHash: "+fmt.Sprintf("%#v", this.TunnelHash)+",\n")
+	s = append(s, "PeerAddr: "+fmt.Sprintf("%#v", this.PeerAddr)+",\n")
+	s = append(s, "Nonce: "+fmt.Sprintf("%#v", this.Nonce)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
 }
-func (m *Message_CloseTunnelResponse) MarshalTo(dAtA []byte) (int, error) {
-	i := 0
-	if m.CloseTunnelResponse != nil {
-		dAtA[i] = 0x52
-		i++
-		i = encodeVarintMsg(dAtA, i, uint64(m.CloseTunnelResponse.Size()))
-		n11, err := m.CloseTunnelResponse.MarshalTo(dAtA[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n11
+func (this *DeclaredTunnelResult) GoString() string {
+	if this == nil {
+		return "nil"
 	}
-	return i, nil
+	s := make([]string, 0, 9)
+	s = append(s, "&msgpb.DeclaredTunnelResult{")
+	s = append(s, "Tag: "+fmt.Sprintf("%#v", this.Tag)+",\n")
+	s = append(s, "Accepted: "+fmt.Sprintf("%#v", this.Accepted)+",\n")
+	s = append(s, "TunnelHash: "+fmt.Sprintf("%#v", this.TunnelHash)+",\n")
+	s = append(s, "ServerAddr: "+fmt.Sprintf("%#v", this.ServerAddr)+",\n")
+	s = append(s, "Reason: "+fmt.Sprintf("%#v", this.Reason)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
 }
-func (m *Message_ShutdownRequest) MarshalTo(dAtA []byte) (int, error) {
-	i := 0
-	if m.ShutdownRequest != nil {
-		dAtA[i] = 0x5a
-		i++
-		i = encodeVarintMsg(dAtA, i, uint64(m.ShutdownRequest.Size()))
-		n12, err := m.ShutdownRequest.MarshalTo(dAtA[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n12
+func (this *DeclareTunnelsResponse) GoString() string {
+	if this == nil {
+		return "nil"
 	}
-	return i, nil
-}
-func encodeFixed64Msg(dAtA []byte, offset int, v uint64) int {
-	dAtA[offset] = uint8(v)
-	dAtA[offset+1] = uint8(v >> 8)
-	dAtA[offset+2] = uint8(v >> 16)
-	dAtA[offset+3] = uint8(v >> 24)
-	dAtA[offset+4] = uint8(v >> 32)
-	dAtA[offset+5] = uint8(v >> 40)
-	dAtA[offset+6] = uint8(v >> 48)
-	dAtA[offset+7] = uint8(v >> 56)
-	return offset + 8
+	s := make([]string, 0, 5)
+	s = append(s, "&msgpb.DeclareTunnelsResponse{")
+	if this.Results != nil {
+		s = append(s, "Results: "+fmt.Sprintf("%#v", this.Results)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
 }
-func encodeFixed32Msg(dAtA []byte, offset int, v uint32) int {
-	dAtA[offset] = uint8(v)
-	dAtA[offset+1] = uint8(v >> 8)
-	dAtA[offset+2] = uint8(v >> 16)
-	dAtA[offset+3] = uint8(v >> 24)
-	return offset + 4
+func (this *HealthReport) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 9)
+	s = append(s, "&msgpb.HealthReport{")
+	s = append(s, "ID: "+fmt.Sprintf("%#v", this.ID)+",\n")
+	s = append(s, "ClientHash: "+fmt.Sprintf("%#v", this.ClientHash)+",\n")
+	s = append(s, "TunnelHash: "+fmt.Sprintf("%#v", this.TunnelHash)+",\n")
+	s = append(s, "Healthy: "+fmt.Sprintf("%#v", this.Healthy)+",\n")
+	s = append(s, "Reason: "+fmt.Sprintf("%#v", this.Reason)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
 }
-func encodeVarintMsg(dAtA []byte, offset int, v uint64) int {
-	for v >= 1<<7 {
-		dAtA[offset] = uint8(v&0x7f | 0x80)
-		v >>= 7
-		offset++
+func (this *DialErrorReport) GoString() string {
+	if this == nil {
+		return "nil"
 	}
-	dAtA[offset] = uint8(v)
-	return offset + 1
+	s := make([]string, 0, 10)
+	s = append(s, "&msgpb.DialErrorReport{")
+	s = append(s, "ID: "+fmt.Sprintf("%#v", this.ID)+",\n")
+	s = append(s, "ClientHash: "+fmt.Sprintf("%#v", this.ClientHash)+",\n")
+	s = append(s, "TunnelHash: "+fmt.Sprintf("%#v", this.TunnelHash)+",\n")
+	s = append(s, "Class: "+fmt.Sprintf("%#v", this.Class)+",\n")
+	s = append(s, "Reason: "+fmt.Sprintf("%#v", this.Reason)+",\n")
+	s = append(s, "Count: "+fmt.Sprintf("%#v", this.Count)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
 }
-func (m *HandshakeRequest) Size() (n int) {
-	var l int
-	_ = l
-	l = len(m.ID)
-	if l > 0 {
-		n += 1 + l + sovMsg(uint64(l))
+func (this *PunchReport) GoString() string {
+	if this == nil {
+		return "nil"
 	}
-	l = len(m.Hash)
-	if l > 0 {
-		n += 1 + l + sovMsg(uint64(l))
+	s := make([]string, 0, 9)
+	s = append(s, "&msgpb.PunchReport{")
+	s = append(s, "ID: "+fmt.Sprintf("%#v", this.ID)+",\n")
+	s = append(s, "ClientHash: "+fmt.Sprintf("%#v", this.ClientHash)+",\n")
+	s = append(s, "TunnelHash: "+fmt.Sprintf("%#v", this.TunnelHash)+",\n")
+	s = append(s, "Direct: "+fmt.Sprintf("%#v", this.Direct)+",\n")
+	s = append(s, "Reason: "+fmt.Sprintf("%#v", this.Reason)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *DeclaredTunnel) GoString() string {
+	if this == nil {
+		return "nil"
 	}
-	l = len(m.Version)
-	if l > 0 {
-		n += 1 + l + sovMsg(uint64(l))
+	s := make([]string, 0, 8)
+	s = append(s, "&msgpb.DeclaredTunnel{")
+	s = append(s, "Tag: "+fmt.Sprintf("%#v", this.Tag)+",\n")
+	s = append(s, "Proto: "+fmt.Sprintf("%#v", this.Proto)+",\n")
+	s = append(s, "ExportAddr: "+fmt.Sprintf("%#v", this.ExportAddr)+",\n")
+	s = append(s, "ServerAddr: "+fmt.Sprintf("%#v", this.ServerAddr)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *DeclareTunnelsRequest) GoString() string {
+	if this == nil {
+		return "nil"
 	}
-	l = len(m.Device)
-	if l > 0 {
-		n += 1 + l + sovMsg(uint64(l))
+	s := make([]string, 0, 7)
+	s = append(s, "&msgpb.DeclareTunnelsRequest{")
+	s = append(s, "ID: "+fmt.Sprintf("%#v", this.ID)+",\n")
+	s = append(s, "ClientHash: "+fmt.Sprintf("%#v", this.ClientHash)+",\n")
+	if this.Tunnels != nil {
+		s = append(s, "Tunnels: "+fmt.Sprintf("%#v", this.Tunnels)+",\n")
 	}
-	return n
+	s = append(s, "}")
+	return strings.Join(s, "")
 }
-
-func (m *HandshakeResponse) Size() (n int) {
-	var l int
-	_ = l
-	if m.ErrCode != 0 {
-		n += 1 + sovMsg(uint64(m.ErrCode))
+func (this *Message) GoString() string {
+	if this == nil {
+		return "nil"
 	}
-	return n
+	s := make([]string, 0, 21)
+	s = append(s, "&msgpb.Message{")
+	if this.Body != nil {
+		s = append(s, "Body: "+fmt.Sprintf("%#v", this.Body)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
 }
-
-func (m *PingRequest) Size() (n int) {
-	var l int
-	_ = l
-	return n
+func (this *Message_HandshakeRequest) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&msgpb.Message_HandshakeRequest{` +
+		`HandshakeRequest:` + fmt.Sprintf("%#v", this.HandshakeRequest) + `}`}, ", ")
+	return s
 }
-
-func (m *PingResponse) Size() (n int) {
-	var l int
-	_ = l
-	return n
+func (this *Message_HandshakeResponse) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&msgpb.Message_HandshakeResponse{` +
+		`HandshakeResponse:` + fmt.Sprintf("%#v", this.HandshakeResponse) + `}`}, ", ")
+	return s
 }
-
-func (m *TunnelHandshakeRequest) Size() (n int) {
-	var l int
-	_ = l
-	l = len(m.ID)
-	if l > 0 {
-		n += 1 + l + sovMsg(uint64(l))
+func (this *Message_TunnelHandshakeRequest) GoString() string {
+	if this == nil {
+		return "nil"
 	}
-	l = len(m.ClientHash)
-	if l > 0 {
-		n += 1 + l + sovMsg(uint64(l))
+	s := strings.Join([]string{`&msgpb.Message_TunnelHandshakeRequest{` +
+		`TunnelHandshakeRequest:` + fmt.Sprintf("%#v", this.TunnelHandshakeRequest) + `}`}, ", ")
+	return s
+}
+func (this *Message_TunnelHandshakeResponse) GoString() string {
+	if this == nil {
+		return "nil"
 	}
-	l = len(m.TunnelHash)
-	if l > 0 {
-		n += 1 + l + sovMsg(uint64(l))
+	s := strings.Join([]string{`&msgpb.Message_TunnelHandshakeResponse{` +
+		`TunnelHandshakeResponse:` + fmt.Sprintf("%#v", this.TunnelHandshakeResponse) + `}`}, ", ")
+	return s
+}
+func (this *Message_PingRequest) GoString() string {
+	if this == nil {
+		return "nil"
 	}
-	return n
+	s := strings.Join([]string{`&msgpb.Message_PingRequest{` +
+		`PingRequest:` + fmt.Sprintf("%#v", this.PingRequest) + `}`}, ", ")
+	return s
 }
-
-func (m *TunnelHandshakeResponse) Size() (n int) {
-	var l int
-	_ = l
-	if m.ErrCode != 0 {
-		n += 1 + sovMsg(uint64(m.ErrCode))
+func (this *Message_PingResponse) GoString() string {
+	if this == nil {
+		return "nil"
 	}
-	return n
+	s := strings.Join([]string{`&msgpb.Message_PingResponse{` +
+		`PingResponse:` + fmt.Sprintf("%#v", this.PingResponse) + `}`}, ", ")
+	return s
 }
-
-func (m *NewTunnelRequest) Size() (n int) {
-	var l int
-	_ = l
-	l = len(m.ID)
-	if l > 0 {
-		n += 1 + l + sovMsg(uint64(l))
+func (this *Message_NewTunnelRequest) GoString() string {
+	if this == nil {
+		return "nil"
 	}
-	l = len(m.ClientHash)
-	if l > 0 {
-		n += 1 + l + sovMsg(uint64(l))
+	s := strings.Join([]string{`&msgpb.Message_NewTunnelRequest{` +
+		`NewTunnelRequest:` + fmt.Sprintf("%#v", this.NewTunnelRequest) + `}`}, ", ")
+	return s
+}
+func (this *Message_NewTunnelResponse) GoString() string {
+	if this == nil {
+		return "nil"
 	}
-	l = len(m.TunnelHash)
-	if l > 0 {
-		n += 1 + l + sovMsg(uint64(l))
+	s := strings.Join([]string{`&msgpb.Message_NewTunnelResponse{` +
+		`NewTunnelResponse:` + fmt.Sprintf("%#v", this.NewTunnelResponse) + `}`}, ", ")
+	return s
+}
+func (this *Message_CloseTunnelRequest) GoString() string {
+	if this == nil {
+		return "nil"
 	}
-	l = len(m.Proto)
-	if l > 0 {
-		n += 1 + l + sovMsg(uint64(l))
+	s := strings.Join([]string{`&msgpb.Message_CloseTunnelRequest{` +
+		`CloseTunnelRequest:` + fmt.Sprintf("%#v", this.CloseTunnelRequest) + `}`}, ", ")
+	return s
+}
+func (this *Message_CloseTunnelResponse) GoString() string {
+	if this == nil {
+		return "nil"
 	}
-	l = len(m.ExportAddr)
-	if l > 0 {
-		n += 1 + l + sovMsg(uint64(l))
+	s := strings.Join([]string{`&msgpb.Message_CloseTunnelResponse{` +
+		`CloseTunnelResponse:` + fmt.Sprintf("%#v", this.CloseTunnelResponse) + `}`}, ", ")
+	return s
+}
+func (this *Message_ShutdownRequest) GoString() string {
+	if this == nil {
+		return "nil"
 	}
-	l = len(m.RegistryAddr)
-	if l > 0 {
-		n += 1 + l + sovMsg(uint64(l))
+	s := strings.Join([]string{`&msgpb.Message_ShutdownRequest{` +
+		`ShutdownRequest:` + fmt.Sprintf("%#v", this.ShutdownRequest) + `}`}, ", ")
+	return s
+}
+func (this *Message_HealthReport) GoString() string {
+	if this == nil {
+		return "nil"
 	}
-	return n
+	s := strings.Join([]string{`&msgpb.Message_HealthReport{` +
+		`HealthReport:` + fmt.Sprintf("%#v", this.HealthReport) + `}`}, ", ")
+	return s
 }
-
-func (m *NewTunnelResponse) Size() (n int) {
-	var l int
-	_ = l
-	l = len(m.TunnelHash)
-	if l > 0 {
-		n += 1 + l + sovMsg(uint64(l))
+func (this *Message_DialErrorReport) GoString() string {
+	if this == nil {
+		return "nil"
 	}
-	if m.ErrCode != 0 {
-		n += 1 + sovMsg(uint64(m.ErrCode))
+	s := strings.Join([]string{`&msgpb.Message_DialErrorReport{` +
+		`DialErrorReport:` + fmt.Sprintf("%#v", this.DialErrorReport) + `}`}, ", ")
+	return s
+}
+func (this *Message_PunchRequest) GoString() string {
+	if this == nil {
+		return "nil"
 	}
-	return n
+	s := strings.Join([]string{`&msgpb.Message_PunchRequest{` +
+		`PunchRequest:` + fmt.Sprintf("%#v", this.PunchRequest) + `}`}, ", ")
+	return s
 }
-
-func (m *CloseTunnelRequest) Size() (n int) {
-	var l int
-	_ = l
-	l = len(m.ID)
-	if l > 0 {
-		n += 1 + l + sovMsg(uint64(l))
+func (this *Message_PunchReport) GoString() string {
+	if this == nil {
+		return "nil"
 	}
-	l = len(m.ClientHash)
-	if l > 0 {
-		n += 1 + l + sovMsg(uint64(l))
+	s := strings.Join([]string{`&msgpb.Message_PunchReport{` +
+		`PunchReport:` + fmt.Sprintf("%#v", this.PunchReport) + `}`}, ", ")
+	return s
+}
+func (this *Message_DeclareTunnelsRequest) GoString() string {
+	if this == nil {
+		return "nil"
 	}
-	l = len(m.TunnelHash)
-	if l > 0 {
-		n += 1 + l + sovMsg(uint64(l))
+	s := strings.Join([]string{`&msgpb.Message_DeclareTunnelsRequest{` +
+		`DeclareTunnelsRequest:` + fmt.Sprintf("%#v", this.DeclareTunnelsRequest) + `}`}, ", ")
+	return s
+}
+func (this *Message_DeclareTunnelsResponse) GoString() string {
+	if this == nil {
+		return "nil"
 	}
-	return n
+	s := strings.Join([]string{`&msgpb.Message_DeclareTunnelsResponse{` +
+		`DeclareTunnelsResponse:` + fmt.Sprintf("%#v", this.DeclareTunnelsResponse) + `}`}, ", ")
+	return s
 }
-
-func (m *CloseTunnelResponse) Size() (n int) {
-	var l int
-	_ = l
-	l = len(m.TunnelHash)
-	if l > 0 {
-		n += 1 + l + sovMsg(uint64(l))
+func valueToGoStringMsg(v interface{}, typ string) string {
+	rv := reflect.ValueOf(v)
+	if rv.IsNil() {
+		return "nil"
 	}
-	if m.ErrCode != 0 {
-		n += 1 + sovMsg(uint64(m.ErrCode))
+	pv := reflect.Indirect(rv).Interface()
+	return fmt.Sprintf("func(v %v) *%v { return &v } ( %#v )", typ, typ, pv)
+}
+func (m *HandshakeRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
-	return n
+	return dAtA[:n], nil
 }
 
-func (m *ShutdownRequest) Size() (n int) {
+func (m *HandshakeRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
 	var l int
 	_ = l
-	l = len(m.ID)
-	if l > 0 {
-		n += 1 + l + sovMsg(uint64(l))
+	if len(m.ID) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.ID)))
+		i += copy(dAtA[i:], m.ID)
 	}
-	l = len(m.ClientHash)
-	if l > 0 {
-		n += 1 + l + sovMsg(uint64(l))
+	if len(m.Hash) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.Hash)))
+		i += copy(dAtA[i:], m.Hash)
 	}
-	return n
+	if len(m.Version) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.Version)))
+		i += copy(dAtA[i:], m.Version)
+	}
+	if len(m.Device) > 0 {
+		dAtA[i] = 0x22
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.Device)))
+		i += copy(dAtA[i:], m.Device)
+	}
+	return i, nil
 }
 
-func (m *Message) Size() (n int) {
-	var l int
-	_ = l
-	if m.Body != nil {
-		n += m.Body.Size()
+func (m *HandshakeResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
-	return n
+	return dAtA[:n], nil
 }
 
-func (m *Message_HandshakeRequest) Size() (n int) {
+func (m *HandshakeResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
 	var l int
 	_ = l
-	if m.HandshakeRequest != nil {
-		l = m.HandshakeRequest.Size()
-		n += 1 + l + sovMsg(uint64(l))
+	if m.ErrCode != 0 {
+		dAtA[i] = 0x8
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(m.ErrCode))
 	}
-	return n
+	if len(m.RegistryAddr) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.RegistryAddr)))
+		i += copy(dAtA[i:], m.RegistryAddr)
+	}
+	return i, nil
 }
-func (m *Message_HandshakeResponse) Size() (n int) {
-	var l int
-	_ = l
-	if m.HandshakeResponse != nil {
-		l = m.HandshakeResponse.Size()
-		n += 1 + l + sovMsg(uint64(l))
+
+func (m *PingRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
-	return n
+	return dAtA[:n], nil
 }
-func (m *Message_TunnelHandshakeRequest) Size() (n int) {
-	var l int
-	_ = l
-	if m.TunnelHandshakeRequest != nil {
-		l = m.TunnelHandshakeRequest.Size()
-		n += 1 + l + sovMsg(uint64(l))
-	}
-	return n
-}
-func (m *Message_TunnelHandshakeResponse) Size() (n int) {
+
+func (m *PingRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
 	var l int
 	_ = l
-	if m.TunnelHandshakeResponse != nil {
-		l = m.TunnelHandshakeResponse.Size()
-		n += 1 + l + sovMsg(uint64(l))
-	}
-	return n
+	return i, nil
 }
-func (m *Message_PingRequest) Size() (n int) {
-	var l int
-	_ = l
-	if m.PingRequest != nil {
-		l = m.PingRequest.Size()
-		n += 1 + l + sovMsg(uint64(l))
+
+func (m *PingResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
-	return n
+	return dAtA[:n], nil
 }
-func (m *Message_PingResponse) Size() (n int) {
+
+func (m *PingResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
 	var l int
 	_ = l
-	if m.PingResponse != nil {
-		l = m.PingResponse.Size()
-		n += 1 + l + sovMsg(uint64(l))
+	return i, nil
+}
+
+func (m *TunnelHandshakeRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
-	return n
+	return dAtA[:n], nil
 }
-func (m *Message_NewTunnelRequest) Size() (n int) {
+
+func (m *TunnelHandshakeRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
 	var l int
 	_ = l
-	if m.NewTunnelRequest != nil {
-		l = m.NewTunnelRequest.Size()
-		n += 1 + l + sovMsg(uint64(l))
+	if len(m.ID) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.ID)))
+		i += copy(dAtA[i:], m.ID)
 	}
-	return n
+	if len(m.ClientHash) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.ClientHash)))
+		i += copy(dAtA[i:], m.ClientHash)
+	}
+	if len(m.TunnelHash) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.TunnelHash)))
+		i += copy(dAtA[i:], m.TunnelHash)
+	}
+	if m.Visitor {
+		dAtA[i] = 0x20
+		i++
+		if m.Visitor {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if len(m.SecretKey) > 0 {
+		dAtA[i] = 0x2a
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.SecretKey)))
+		i += copy(dAtA[i:], m.SecretKey)
+	}
+	if m.Punch {
+		dAtA[i] = 0x30
+		i++
+		if m.Punch {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if len(m.Nonce) > 0 {
+		dAtA[i] = 0x3a
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.Nonce)))
+		i += copy(dAtA[i:], m.Nonce)
+	}
+	return i, nil
 }
-func (m *Message_NewTunnelResponse) Size() (n int) {
-	var l int
-	_ = l
-	if m.NewTunnelResponse != nil {
-		l = m.NewTunnelResponse.Size()
-		n += 1 + l + sovMsg(uint64(l))
+
+func (m *TunnelHandshakeResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
-	return n
+	return dAtA[:n], nil
 }
-func (m *Message_CloseTunnelRequest) Size() (n int) {
+
+func (m *TunnelHandshakeResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
 	var l int
 	_ = l
-	if m.CloseTunnelRequest != nil {
-		l = m.CloseTunnelRequest.Size()
-		n += 1 + l + sovMsg(uint64(l))
+	if m.ErrCode != 0 {
+		dAtA[i] = 0x8
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(m.ErrCode))
 	}
-	return n
+	if len(m.PeerAddr) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.PeerAddr)))
+		i += copy(dAtA[i:], m.PeerAddr)
+	}
+	if len(m.Nonce) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.Nonce)))
+		i += copy(dAtA[i:], m.Nonce)
+	}
+	return i, nil
 }
-func (m *Message_CloseTunnelResponse) Size() (n int) {
-	var l int
-	_ = l
-	if m.CloseTunnelResponse != nil {
-		l = m.CloseTunnelResponse.Size()
-		n += 1 + l + sovMsg(uint64(l))
+
+func (m *NewTunnelRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
-	return n
+	return dAtA[:n], nil
 }
-func (m *Message_ShutdownRequest) Size() (n int) {
+
+func (m *NewTunnelRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
 	var l int
 	_ = l
-	if m.ShutdownRequest != nil {
-		l = m.ShutdownRequest.Size()
-		n += 1 + l + sovMsg(uint64(l))
+	if len(m.ID) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.ID)))
+		i += copy(dAtA[i:], m.ID)
 	}
-	return n
-}
-
-func sovMsg(x uint64) (n int) {
-	for {
-		n++
-		x >>= 7
-		if x == 0 {
-			break
-		}
+	if len(m.ClientHash) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.ClientHash)))
+		i += copy(dAtA[i:], m.ClientHash)
 	}
-	return n
-}
-func sozMsg(x uint64) (n int) {
-	return sovMsg(uint64((x << 1) ^ uint64((int64(x) >> 63))))
-}
-func (this *HandshakeRequest) String() string {
-	if this == nil {
-		return "nil"
+	if len(m.TunnelHash) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.TunnelHash)))
+		i += copy(dAtA[i:], m.TunnelHash)
 	}
-	s := strings.Join([]string{`&HandshakeRequest{`,
-		`ID:` + fmt.Sprintf("%v", this.ID) + `,`,
-		`Hash:` + fmt.Sprintf("%v", this.Hash) + `,`,
-		`Version:` + fmt.Sprintf("%v", this.Version) + `,`,
-		`Device:` + fmt.Sprintf("%v", this.Device) + `,`,
-		`}`,
-	}, "")
-	return s
-}
-func (this *HandshakeResponse) String() string {
-	if this == nil {
-		return "nil"
+	if len(m.Proto) > 0 {
+		dAtA[i] = 0x22
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.Proto)))
+		i += copy(dAtA[i:], m.Proto)
 	}
-	s := strings.Join([]string{`&HandshakeResponse{`,
-		`ErrCode:` + fmt.Sprintf("%v", this.ErrCode) + `,`,
-		`}`,
-	}, "")
-	return s
-}
-func (this *PingRequest) String() string {
-	if this == nil {
-		return "nil"
+	if len(m.ExportAddr) > 0 {
+		dAtA[i] = 0x2a
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.ExportAddr)))
+		i += copy(dAtA[i:], m.ExportAddr)
 	}
-	s := strings.Join([]string{`&PingRequest{`,
-		`}`,
-	}, "")
-	return s
-}
-func (this *PingResponse) String() string {
-	if this == nil {
-		return "nil"
+	if len(m.RegistryAddr) > 0 {
+		dAtA[i] = 0x32
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.RegistryAddr)))
+		i += copy(dAtA[i:], m.RegistryAddr)
 	}
-	s := strings.Join([]string{`&PingResponse{`,
-		`}`,
-	}, "")
-	return s
-}
-func (this *TunnelHandshakeRequest) String() string {
-	if this == nil {
-		return "nil"
+	if len(m.HealthCheckPath) > 0 {
+		dAtA[i] = 0x3a
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.HealthCheckPath)))
+		i += copy(dAtA[i:], m.HealthCheckPath)
 	}
-	s := strings.Join([]string{`&TunnelHandshakeRequest{`,
-		`ID:` + fmt.Sprintf("%v", this.ID) + `,`,
-		`ClientHash:` + fmt.Sprintf("%v", this.ClientHash) + `,`,
-		`TunnelHash:` + fmt.Sprintf("%v", this.TunnelHash) + `,`,
-		`}`,
-	}, "")
-	return s
-}
-func (this *TunnelHandshakeResponse) String() string {
-	if this == nil {
-		return "nil"
+	if len(m.AllowCIDRs) > 0 {
+		dAtA[i] = 0x42
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.AllowCIDRs)))
+		i += copy(dAtA[i:], m.AllowCIDRs)
 	}
-	s := strings.Join([]string{`&TunnelHandshakeResponse{`,
-		`ErrCode:` + fmt.Sprintf("%v", this.ErrCode) + `,`,
-		`}`,
-	}, "")
-	return s
-}
-func (this *NewTunnelRequest) String() string {
-	if this == nil {
-		return "nil"
+	if len(m.AllowPorts) > 0 {
+		dAtA[i] = 0x4a
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.AllowPorts)))
+		i += copy(dAtA[i:], m.AllowPorts)
 	}
-	s := strings.Join([]string{`&NewTunnelRequest{`,
-		`ID:` + fmt.Sprintf("%v", this.ID) + `,`,
-		`ClientHash:` + fmt.Sprintf("%v", this.ClientHash) + `,`,
-		`TunnelHash:` + fmt.Sprintf("%v", this.TunnelHash) + `,`,
-		`Proto:` + fmt.Sprintf("%v", this.Proto) + `,`,
-		`ExportAddr:` + fmt.Sprintf("%v", this.ExportAddr) + `,`,
-		`RegistryAddr:` + fmt.Sprintf("%v", this.RegistryAddr) + `,`,
-		`}`,
-	}, "")
-	return s
-}
-func (this *NewTunnelResponse) String() string {
-	if this == nil {
-		return "nil"
+	if len(m.ProxyUser) > 0 {
+		dAtA[i] = 0x52
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.ProxyUser)))
+		i += copy(dAtA[i:], m.ProxyUser)
 	}
-	s := strings.Join([]string{`&NewTunnelResponse{`,
-		`TunnelHash:` + fmt.Sprintf("%v", this.TunnelHash) + `,`,
-		`ErrCode:` + fmt.Sprintf("%v", this.ErrCode) + `,`,
-		`}`,
-	}, "")
-	return s
-}
-func (this *CloseTunnelRequest) String() string {
-	if this == nil {
-		return "nil"
+	if len(m.ProxyPassword) > 0 {
+		dAtA[i] = 0x5a
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.ProxyPassword)))
+		i += copy(dAtA[i:], m.ProxyPassword)
 	}
-	s := strings.Join([]string{`&CloseTunnelRequest{`,
-		`ID:` + fmt.Sprintf("%v", this.ID) + `,`,
-		`ClientHash:` + fmt.Sprintf("%v", this.ClientHash) + `,`,
-		`TunnelHash:` + fmt.Sprintf("%v", this.TunnelHash) + `,`,
-		`}`,
-	}, "")
-	return s
-}
-func (this *CloseTunnelResponse) String() string {
-	if this == nil {
-		return "nil"
+	if m.LocalTLS {
+		dAtA[i] = 0x60
+		i++
+		if m.LocalTLS {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
 	}
-	s := strings.Join([]string{`&CloseTunnelResponse{`,
-		`TunnelHash:` + fmt.Sprintf("%v", this.TunnelHash) + `,`,
-		`ErrCode:` + fmt.Sprintf("%v", this.ErrCode) + `,`,
-		`}`,
-	}, "")
-	return s
-}
-func (this *ShutdownRequest) String() string {
-	if this == nil {
-		return "nil"
+	if len(m.TLSServerName) > 0 {
+		dAtA[i] = 0x6a
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.TLSServerName)))
+		i += copy(dAtA[i:], m.TLSServerName)
 	}
-	s := strings.Join([]string{`&ShutdownRequest{`,
-		`ID:` + fmt.Sprintf("%v", this.ID) + `,`,
-		`ClientHash:` + fmt.Sprintf("%v", this.ClientHash) + `,`,
-		`}`,
-	}, "")
-	return s
-}
-func (this *Message) String() string {
-	if this == nil {
-		return "nil"
+	if m.TLSSkipVerify {
+		dAtA[i] = 0x70
+		i++
+		if m.TLSSkipVerify {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
 	}
-	s := strings.Join([]string{`&Message{`,
-		`Body:` + fmt.Sprintf("%v", this.Body) + `,`,
-		`}`,
-	}, "")
-	return s
-}
-func (this *Message_HandshakeRequest) String() string {
-	if this == nil {
-		return "nil"
+	if len(m.TLSCA) > 0 {
+		dAtA[i] = 0x7a
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.TLSCA)))
+		i += copy(dAtA[i:], m.TLSCA)
 	}
-	s := strings.Join([]string{`&Message_HandshakeRequest{`,
-		`HandshakeRequest:` + strings.Replace(fmt.Sprintf("%v", this.HandshakeRequest), "HandshakeRequest", "HandshakeRequest", 1) + `,`,
-		`}`,
-	}, "")
-	return s
+	if m.FileListing {
+		dAtA[i] = 0x80
+		i++
+		dAtA[i] = 0x1
+		i++
+		if m.FileListing {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if len(m.BasicAuthUser) > 0 {
+		dAtA[i] = 0x8a
+		i++
+		dAtA[i] = 0x1
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.BasicAuthUser)))
+		i += copy(dAtA[i:], m.BasicAuthUser)
+	}
+	if len(m.BasicAuthPassword) > 0 {
+		dAtA[i] = 0x92
+		i++
+		dAtA[i] = 0x1
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.BasicAuthPassword)))
+		i += copy(dAtA[i:], m.BasicAuthPassword)
+	}
+	return i, nil
 }
-func (this *Message_HandshakeResponse) String() string {
-	if this == nil {
-		return "nil"
+
+func (m *NewTunnelResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
-	s := strings.Join([]string{`&Message_HandshakeResponse{`,
-		`HandshakeResponse:` + strings.Replace(fmt.Sprintf("%v", this.HandshakeResponse), "HandshakeResponse", "HandshakeResponse", 1) + `,`,
-		`}`,
-	}, "")
-	return s
+	return dAtA[:n], nil
 }
-func (this *Message_TunnelHandshakeRequest) String() string {
-	if this == nil {
-		return "nil"
+
+func (m *NewTunnelResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.TunnelHash) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.TunnelHash)))
+		i += copy(dAtA[i:], m.TunnelHash)
 	}
-	s := strings.Join([]string{`&Message_TunnelHandshakeRequest{`,
-		`TunnelHandshakeRequest:` + strings.Replace(fmt.Sprintf("%v", this.TunnelHandshakeRequest), "TunnelHandshakeRequest", "TunnelHandshakeRequest", 1) + `,`,
-		`}`,
-	}, "")
-	return s
+	if m.ErrCode != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(m.ErrCode))
+	}
+	return i, nil
 }
-func (this *Message_TunnelHandshakeResponse) String() string {
-	if this == nil {
-		return "nil"
+
+func (m *CloseTunnelRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
-	s := strings.Join([]string{`&Message_TunnelHandshakeResponse{`,
-		`TunnelHandshakeResponse:` + strings.Replace(fmt.Sprintf("%v", this.TunnelHandshakeResponse), "TunnelHandshakeResponse", "TunnelHandshakeResponse", 1) + `,`,
-		`}`,
-	}, "")
-	return s
+	return dAtA[:n], nil
 }
-func (this *Message_PingRequest) String() string {
-	if this == nil {
-		return "nil"
+
+func (m *CloseTunnelRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.ID) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.ID)))
+		i += copy(dAtA[i:], m.ID)
 	}
-	s := strings.Join([]string{`&Message_PingRequest{`,
-		`PingRequest:` + strings.Replace(fmt.Sprintf("%v", this.PingRequest), "PingRequest", "PingRequest", 1) + `,`,
-		`}`,
-	}, "")
-	return s
+	if len(m.ClientHash) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.ClientHash)))
+		i += copy(dAtA[i:], m.ClientHash)
+	}
+	if len(m.TunnelHash) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.TunnelHash)))
+		i += copy(dAtA[i:], m.TunnelHash)
+	}
+	return i, nil
 }
-func (this *Message_PingResponse) String() string {
-	if this == nil {
-		return "nil"
+
+func (m *CloseTunnelResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
-	s := strings.Join([]string{`&Message_PingResponse{`,
-		`PingResponse:` + strings.Replace(fmt.Sprintf("%v", this.PingResponse), "PingResponse", "PingResponse", 1) + `,`,
-		`}`,
-	}, "")
-	return s
+	return dAtA[:n], nil
 }
-func (this *Message_NewTunnelRequest) String() string {
-	if this == nil {
-		return "nil"
+
+func (m *CloseTunnelResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.TunnelHash) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.TunnelHash)))
+		i += copy(dAtA[i:], m.TunnelHash)
 	}
-	s := strings.Join([]string{`&Message_NewTunnelRequest{`,
-		`NewTunnelRequest:` + strings.Replace(fmt.Sprintf("%v", this.NewTunnelRequest), "NewTunnelRequest", "NewTunnelRequest", 1) + `,`,
-		`}`,
-	}, "")
-	return s
+	if m.ErrCode != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(m.ErrCode))
+	}
+	return i, nil
 }
-func (this *Message_NewTunnelResponse) String() string {
-	if this == nil {
-		return "nil"
+
+func (m *ShutdownRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
-	s := strings.Join([]string{`&Message_NewTunnelResponse{`,
-		`NewTunnelResponse:` + strings.Replace(fmt.Sprintf("%v", this.NewTunnelResponse), "NewTunnelResponse", "NewTunnelResponse", 1) + `,`,
-		`}`,
-	}, "")
-	return s
+	return dAtA[:n], nil
 }
-func (this *Message_CloseTunnelRequest) String() string {
-	if this == nil {
-		return "nil"
+
+func (m *ShutdownRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.ID) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.ID)))
+		i += copy(dAtA[i:], m.ID)
+	}
+	if len(m.ClientHash) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.ClientHash)))
+		i += copy(dAtA[i:], m.ClientHash)
+	}
+	if m.Reconnect {
+		dAtA[i] = 0x18
+		i++
+		if m.Reconnect {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if len(m.ReconnectAddr) > 0 {
+		dAtA[i] = 0x22
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.ReconnectAddr)))
+		i += copy(dAtA[i:], m.ReconnectAddr)
+	}
+	if m.ReconnectDelay != 0 {
+		dAtA[i] = 0x28
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(m.ReconnectDelay))
+	}
+	if m.Restart {
+		dAtA[i] = 0x30
+		i++
+		if m.Restart {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	return i, nil
+}
+
+func (m *PunchRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *PunchRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.ID) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.ID)))
+		i += copy(dAtA[i:], m.ID)
+	}
+	if len(m.ClientHash) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.ClientHash)))
+		i += copy(dAtA[i:], m.ClientHash)
+	}
+	if len(m.TunnelHash) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.TunnelHash)))
+		i += copy(dAtA[i:], m.TunnelHash)
+	}
+	if len(m.PeerAddr) > 0 {
+		dAtA[i] = 0x22
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.PeerAddr)))
+		i += copy(dAtA[i:], m.PeerAddr)
+	}
+	if len(m.Nonce) > 0 {
+		dAtA[i] = 0x2a
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.Nonce)))
+		i += copy(dAtA[i:], m.Nonce)
+	}
+	return i, nil
+}
+
+func (m *DeclaredTunnelResult) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *DeclaredTunnelResult) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Tag) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.Tag)))
+		i += copy(dAtA[i:], m.Tag)
+	}
+	if m.Accepted {
+		dAtA[i] = 0x10
+		i++
+		if m.Accepted {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if len(m.TunnelHash) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.TunnelHash)))
+		i += copy(dAtA[i:], m.TunnelHash)
+	}
+	if len(m.ServerAddr) > 0 {
+		dAtA[i] = 0x22
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.ServerAddr)))
+		i += copy(dAtA[i:], m.ServerAddr)
+	}
+	if len(m.Reason) > 0 {
+		dAtA[i] = 0x2a
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.Reason)))
+		i += copy(dAtA[i:], m.Reason)
+	}
+	return i, nil
+}
+
+func (m *DeclareTunnelsResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *DeclareTunnelsResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Results) > 0 {
+		for _, msg := range m.Results {
+			dAtA[i] = 0xa
+			i++
+			i = encodeVarintMsg(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	return i, nil
+}
+
+func (m *HealthReport) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *HealthReport) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.ID) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.ID)))
+		i += copy(dAtA[i:], m.ID)
+	}
+	if len(m.ClientHash) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.ClientHash)))
+		i += copy(dAtA[i:], m.ClientHash)
+	}
+	if len(m.TunnelHash) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.TunnelHash)))
+		i += copy(dAtA[i:], m.TunnelHash)
+	}
+	if m.Healthy {
+		dAtA[i] = 0x20
+		i++
+		if m.Healthy {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if len(m.Reason) > 0 {
+		dAtA[i] = 0x2a
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.Reason)))
+		i += copy(dAtA[i:], m.Reason)
+	}
+	return i, nil
+}
+
+func (m *DialErrorReport) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *DialErrorReport) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.ID) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.ID)))
+		i += copy(dAtA[i:], m.ID)
+	}
+	if len(m.ClientHash) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.ClientHash)))
+		i += copy(dAtA[i:], m.ClientHash)
+	}
+	if len(m.TunnelHash) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.TunnelHash)))
+		i += copy(dAtA[i:], m.TunnelHash)
+	}
+	if m.Class != 0 {
+		dAtA[i] = 0x20
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(m.Class))
+	}
+	if len(m.Reason) > 0 {
+		dAtA[i] = 0x2a
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.Reason)))
+		i += copy(dAtA[i:], m.Reason)
+	}
+	if m.Count != 0 {
+		dAtA[i] = 0x30
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(m.Count))
+	}
+	return i, nil
+}
+
+func (m *PunchReport) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *PunchReport) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.ID) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.ID)))
+		i += copy(dAtA[i:], m.ID)
+	}
+	if len(m.ClientHash) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.ClientHash)))
+		i += copy(dAtA[i:], m.ClientHash)
+	}
+	if len(m.TunnelHash) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.TunnelHash)))
+		i += copy(dAtA[i:], m.TunnelHash)
+	}
+	if m.Direct {
+		dAtA[i] = 0x20
+		i++
+		if m.Direct {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if len(m.Reason) > 0 {
+		dAtA[i] = 0x2a
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.Reason)))
+		i += copy(dAtA[i:], m.Reason)
+	}
+	return i, nil
+}
+
+func (m *DeclaredTunnel) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *DeclaredTunnel) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Tag) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.Tag)))
+		i += copy(dAtA[i:], m.Tag)
+	}
+	if len(m.Proto) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.Proto)))
+		i += copy(dAtA[i:], m.Proto)
+	}
+	if len(m.ExportAddr) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.ExportAddr)))
+		i += copy(dAtA[i:], m.ExportAddr)
+	}
+	if len(m.ServerAddr) > 0 {
+		dAtA[i] = 0x22
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.ServerAddr)))
+		i += copy(dAtA[i:], m.ServerAddr)
+	}
+	return i, nil
+}
+
+func (m *DeclareTunnelsRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *DeclareTunnelsRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.ID) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.ID)))
+		i += copy(dAtA[i:], m.ID)
+	}
+	if len(m.ClientHash) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.ClientHash)))
+		i += copy(dAtA[i:], m.ClientHash)
+	}
+	if len(m.Tunnels) > 0 {
+		for _, msg := range m.Tunnels {
+			dAtA[i] = 0x1a
+			i++
+			i = encodeVarintMsg(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	return i, nil
+}
+
+func (m *Message) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Message) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Body != nil {
+		nn1, err := m.Body.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += nn1
+	}
+	return i, nil
+}
+
+func (m *Message_HandshakeRequest) MarshalTo(dAtA []byte) (int, error) {
+	i := 0
+	if m.HandshakeRequest != nil {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(m.HandshakeRequest.Size()))
+		n2, err := m.HandshakeRequest.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n2
+	}
+	return i, nil
+}
+func (m *Message_HandshakeResponse) MarshalTo(dAtA []byte) (int, error) {
+	i := 0
+	if m.HandshakeResponse != nil {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(m.HandshakeResponse.Size()))
+		n3, err := m.HandshakeResponse.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n3
+	}
+	return i, nil
+}
+func (m *Message_TunnelHandshakeRequest) MarshalTo(dAtA []byte) (int, error) {
+	i := 0
+	if m.TunnelHandshakeRequest != nil {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(m.TunnelHandshakeRequest.Size()))
+		n4, err := m.TunnelHandshakeRequest.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n4
+	}
+	return i, nil
+}
+func (m *Message_TunnelHandshakeResponse) MarshalTo(dAtA []byte) (int, error) {
+	i := 0
+	if m.TunnelHandshakeResponse != nil {
+		dAtA[i] = 0x22
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(m.TunnelHandshakeResponse.Size()))
+		n5, err := m.TunnelHandshakeResponse.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n5
+	}
+	return i, nil
+}
+func (m *Message_PingRequest) MarshalTo(dAtA []byte) (int, error) {
+	i := 0
+	if m.PingRequest != nil {
+		dAtA[i] = 0x2a
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(m.PingRequest.Size()))
+		n6, err := m.PingRequest.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n6
+	}
+	return i, nil
+}
+func (m *Message_PingResponse) MarshalTo(dAtA []byte) (int, error) {
+	i := 0
+	if m.PingResponse != nil {
+		dAtA[i] = 0x32
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(m.PingResponse.Size()))
+		n7, err := m.PingResponse.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n7
+	}
+	return i, nil
+}
+func (m *Message_NewTunnelRequest) MarshalTo(dAtA []byte) (int, error) {
+	i := 0
+	if m.NewTunnelRequest != nil {
+		dAtA[i] = 0x3a
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(m.NewTunnelRequest.Size()))
+		n8, err := m.NewTunnelRequest.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n8
+	}
+	return i, nil
+}
+func (m *Message_NewTunnelResponse) MarshalTo(dAtA []byte) (int, error) {
+	i := 0
+	if m.NewTunnelResponse != nil {
+		dAtA[i] = 0x42
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(m.NewTunnelResponse.Size()))
+		n9, err := m.NewTunnelResponse.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n9
+	}
+	return i, nil
+}
+func (m *Message_CloseTunnelRequest) MarshalTo(dAtA []byte) (int, error) {
+	i := 0
+	if m.CloseTunnelRequest != nil {
+		dAtA[i] = 0x4a
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(m.CloseTunnelRequest.Size()))
+		n10, err := m.CloseTunnelRequest.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n10
+	}
+	return i, nil
+}
+func (m *Message_CloseTunnelResponse) MarshalTo(dAtA []byte) (int, error) {
+	i := 0
+	if m.CloseTunnelResponse != nil {
+		dAtA[i] = 0x52
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(m.CloseTunnelResponse.Size()))
+		n11, err := m.CloseTunnelResponse.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n11
+	}
+	return i, nil
+}
+func (m *Message_ShutdownRequest) MarshalTo(dAtA []byte) (int, error) {
+	i := 0
+	if m.ShutdownRequest != nil {
+		dAtA[i] = 0x5a
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(m.ShutdownRequest.Size()))
+		n12, err := m.ShutdownRequest.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n12
+	}
+	return i, nil
+}
+func (m *Message_HealthReport) MarshalTo(dAtA []byte) (int, error) {
+	i := 0
+	if m.HealthReport != nil {
+		dAtA[i] = 0x62
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(m.HealthReport.Size()))
+		n13, err := m.HealthReport.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n13
+	}
+	return i, nil
+}
+func (m *Message_DialErrorReport) MarshalTo(dAtA []byte) (int, error) {
+	i := 0
+	if m.DialErrorReport != nil {
+		dAtA[i] = 0x6a
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(m.DialErrorReport.Size()))
+		n14, err := m.DialErrorReport.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n14
+	}
+	return i, nil
+}
+func (m *Message_PunchRequest) MarshalTo(dAtA []byte) (int, error) {
+	i := 0
+	if m.PunchRequest != nil {
+		dAtA[i] = 0x72
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(m.PunchRequest.Size()))
+		n15, err := m.PunchRequest.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n15
+	}
+	return i, nil
+}
+func (m *Message_PunchReport) MarshalTo(dAtA []byte) (int, error) {
+	i := 0
+	if m.PunchReport != nil {
+		dAtA[i] = 0x7a
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(m.PunchReport.Size()))
+		n16, err := m.PunchReport.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n16
+	}
+	return i, nil
+}
+func (m *Message_DeclareTunnelsRequest) MarshalTo(dAtA []byte) (int, error) {
+	i := 0
+	if m.DeclareTunnelsRequest != nil {
+		dAtA[i] = 0x82
+		i++
+		dAtA[i] = 0x1
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(m.DeclareTunnelsRequest.Size()))
+		n17, err := m.DeclareTunnelsRequest.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n17
+	}
+	return i, nil
+}
+func (m *Message_DeclareTunnelsResponse) MarshalTo(dAtA []byte) (int, error) {
+	i := 0
+	if m.DeclareTunnelsResponse != nil {
+		dAtA[i] = 0x8a
+		i++
+		dAtA[i] = 0x1
+		i++
+		i = encodeVarintMsg(dAtA, i, uint64(m.DeclareTunnelsResponse.Size()))
+		n18, err := m.DeclareTunnelsResponse.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n18
+	}
+	return i, nil
+}
+func encodeFixed64Msg(dAtA []byte, offset int, v uint64) int {
+	dAtA[offset] = uint8(v)
+	dAtA[offset+1] = uint8(v >> 8)
+	dAtA[offset+2] = uint8(v >> 16)
+	dAtA[offset+3] = uint8(v >> 24)
+	dAtA[offset+4] = uint8(v >> 32)
+	dAtA[offset+5] = uint8(v >> 40)
+	dAtA[offset+6] = uint8(v >> 48)
+	dAtA[offset+7] = uint8(v >> 56)
+	return offset + 8
+}
+func encodeFixed32Msg(dAtA []byte, offset int, v uint32) int {
+	dAtA[offset] = uint8(v)
+	dAtA[offset+1] = uint8(v >> 8)
+	dAtA[offset+2] = uint8(v >> 16)
+	dAtA[offset+3] = uint8(v >> 24)
+	return offset + 4
+}
+func encodeVarintMsg(dAtA []byte, offset int, v uint64) int {
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return offset + 1
+}
+func (m *HandshakeRequest) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.ID)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	l = len(m.Hash)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	l = len(m.Version)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	l = len(m.Device)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	return n
+}
+
+func (m *HandshakeResponse) Size() (n int) {
+	var l int
+	_ = l
+	if m.ErrCode != 0 {
+		n += 1 + sovMsg(uint64(m.ErrCode))
+	}
+	l = len(m.RegistryAddr)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	return n
+}
+
+func (m *PingRequest) Size() (n int) {
+	var l int
+	_ = l
+	return n
+}
+
+func (m *PingResponse) Size() (n int) {
+	var l int
+	_ = l
+	return n
+}
+
+func (m *TunnelHandshakeRequest) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.ID)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	l = len(m.ClientHash)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	l = len(m.TunnelHash)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	if m.Visitor {
+		n += 2
+	}
+	l = len(m.SecretKey)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	if m.Punch {
+		n += 2
+	}
+	l = len(m.Nonce)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	return n
+}
+
+func (m *TunnelHandshakeResponse) Size() (n int) {
+	var l int
+	_ = l
+	if m.ErrCode != 0 {
+		n += 1 + sovMsg(uint64(m.ErrCode))
+	}
+	l = len(m.PeerAddr)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	l = len(m.Nonce)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	return n
+}
+
+func (m *NewTunnelRequest) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.ID)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	l = len(m.ClientHash)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	l = len(m.TunnelHash)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	l = len(m.Proto)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	l = len(m.ExportAddr)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	l = len(m.RegistryAddr)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	l = len(m.HealthCheckPath)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	l = len(m.AllowCIDRs)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	l = len(m.AllowPorts)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	l = len(m.ProxyUser)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	l = len(m.ProxyPassword)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	if m.LocalTLS {
+		n += 2
+	}
+	l = len(m.TLSServerName)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	if m.TLSSkipVerify {
+		n += 2
+	}
+	l = len(m.TLSCA)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	if m.FileListing {
+		n += 3
+	}
+	l = len(m.BasicAuthUser)
+	if l > 0 {
+		n += 2 + l + sovMsg(uint64(l))
+	}
+	l = len(m.BasicAuthPassword)
+	if l > 0 {
+		n += 2 + l + sovMsg(uint64(l))
+	}
+	return n
+}
+
+func (m *NewTunnelResponse) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.TunnelHash)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	if m.ErrCode != 0 {
+		n += 1 + sovMsg(uint64(m.ErrCode))
+	}
+	return n
+}
+
+func (m *CloseTunnelRequest) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.ID)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	l = len(m.ClientHash)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	l = len(m.TunnelHash)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	return n
+}
+
+func (m *CloseTunnelResponse) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.TunnelHash)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	if m.ErrCode != 0 {
+		n += 1 + sovMsg(uint64(m.ErrCode))
+	}
+	return n
+}
+
+func (m *ShutdownRequest) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.ID)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	l = len(m.ClientHash)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	if m.Reconnect {
+		n += 2
+	}
+	l = len(m.ReconnectAddr)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	if m.ReconnectDelay != 0 {
+		n += 1 + sovMsg(uint64(m.ReconnectDelay))
+	}
+	if m.Restart {
+		n += 2
+	}
+	return n
+}
+
+func (m *PunchRequest) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.ID)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	l = len(m.ClientHash)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	l = len(m.TunnelHash)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	l = len(m.PeerAddr)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	l = len(m.Nonce)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	return n
+}
+
+func (m *DeclaredTunnelResult) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Tag)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	if m.Accepted {
+		n += 2
+	}
+	l = len(m.TunnelHash)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	l = len(m.ServerAddr)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	l = len(m.Reason)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	return n
+}
+
+func (m *DeclareTunnelsResponse) Size() (n int) {
+	var l int
+	_ = l
+	if len(m.Results) > 0 {
+		for _, e := range m.Results {
+			l = e.Size()
+			n += 1 + l + sovMsg(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *HealthReport) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.ID)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	l = len(m.ClientHash)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	l = len(m.TunnelHash)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	if m.Healthy {
+		n += 2
+	}
+	l = len(m.Reason)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	return n
+}
+
+func (m *DialErrorReport) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.ID)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	l = len(m.ClientHash)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	l = len(m.TunnelHash)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	if m.Class != 0 {
+		n += 1 + sovMsg(uint64(m.Class))
+	}
+	l = len(m.Reason)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	if m.Count != 0 {
+		n += 1 + sovMsg(uint64(m.Count))
+	}
+	return n
+}
+
+func (m *PunchReport) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.ID)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	l = len(m.ClientHash)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	l = len(m.TunnelHash)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	if m.Direct {
+		n += 2
+	}
+	l = len(m.Reason)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	return n
+}
+
+func (m *DeclaredTunnel) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Tag)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	l = len(m.Proto)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	l = len(m.ExportAddr)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	l = len(m.ServerAddr)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	return n
+}
+
+func (m *DeclareTunnelsRequest) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.ID)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	l = len(m.ClientHash)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	if len(m.Tunnels) > 0 {
+		for _, e := range m.Tunnels {
+			l = e.Size()
+			n += 1 + l + sovMsg(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *Message) Size() (n int) {
+	var l int
+	_ = l
+	if m.Body != nil {
+		n += m.Body.Size()
+	}
+	return n
+}
+
+func (m *Message_HandshakeRequest) Size() (n int) {
+	var l int
+	_ = l
+	if m.HandshakeRequest != nil {
+		l = m.HandshakeRequest.Size()
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	return n
+}
+func (m *Message_HandshakeResponse) Size() (n int) {
+	var l int
+	_ = l
+	if m.HandshakeResponse != nil {
+		l = m.HandshakeResponse.Size()
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	return n
+}
+func (m *Message_TunnelHandshakeRequest) Size() (n int) {
+	var l int
+	_ = l
+	if m.TunnelHandshakeRequest != nil {
+		l = m.TunnelHandshakeRequest.Size()
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	return n
+}
+func (m *Message_TunnelHandshakeResponse) Size() (n int) {
+	var l int
+	_ = l
+	if m.TunnelHandshakeResponse != nil {
+		l = m.TunnelHandshakeResponse.Size()
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	return n
+}
+func (m *Message_PingRequest) Size() (n int) {
+	var l int
+	_ = l
+	if m.PingRequest != nil {
+		l = m.PingRequest.Size()
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	return n
+}
+func (m *Message_PingResponse) Size() (n int) {
+	var l int
+	_ = l
+	if m.PingResponse != nil {
+		l = m.PingResponse.Size()
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	return n
+}
+func (m *Message_NewTunnelRequest) Size() (n int) {
+	var l int
+	_ = l
+	if m.NewTunnelRequest != nil {
+		l = m.NewTunnelRequest.Size()
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	return n
+}
+func (m *Message_NewTunnelResponse) Size() (n int) {
+	var l int
+	_ = l
+	if m.NewTunnelResponse != nil {
+		l = m.NewTunnelResponse.Size()
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	return n
+}
+func (m *Message_CloseTunnelRequest) Size() (n int) {
+	var l int
+	_ = l
+	if m.CloseTunnelRequest != nil {
+		l = m.CloseTunnelRequest.Size()
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	return n
+}
+func (m *Message_CloseTunnelResponse) Size() (n int) {
+	var l int
+	_ = l
+	if m.CloseTunnelResponse != nil {
+		l = m.CloseTunnelResponse.Size()
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	return n
+}
+func (m *Message_ShutdownRequest) Size() (n int) {
+	var l int
+	_ = l
+	if m.ShutdownRequest != nil {
+		l = m.ShutdownRequest.Size()
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	return n
+}
+func (m *Message_HealthReport) Size() (n int) {
+	var l int
+	_ = l
+	if m.HealthReport != nil {
+		l = m.HealthReport.Size()
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	return n
+}
+func (m *Message_DialErrorReport) Size() (n int) {
+	var l int
+	_ = l
+	if m.DialErrorReport != nil {
+		l = m.DialErrorReport.Size()
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	return n
+}
+func (m *Message_PunchRequest) Size() (n int) {
+	var l int
+	_ = l
+	if m.PunchRequest != nil {
+		l = m.PunchRequest.Size()
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	return n
+}
+func (m *Message_PunchReport) Size() (n int) {
+	var l int
+	_ = l
+	if m.PunchReport != nil {
+		l = m.PunchReport.Size()
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	return n
+}
+func (m *Message_DeclareTunnelsRequest) Size() (n int) {
+	var l int
+	_ = l
+	if m.DeclareTunnelsRequest != nil {
+		l = m.DeclareTunnelsRequest.Size()
+		n += 2 + l + sovMsg(uint64(l))
+	}
+	return n
+}
+func (m *Message_DeclareTunnelsResponse) Size() (n int) {
+	var l int
+	_ = l
+	if m.DeclareTunnelsResponse != nil {
+		l = m.DeclareTunnelsResponse.Size()
+		n += 2 + l + sovMsg(uint64(l))
+	}
+	return n
+}
+
+func sovMsg(x uint64) (n int) {
+	for {
+		n++
+		x >>= 7
+		if x == 0 {
+			break
+		}
+	}
+	return n
+}
+func sozMsg(x uint64) (n int) {
+	return sovMsg(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (this *HandshakeRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&HandshakeRequest{`,
+		`ID:` + fmt.Sprintf("%v", this.ID) + `,`,
+		`Hash:` + fmt.Sprintf("%v", this.Hash) + `,`,
+		`Version:` + fmt.Sprintf("%v", this.Version) + `,`,
+		`Device:` + fmt.Sprintf("%v", this.Device) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *HandshakeResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&HandshakeResponse{`,
+		`ErrCode:` + fmt.Sprintf("%v", this.ErrCode) + `,`,
+		`RegistryAddr:` + fmt.Sprintf("%v", this.RegistryAddr) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *PingRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&PingRequest{`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *PingResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&PingResponse{`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *TunnelHandshakeRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&TunnelHandshakeRequest{`,
+		`ID:` + fmt.Sprintf("%v", this.ID) + `,`,
+		`ClientHash:` + fmt.Sprintf("%v", this.ClientHash) + `,`,
+		`TunnelHash:` + fmt.Sprintf("%v", this.TunnelHash) + `,`,
+		`Visitor:` + fmt.Sprintf("%v", this.Visitor) + `,`,
+		`SecretKey:` + fmt.Sprintf("%v", this.SecretKey) + `,`,
+		`Punch:` + fmt.Sprintf("%v", this.Punch) + `,`,
+		`Nonce:` + fmt.Sprintf("%v", this.Nonce) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *TunnelHandshakeResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&TunnelHandshakeResponse{`,
+		`ErrCode:` + fmt.Sprintf("%v", this.ErrCode) + `,`,
+		`PeerAddr:` + fmt.Sprintf("%v", this.PeerAddr) + `,`,
+		`Nonce:` + fmt.Sprintf("%v", this.Nonce) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *NewTunnelRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&NewTunnelRequest{`,
+		`ID:` + fmt.Sprintf("%v", this.ID) + `,`,
+		`ClientHash:` + fmt.Sprintf("%v", this.ClientHash) + `,`,
+		`TunnelHash:` + fmt.Sprintf("%v", this.TunnelHash) + `,`,
+		`Proto:` + fmt.Sprintf("%v", this.Proto) + `,`,
+		`ExportAddr:` + fmt.Sprintf("%v", this.ExportAddr) + `,`,
+		`RegistryAddr:` + fmt.Sprintf("%v", this.RegistryAddr) + `,`,
+		`HealthCheckPath:` + fmt.Sprintf("%v", this.HealthCheckPath) + `,`,
+		`AllowCIDRs:` + fmt.Sprintf("%v", this.AllowCIDRs) + `,`,
+		`AllowPorts:` + fmt.Sprintf("%v", this.AllowPorts) + `,`,
+		`ProxyUser:` + fmt.Sprintf("%v", this.ProxyUser) + `,`,
+		`ProxyPassword:` + fmt.Sprintf("%v", this.ProxyPassword) + `,`,
+		`LocalTLS:` + fmt.Sprintf("%v", this.LocalTLS) + `,`,
+		`TLSServerName:` + fmt.Sprintf("%v", this.TLSServerName) + `,`,
+		`TLSSkipVerify:` + fmt.Sprintf("%v", this.TLSSkipVerify) + `,`,
+		`TLSCA:` + fmt.Sprintf("%v", this.TLSCA) + `,`,
+		`FileListing:` + fmt.Sprintf("%v", this.FileListing) + `,`,
+		`BasicAuthUser:` + fmt.Sprintf("%v", this.BasicAuthUser) + `,`,
+		`BasicAuthPassword:` + fmt.Sprintf("%v", this.BasicAuthPassword) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *NewTunnelResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&NewTunnelResponse{`,
+		`TunnelHash:` + fmt.Sprintf("%v", this.TunnelHash) + `,`,
+		`ErrCode:` + fmt.Sprintf("%v", this.ErrCode) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *CloseTunnelRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&CloseTunnelRequest{`,
+		`ID:` + fmt.Sprintf("%v", this.ID) + `,`,
+		`ClientHash:` + fmt.Sprintf("%v", this.ClientHash) + `,`,
+		`TunnelHash:` + fmt.Sprintf("%v", this.TunnelHash) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *CloseTunnelResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&CloseTunnelResponse{`,
+		`TunnelHash:` + fmt.Sprintf("%v", this.TunnelHash) + `,`,
+		`ErrCode:` + fmt.Sprintf("%v", this.ErrCode) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *ShutdownRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&ShutdownRequest{`,
+		`ID:` + fmt.Sprintf("%v", this.ID) + `,`,
+		`ClientHash:` + fmt.Sprintf("%v", this.ClientHash) + `,`,
+		`Reconnect:` + fmt.Sprintf("%v", this.Reconnect) + `,`,
+		`ReconnectAddr:` + fmt.Sprintf("%v", this.ReconnectAddr) + `,`,
+		`ReconnectDelay:` + fmt.Sprintf("%v", this.ReconnectDelay) + `,`,
+		`Restart:` + fmt.Sprintf("%v", this.Restart) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *PunchRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&PunchRequest{`,
+		`ID:` + fmt.Sprintf("%v", this.ID) + `,`,
+		`ClientHash:` + fmt.Sprintf("%v", this.ClientHash) + `,`,
+		`TunnelHash:` + fmt.Sprintf("%v", this.TunnelHash) + `,`,
+		`PeerAddr:` + fmt.Sprintf("%v", this.PeerAddr) + `,`,
+		`Nonce:` + fmt.Sprintf("%v", this.Nonce) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *DeclaredTunnelResult) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&DeclaredTunnelResult{`,
+		`Tag:` + fmt.Sprintf("%v", this.Tag) + `,`,
+		`Accepted:` + fmt.Sprintf("%v", this.Accepted) + `,`,
+		`TunnelHash:` + fmt.Sprintf("%v", this.TunnelHash) + `,`,
+		`ServerAddr:` + fmt.Sprintf("%v", this.ServerAddr) + `,`,
+		`Reason:` + fmt.Sprintf("%v", this.Reason) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *DeclareTunnelsResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&DeclareTunnelsResponse{`,
+		`Results:` + strings.Replace(fmt.Sprintf("%v", this.Results), "DeclaredTunnelResult", "DeclaredTunnelResult", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *HealthReport) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&HealthReport{`,
+		`ID:` + fmt.Sprintf("%v", this.ID) + `,`,
+		`ClientHash:` + fmt.Sprintf("%v", this.ClientHash) + `,`,
+		`TunnelHash:` + fmt.Sprintf("%v", this.TunnelHash) + `,`,
+		`Healthy:` + fmt.Sprintf("%v", this.Healthy) + `,`,
+		`Reason:` + fmt.Sprintf("%v", this.Reason) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *DialErrorReport) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&DialErrorReport{`,
+		`ID:` + fmt.Sprintf("%v", this.ID) + `,`,
+		`ClientHash:` + fmt.Sprintf("%v", this.ClientHash) + `,`,
+		`TunnelHash:` + fmt.Sprintf("%v", this.TunnelHash) + `,`,
+		`Class:` + fmt.Sprintf("%v", this.Class) + `,`,
+		`Reason:` + fmt.Sprintf("%v", this.Reason) + `,`,
+		`Count:` + fmt.Sprintf("%v", this.Count) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *PunchReport) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&PunchReport{`,
+		`ID:` + fmt.Sprintf("%v", this.ID) + `,`,
+		`ClientHash:` + fmt.Sprintf("%v", this.ClientHash) + `,`,
+		`TunnelHash:` + fmt.Sprintf("%v", this.TunnelHash) + `,`,
+		`Direct:` + fmt.Sprintf("%v", this.Direct) + `,`,
+		`Reason:` + fmt.Sprintf("%v", this.Reason) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *DeclaredTunnel) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&DeclaredTunnel{`,
+		`Tag:` + fmt.Sprintf("%v", this.Tag) + `,`,
+		`Proto:` + fmt.Sprintf("%v", this.Proto) + `,`,
+		`ExportAddr:` + fmt.Sprintf("%v", this.ExportAddr) + `,`,
+		`ServerAddr:` + fmt.Sprintf("%v", this.ServerAddr) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *DeclareTunnelsRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&DeclareTunnelsRequest{`,
+		`ID:` + fmt.Sprintf("%v", this.ID) + `,`,
+		`ClientHash:` + fmt.Sprintf("%v", this.ClientHash) + `,`,
+		`Tunnels:` + strings.Replace(fmt.Sprintf("%v", this.Tunnels), "DeclaredTunnel", "DeclaredTunnel", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *Message) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&Message{`,
+		`Body:` + fmt.Sprintf("%v", this.Body) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *Message_HandshakeRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&Message_HandshakeRequest{`,
+		`HandshakeRequest:` + strings.Replace(fmt.Sprintf("%v", this.HandshakeRequest), "HandshakeRequest", "HandshakeRequest", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *Message_HandshakeResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&Message_HandshakeResponse{`,
+		`HandshakeResponse:` + strings.Replace(fmt.Sprintf("%v", this.HandshakeResponse), "HandshakeResponse", "HandshakeResponse", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *Message_TunnelHandshakeRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&Message_TunnelHandshakeRequest{`,
+		`TunnelHandshakeRequest:` + strings.Replace(fmt.Sprintf("%v", this.TunnelHandshakeRequest), "TunnelHandshakeRequest", "TunnelHandshakeRequest", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *Message_TunnelHandshakeResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&Message_TunnelHandshakeResponse{`,
+		`TunnelHandshakeResponse:` + strings.Replace(fmt.Sprintf("%v", this.TunnelHandshakeResponse), "TunnelHandshakeResponse", "TunnelHandshakeResponse", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *Message_PingRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&Message_PingRequest{`,
+		`PingRequest:` + strings.Replace(fmt.Sprintf("%v", this.PingRequest), "PingRequest", "PingRequest", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *Message_PingResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&Message_PingResponse{`,
+		`PingResponse:` + strings.Replace(fmt.Sprintf("%v", this.PingResponse), "PingResponse", "PingResponse", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *Message_NewTunnelRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&Message_NewTunnelRequest{`,
+		`NewTunnelRequest:` + strings.Replace(fmt.Sprintf("%v", this.NewTunnelRequest), "NewTunnelRequest", "NewTunnelRequest", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *Message_NewTunnelResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&Message_NewTunnelResponse{`,
+		`NewTunnelResponse:` + strings.Replace(fmt.Sprintf("%v", this.NewTunnelResponse), "NewTunnelResponse", "NewTunnelResponse", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *Message_CloseTunnelRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&Message_CloseTunnelRequest{`,
+		`CloseTunnelRequest:` + strings.Replace(fmt.Sprintf("%v", this.CloseTunnelRequest), "CloseTunnelRequest", "CloseTunnelRequest", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *Message_CloseTunnelResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&Message_CloseTunnelResponse{`,
+		`CloseTunnelResponse:` + strings.Replace(fmt.Sprintf("%v", this.CloseTunnelResponse), "CloseTunnelResponse", "CloseTunnelResponse", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *Message_ShutdownRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&Message_ShutdownRequest{`,
+		`ShutdownRequest:` + strings.Replace(fmt.Sprintf("%v", this.ShutdownRequest), "ShutdownRequest", "ShutdownRequest", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *Message_HealthReport) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&Message_HealthReport{`,
+		`HealthReport:` + strings.Replace(fmt.Sprintf("%v", this.HealthReport), "HealthReport", "HealthReport", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *Message_DialErrorReport) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&Message_DialErrorReport{`,
+		`DialErrorReport:` + strings.Replace(fmt.Sprintf("%v", this.DialErrorReport), "DialErrorReport", "DialErrorReport", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *Message_PunchRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&Message_PunchRequest{`,
+		`PunchRequest:` + strings.Replace(fmt.Sprintf("%v", this.PunchRequest), "PunchRequest", "PunchRequest", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *Message_PunchReport) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&Message_PunchReport{`,
+		`PunchReport:` + strings.Replace(fmt.Sprintf("%v", this.PunchReport), "PunchReport", "PunchReport", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *Message_DeclareTunnelsRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&Message_DeclareTunnelsRequest{`,
+		`DeclareTunnelsRequest:` + strings.Replace(fmt.Sprintf("%v", this.DeclareTunnelsRequest), "DeclareTunnelsRequest", "DeclareTunnelsRequest", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *Message_DeclareTunnelsResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&Message_DeclareTunnelsResponse{`,
+		`DeclareTunnelsResponse:` + strings.Replace(fmt.Sprintf("%v", this.DeclareTunnelsResponse), "DeclareTunnelsResponse", "DeclareTunnelsResponse", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func valueToStringMsg(v interface{}) string {
+	rv := reflect.ValueOf(v)
+	if rv.IsNil() {
+		return "nil"
+	}
+	pv := reflect.Indirect(rv).Interface()
+	return fmt.Sprintf("*%v", pv)
+}
+func (m *HandshakeRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMsg
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: HandshakeRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: HandshakeRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ID", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMsg
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ID = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Hash", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMsg
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Hash = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Version", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMsg
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Version = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Device", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMsg
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Device = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMsg(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMsg
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *HandshakeResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMsg
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: HandshakeResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: HandshakeResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ErrCode", wireType)
+			}
+			m.ErrCode = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ErrCode |= (ErrCode(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RegistryAddr", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMsg
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.RegistryAddr = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMsg(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMsg
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *PingRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMsg
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: PingRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: PingRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMsg(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMsg
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *PingResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMsg
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: PingResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: PingResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMsg(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMsg
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *TunnelHandshakeRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMsg
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: TunnelHandshakeRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: TunnelHandshakeRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ID", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMsg
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ID = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ClientHash", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMsg
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ClientHash = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TunnelHash", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMsg
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.TunnelHash = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Visitor", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Visitor = bool(v != 0)
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SecretKey", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMsg
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.SecretKey = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Punch", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Punch = bool(v != 0)
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Nonce", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMsg
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Nonce = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMsg(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMsg
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *TunnelHandshakeResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMsg
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: TunnelHandshakeResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: TunnelHandshakeResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ErrCode", wireType)
+			}
+			m.ErrCode = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ErrCode |= (ErrCode(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PeerAddr", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMsg
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.PeerAddr = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Nonce", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMsg
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Nonce = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMsg(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMsg
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *NewTunnelRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMsg
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: NewTunnelRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: NewTunnelRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ID", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMsg
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ID = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ClientHash", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMsg
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ClientHash = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TunnelHash", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMsg
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.TunnelHash = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Proto", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMsg
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Proto = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ExportAddr", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMsg
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ExportAddr = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RegistryAddr", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMsg
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.RegistryAddr = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field HealthCheckPath", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMsg
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.HealthCheckPath = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 8:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AllowCIDRs", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMsg
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.AllowCIDRs = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 9:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AllowPorts", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMsg
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.AllowPorts = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 10:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ProxyUser", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMsg
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ProxyUser = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 11:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ProxyPassword", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMsg
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ProxyPassword = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 12:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LocalTLS", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.LocalTLS = bool(v != 0)
+		case 13:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TLSServerName", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMsg
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.TLSServerName = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 14:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TLSSkipVerify", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.TLSSkipVerify = bool(v != 0)
+		case 15:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TLSCA", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMsg
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.TLSCA = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 16:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field FileListing", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.FileListing = bool(v != 0)
+		case 17:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field BasicAuthUser", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMsg
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.BasicAuthUser = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 18:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field BasicAuthPassword", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMsg
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.BasicAuthPassword = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMsg(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMsg
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *NewTunnelResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMsg
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: NewTunnelResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: NewTunnelResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TunnelHash", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMsg
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.TunnelHash = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ErrCode", wireType)
+			}
+			m.ErrCode = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ErrCode |= (ErrCode(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMsg(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMsg
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *CloseTunnelRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMsg
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: CloseTunnelRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: CloseTunnelRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ID", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMsg
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ID = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ClientHash", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMsg
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ClientHash = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TunnelHash", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMsg
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.TunnelHash = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMsg(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMsg
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *CloseTunnelResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMsg
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: CloseTunnelResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: CloseTunnelResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TunnelHash", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMsg
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.TunnelHash = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ErrCode", wireType)
+			}
+			m.ErrCode = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ErrCode |= (ErrCode(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMsg(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMsg
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
 	}
-	s := strings.Join([]string{`&Message_CloseTunnelRequest{`,
-		`CloseTunnelRequest:` + strings.Replace(fmt.Sprintf("%v", this.CloseTunnelRequest), "CloseTunnelRequest", "CloseTunnelRequest", 1) + `,`,
-		`}`,
-	}, "")
-	return s
-}
-func (this *Message_CloseTunnelResponse) String() string {
-	if this == nil {
-		return "nil"
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
 	}
-	s := strings.Join([]string{`&Message_CloseTunnelResponse{`,
-		`CloseTunnelResponse:` + strings.Replace(fmt.Sprintf("%v", this.CloseTunnelResponse), "CloseTunnelResponse", "CloseTunnelResponse", 1) + `,`,
-		`}`,
-	}, "")
-	return s
+	return nil
 }
-func (this *Message_ShutdownRequest) String() string {
-	if this == nil {
-		return "nil"
+func (m *ShutdownRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMsg
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ShutdownRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ShutdownRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ID", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMsg
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ID = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ClientHash", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMsg
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ClientHash = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Reconnect", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Reconnect = bool(v != 0)
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ReconnectAddr", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMsg
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ReconnectAddr = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ReconnectDelay", wireType)
+			}
+			m.ReconnectDelay = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ReconnectDelay |= (uint32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Restart", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Restart = bool(v != 0)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMsg(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMsg
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
 	}
-	s := strings.Join([]string{`&Message_ShutdownRequest{`,
-		`ShutdownRequest:` + strings.Replace(fmt.Sprintf("%v", this.ShutdownRequest), "ShutdownRequest", "ShutdownRequest", 1) + `,`,
-		`}`,
-	}, "")
-	return s
-}
-func valueToStringMsg(v interface{}) string {
-	rv := reflect.ValueOf(v)
-	if rv.IsNil() {
-		return "nil"
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
 	}
-	pv := reflect.Indirect(rv).Interface()
-	return fmt.Sprintf("*%v", pv)
+	return nil
 }
-func (m *HandshakeRequest) Unmarshal(dAtA []byte) error {
+func (m *PunchRequest) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -2675,15 +6748,44 @@ func (m *HandshakeRequest) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: HandshakeRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: PunchRequest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: HandshakeRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: PunchRequest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ID", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field ID", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMsg
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ID = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ClientHash", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -2708,11 +6810,11 @@ func (m *HandshakeRequest) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.ID = string(dAtA[iNdEx:postIndex])
+			m.ClientHash = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
-		case 2:
+		case 3:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Hash", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field TunnelHash", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -2737,11 +6839,11 @@ func (m *HandshakeRequest) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Hash = string(dAtA[iNdEx:postIndex])
+			m.TunnelHash = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
-		case 3:
+		case 4:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Version", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field PeerAddr", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -2766,11 +6868,11 @@ func (m *HandshakeRequest) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Version = string(dAtA[iNdEx:postIndex])
+			m.PeerAddr = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
-		case 4:
+		case 5:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Device", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Nonce", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -2795,7 +6897,7 @@ func (m *HandshakeRequest) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Device = string(dAtA[iNdEx:postIndex])
+			m.Nonce = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
@@ -2818,7 +6920,7 @@ func (m *HandshakeRequest) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *HandshakeResponse) Unmarshal(dAtA []byte) error {
+func (m *DeclaredTunnelResult) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -2841,17 +6943,46 @@ func (m *HandshakeResponse) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: HandshakeResponse: wiretype end group for non-group")
+			return fmt.Errorf("proto: DeclaredTunnelResult: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: HandshakeResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: DeclaredTunnelResult: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Tag", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMsg
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Tag = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ErrCode", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Accepted", wireType)
 			}
-			m.ErrCode = 0
+			var v int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMsg
@@ -2861,61 +6992,99 @@ func (m *HandshakeResponse) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.ErrCode |= (ErrCode(b) & 0x7F) << shift
+				v |= (int(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-		default:
-			iNdEx = preIndex
-			skippy, err := skipMsg(dAtA[iNdEx:])
-			if err != nil {
-				return err
+			m.Accepted = bool(v != 0)
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TunnelHash", wireType)
 			}
-			if skippy < 0 {
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthMsg
 			}
-			if (iNdEx + skippy) > l {
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			iNdEx += skippy
-		}
-	}
-
-	if iNdEx > l {
-		return io.ErrUnexpectedEOF
-	}
-	return nil
-}
-func (m *PingRequest) Unmarshal(dAtA []byte) error {
-	l := len(dAtA)
-	iNdEx := 0
-	for iNdEx < l {
-		preIndex := iNdEx
-		var wire uint64
-		for shift := uint(0); ; shift += 7 {
-			if shift >= 64 {
-				return ErrIntOverflowMsg
+			m.TunnelHash = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ServerAddr", wireType)
 			}
-			if iNdEx >= l {
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMsg
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			b := dAtA[iNdEx]
-			iNdEx++
-			wire |= (uint64(b) & 0x7F) << shift
-			if b < 0x80 {
-				break
+			m.ServerAddr = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Reason", wireType)
 			}
-		}
-		fieldNum := int32(wire >> 3)
-		wireType := int(wire & 0x7)
-		if wireType == 4 {
-			return fmt.Errorf("proto: PingRequest: wiretype end group for non-group")
-		}
-		if fieldNum <= 0 {
-			return fmt.Errorf("proto: PingRequest: illegal tag %d (wire type %d)", fieldNum, wire)
-		}
-		switch fieldNum {
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMsg
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Reason = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipMsg(dAtA[iNdEx:])
@@ -2937,7 +7106,7 @@ func (m *PingRequest) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *PingResponse) Unmarshal(dAtA []byte) error {
+func (m *DeclareTunnelsResponse) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -2960,12 +7129,43 @@ func (m *PingResponse) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: PingResponse: wiretype end group for non-group")
+			return fmt.Errorf("proto: DeclareTunnelsResponse: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: PingResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: DeclareTunnelsResponse: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Results", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMsg
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Results = append(m.Results, &DeclaredTunnelResult{})
+			if err := m.Results[len(m.Results)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipMsg(dAtA[iNdEx:])
@@ -2987,7 +7187,7 @@ func (m *PingResponse) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *TunnelHandshakeRequest) Unmarshal(dAtA []byte) error {
+func (m *HealthReport) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -3010,10 +7210,10 @@ func (m *TunnelHandshakeRequest) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: TunnelHandshakeRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: HealthReport: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: TunnelHandshakeRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: HealthReport: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
@@ -3076,7 +7276,56 @@ func (m *TunnelHandshakeRequest) Unmarshal(dAtA []byte) error {
 			iNdEx = postIndex
 		case 3:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field TunnelHash", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field TunnelHash", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMsg
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.TunnelHash = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Healthy", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Healthy = bool(v != 0)
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Reason", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -3101,7 +7350,7 @@ func (m *TunnelHandshakeRequest) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.TunnelHash = string(dAtA[iNdEx:postIndex])
+			m.Reason = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
@@ -3124,76 +7373,7 @@ func (m *TunnelHandshakeRequest) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *TunnelHandshakeResponse) Unmarshal(dAtA []byte) error {
-	l := len(dAtA)
-	iNdEx := 0
-	for iNdEx < l {
-		preIndex := iNdEx
-		var wire uint64
-		for shift := uint(0); ; shift += 7 {
-			if shift >= 64 {
-				return ErrIntOverflowMsg
-			}
-			if iNdEx >= l {
-				return io.ErrUnexpectedEOF
-			}
-			b := dAtA[iNdEx]
-			iNdEx++
-			wire |= (uint64(b) & 0x7F) << shift
-			if b < 0x80 {
-				break
-			}
-		}
-		fieldNum := int32(wire >> 3)
-		wireType := int(wire & 0x7)
-		if wireType == 4 {
-			return fmt.Errorf("proto: TunnelHandshakeResponse: wiretype end group for non-group")
-		}
-		if fieldNum <= 0 {
-			return fmt.Errorf("proto: TunnelHandshakeResponse: illegal tag %d (wire type %d)", fieldNum, wire)
-		}
-		switch fieldNum {
-		case 1:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ErrCode", wireType)
-			}
-			m.ErrCode = 0
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowMsg
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				m.ErrCode |= (ErrCode(b) & 0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
-		default:
-			iNdEx = preIndex
-			skippy, err := skipMsg(dAtA[iNdEx:])
-			if err != nil {
-				return err
-			}
-			if skippy < 0 {
-				return ErrInvalidLengthMsg
-			}
-			if (iNdEx + skippy) > l {
-				return io.ErrUnexpectedEOF
-			}
-			iNdEx += skippy
-		}
-	}
-
-	if iNdEx > l {
-		return io.ErrUnexpectedEOF
-	}
-	return nil
-}
-func (m *NewTunnelRequest) Unmarshal(dAtA []byte) error {
+func (m *DialErrorReport) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -3216,10 +7396,10 @@ func (m *NewTunnelRequest) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: NewTunnelRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: DialErrorReport: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: NewTunnelRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: DialErrorReport: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
@@ -3310,10 +7490,10 @@ func (m *NewTunnelRequest) Unmarshal(dAtA []byte) error {
 			m.TunnelHash = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		case 4:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Proto", wireType)
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Class", wireType)
 			}
-			var stringLen uint64
+			m.Class = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMsg
@@ -3323,24 +7503,14 @@ func (m *NewTunnelRequest) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= (uint64(b) & 0x7F) << shift
+				m.Class |= (DialErrorClass(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
-				return ErrInvalidLengthMsg
-			}
-			postIndex := iNdEx + intStringLen
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			m.Proto = string(dAtA[iNdEx:postIndex])
-			iNdEx = postIndex
 		case 5:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ExportAddr", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Reason", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -3365,13 +7535,13 @@ func (m *NewTunnelRequest) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.ExportAddr = string(dAtA[iNdEx:postIndex])
+			m.Reason = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		case 6:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field RegistryAddr", wireType)
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Count", wireType)
 			}
-			var stringLen uint64
+			m.Count = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMsg
@@ -3381,21 +7551,11 @@ func (m *NewTunnelRequest) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= (uint64(b) & 0x7F) << shift
+				m.Count |= (uint32(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
-				return ErrInvalidLengthMsg
-			}
-			postIndex := iNdEx + intStringLen
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			m.RegistryAddr = string(dAtA[iNdEx:postIndex])
-			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipMsg(dAtA[iNdEx:])
@@ -3417,7 +7577,7 @@ func (m *NewTunnelRequest) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *NewTunnelResponse) Unmarshal(dAtA []byte) error {
+func (m *PunchReport) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -3440,15 +7600,15 @@ func (m *NewTunnelResponse) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: NewTunnelResponse: wiretype end group for non-group")
+			return fmt.Errorf("proto: PunchReport: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: NewTunnelResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: PunchReport: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field TunnelHash", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field ID", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -3473,13 +7633,13 @@ func (m *NewTunnelResponse) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.TunnelHash = string(dAtA[iNdEx:postIndex])
+			m.ID = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		case 2:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ErrCode", wireType)
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ClientHash", wireType)
 			}
-			m.ErrCode = 0
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMsg
@@ -3489,64 +7649,24 @@ func (m *NewTunnelResponse) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.ErrCode |= (ErrCode(b) & 0x7F) << shift
+				stringLen |= (uint64(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-		default:
-			iNdEx = preIndex
-			skippy, err := skipMsg(dAtA[iNdEx:])
-			if err != nil {
-				return err
-			}
-			if skippy < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthMsg
 			}
-			if (iNdEx + skippy) > l {
-				return io.ErrUnexpectedEOF
-			}
-			iNdEx += skippy
-		}
-	}
-
-	if iNdEx > l {
-		return io.ErrUnexpectedEOF
-	}
-	return nil
-}
-func (m *CloseTunnelRequest) Unmarshal(dAtA []byte) error {
-	l := len(dAtA)
-	iNdEx := 0
-	for iNdEx < l {
-		preIndex := iNdEx
-		var wire uint64
-		for shift := uint(0); ; shift += 7 {
-			if shift >= 64 {
-				return ErrIntOverflowMsg
-			}
-			if iNdEx >= l {
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			b := dAtA[iNdEx]
-			iNdEx++
-			wire |= (uint64(b) & 0x7F) << shift
-			if b < 0x80 {
-				break
-			}
-		}
-		fieldNum := int32(wire >> 3)
-		wireType := int(wire & 0x7)
-		if wireType == 4 {
-			return fmt.Errorf("proto: CloseTunnelRequest: wiretype end group for non-group")
-		}
-		if fieldNum <= 0 {
-			return fmt.Errorf("proto: CloseTunnelRequest: illegal tag %d (wire type %d)", fieldNum, wire)
-		}
-		switch fieldNum {
-		case 1:
+			m.ClientHash = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ID", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field TunnelHash", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -3571,13 +7691,13 @@ func (m *CloseTunnelRequest) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.ID = string(dAtA[iNdEx:postIndex])
+			m.TunnelHash = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
-		case 2:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ClientHash", wireType)
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Direct", wireType)
 			}
-			var stringLen uint64
+			var v int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMsg
@@ -3587,24 +7707,15 @@ func (m *CloseTunnelRequest) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= (uint64(b) & 0x7F) << shift
+				v |= (int(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
-				return ErrInvalidLengthMsg
-			}
-			postIndex := iNdEx + intStringLen
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			m.ClientHash = string(dAtA[iNdEx:postIndex])
-			iNdEx = postIndex
-		case 3:
+			m.Direct = bool(v != 0)
+		case 5:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field TunnelHash", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Reason", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -3629,7 +7740,7 @@ func (m *CloseTunnelRequest) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.TunnelHash = string(dAtA[iNdEx:postIndex])
+			m.Reason = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
@@ -3652,7 +7763,7 @@ func (m *CloseTunnelRequest) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *CloseTunnelResponse) Unmarshal(dAtA []byte) error {
+func (m *DeclaredTunnel) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -3662,28 +7773,86 @@ func (m *CloseTunnelResponse) Unmarshal(dAtA []byte) error {
 			if shift >= 64 {
 				return ErrIntOverflowMsg
 			}
-			if iNdEx >= l {
-				return io.ErrUnexpectedEOF
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: DeclaredTunnel: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: DeclaredTunnel: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Tag", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMsg
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Tag = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Proto", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMsg
 			}
-			b := dAtA[iNdEx]
-			iNdEx++
-			wire |= (uint64(b) & 0x7F) << shift
-			if b < 0x80 {
-				break
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
 			}
-		}
-		fieldNum := int32(wire >> 3)
-		wireType := int(wire & 0x7)
-		if wireType == 4 {
-			return fmt.Errorf("proto: CloseTunnelResponse: wiretype end group for non-group")
-		}
-		if fieldNum <= 0 {
-			return fmt.Errorf("proto: CloseTunnelResponse: illegal tag %d (wire type %d)", fieldNum, wire)
-		}
-		switch fieldNum {
-		case 1:
+			m.Proto = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field TunnelHash", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field ExportAddr", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -3708,13 +7877,13 @@ func (m *CloseTunnelResponse) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.TunnelHash = string(dAtA[iNdEx:postIndex])
+			m.ExportAddr = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
-		case 2:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ErrCode", wireType)
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ServerAddr", wireType)
 			}
-			m.ErrCode = 0
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMsg
@@ -3724,11 +7893,21 @@ func (m *CloseTunnelResponse) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.ErrCode |= (ErrCode(b) & 0x7F) << shift
+				stringLen |= (uint64(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMsg
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ServerAddr = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipMsg(dAtA[iNdEx:])
@@ -3750,7 +7929,7 @@ func (m *CloseTunnelResponse) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *ShutdownRequest) Unmarshal(dAtA []byte) error {
+func (m *DeclareTunnelsRequest) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -3773,10 +7952,10 @@ func (m *ShutdownRequest) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: ShutdownRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: DeclareTunnelsRequest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: ShutdownRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: DeclareTunnelsRequest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
@@ -3837,6 +8016,37 @@ func (m *ShutdownRequest) Unmarshal(dAtA []byte) error {
 			}
 			m.ClientHash = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Tunnels", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMsg
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Tunnels = append(m.Tunnels, &DeclaredTunnel{})
+			if err := m.Tunnels[len(m.Tunnels)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipMsg(dAtA[iNdEx:])
@@ -4239,6 +8449,198 @@ func (m *Message) Unmarshal(dAtA []byte) error {
 			}
 			m.Body = &Message_ShutdownRequest{v}
 			iNdEx = postIndex
+		case 12:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field HealthReport", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMsg
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			v := &HealthReport{}
+			if err := v.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			m.Body = &Message_HealthReport{v}
+			iNdEx = postIndex
+		case 13:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DialErrorReport", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMsg
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			v := &DialErrorReport{}
+			if err := v.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			m.Body = &Message_DialErrorReport{v}
+			iNdEx = postIndex
+		case 14:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PunchRequest", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMsg
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			v := &PunchRequest{}
+			if err := v.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			m.Body = &Message_PunchRequest{v}
+			iNdEx = postIndex
+		case 15:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PunchReport", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMsg
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			v := &PunchReport{}
+			if err := v.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			m.Body = &Message_PunchReport{v}
+			iNdEx = postIndex
+		case 16:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DeclareTunnelsRequest", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMsg
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			v := &DeclareTunnelsRequest{}
+			if err := v.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			m.Body = &Message_DeclareTunnelsRequest{v}
+			iNdEx = postIndex
+		case 17:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DeclareTunnelsResponse", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMsg
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			v := &DeclareTunnelsResponse{}
+			if err := v.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			m.Body = &Message_DeclareTunnelsResponse{v}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipMsg(dAtA[iNdEx:])
@@ -4368,56 +8770,109 @@ var (
 func init() { proto.RegisterFile("msg/msgpb/msg.proto", fileDescriptorMsg) }
 
 var fileDescriptorMsg = []byte{
-	// 812 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x55, 0xc1, 0x6e, 0xf3, 0x44,
-	0x10, 0xb6, 0xd3, 0xc4, 0x69, 0xc7, 0x69, 0xeb, 0x6c, 0xda, 0xc4, 0x8d, 0x84, 0x8b, 0xcc, 0x05,
-	0x90, 0x48, 0x51, 0x39, 0x20, 0x71, 0x40, 0x6a, 0xd2, 0x82, 0x5b, 0xa9, 0xa5, 0x72, 0x11, 0x12,
-	0x12, 0x52, 0xe4, 0xd8, 0x8b, 0x6d, 0x91, 0xda, 0x66, 0x6d, 0xb7, 0xf4, 0xc6, 0x23, 0xf0, 0x18,
-	0x3c, 0x4a, 0x2f, 0x48, 0x15, 0x27, 0x4e, 0x88, 0x98, 0x0b, 0xc7, 0x3e, 0x02, 0xf2, 0xee, 0x26,
-	0x71, 0xec, 0x14, 0x81, 0x50, 0xff, 0x4b, 0xb4, 0x33, 0xb3, 0xf3, 0xcd, 0x37, 0xb3, 0xf9, 0xc6,
-	0xd0, 0xb9, 0x8d, 0xdd, 0xa3, 0xdb, 0xd8, 0x8d, 0x26, 0xf9, 0xef, 0x20, 0x22, 0x61, 0x12, 0xa2,
-	0x06, 0x75, 0xf4, 0x3f, 0x70, 0xfd, 0xc4, 0x4b, 0x27, 0x03, 0x3b, 0xbc, 0x3d, 0x72, 0x43, 0x37,
-	0x3c, 0xa2, 0xd1, 0x49, 0xfa, 0x2d, 0xb5, 0xa8, 0x41, 0x4f, 0x2c, 0x4b, 0x8f, 0x40, 0x31, 0xac,
-	0xc0, 0x89, 0x3d, 0xeb, 0x3b, 0x6c, 0xe2, 0xef, 0x53, 0x1c, 0x27, 0xa8, 0x0b, 0x35, 0xdf, 0x51,
-	0xc5, 0xb7, 0xc5, 0x77, 0xb7, 0x86, 0x52, 0xf6, 0xfb, 0x61, 0xed, 0xfc, 0xd4, 0xac, 0xf9, 0x0e,
-	0x42, 0x50, 0xf7, 0xac, 0xd8, 0x53, 0x6b, 0x79, 0xc4, 0xa4, 0x67, 0xa4, 0x42, 0xf3, 0x0e, 0x93,
-	0xd8, 0x0f, 0x03, 0x75, 0x83, 0xba, 0xe7, 0x26, 0xea, 0x82, 0xe4, 0xe0, 0x3b, 0xdf, 0xc6, 0x6a,
-	0x9d, 0x06, 0xb8, 0xa5, 0x7f, 0x0a, 0xed, 0x42, 0xc5, 0x38, 0x0a, 0x83, 0x18, 0xa3, 0xf7, 0x60,
-	0x13, 0x13, 0x32, 0xb6, 0x43, 0x07, 0xd3, 0xc2, 0x3b, 0xc7, 0x3b, 0x03, 0xda, 0xcf, 0xe0, 0x8c,
-	0x90, 0x51, 0xe8, 0x60, 0xb3, 0x89, 0xd9, 0x41, 0xdf, 0x06, 0xf9, 0xda, 0x0f, 0x5c, 0x4e, 0x56,
-	0xdf, 0x81, 0x16, 0x33, 0x19, 0x92, 0x4e, 0xa0, 0xfb, 0x65, 0x1a, 0x04, 0x78, 0xfa, 0xaf, 0xdb,
-	0x3a, 0x04, 0xd9, 0x9e, 0xfa, 0x38, 0x48, 0xc6, 0x85, 0xee, 0x80, 0xb9, 0x8c, 0xbc, 0xc7, 0x43,
-	0x90, 0x13, 0x0a, 0xc9, 0x2e, 0xb0, 0x3e, 0x21, 0xe1, 0x55, 0x62, 0x4f, 0x3f, 0x85, 0x5e, 0xa5,
-	0xe6, 0x7f, 0x6f, 0xec, 0x51, 0x04, 0xe5, 0x0a, 0xdf, 0x33, 0xa4, 0x57, 0x27, 0x8d, 0xf6, 0xa0,
-	0x41, 0xff, 0x02, 0xfc, 0x79, 0x98, 0x91, 0xa7, 0xe1, 0x1f, 0xa2, 0x90, 0x24, 0x63, 0xcb, 0x71,
-	0x88, 0xda, 0x60, 0x69, 0xcc, 0x75, 0xe2, 0x38, 0x04, 0xbd, 0x03, 0xdb, 0x04, 0xbb, 0x7e, 0x9c,
-	0x90, 0x07, 0x76, 0x45, 0xa2, 0x57, 0x5a, 0x73, 0x67, 0x7e, 0x49, 0x1f, 0x43, 0xbb, 0xd0, 0x09,
-	0x1f, 0x45, 0x89, 0x91, 0x58, 0x61, 0x54, 0x9c, 0x55, 0xed, 0x9f, 0x67, 0x15, 0x00, 0x1a, 0x4d,
-	0xc3, 0x18, 0xbf, 0xa1, 0x61, 0xe9, 0x16, 0x74, 0x56, 0xea, 0xbd, 0x42, 0x4b, 0x17, 0xb0, 0x7b,
-	0xe3, 0xa5, 0x89, 0x13, 0xde, 0x07, 0xff, 0xb7, 0x1f, 0xfd, 0x17, 0x09, 0x9a, 0x97, 0x38, 0x8e,
-	0x2d, 0x17, 0xa3, 0xcf, 0xa0, 0xed, 0xcd, 0xff, 0x96, 0x63, 0xc2, 0x90, 0x29, 0xa6, 0x7c, 0xdc,
-	0xe3, 0x5c, 0xca, 0x52, 0x31, 0x04, 0x53, 0xf1, 0xca, 0xf2, 0x39, 0x07, 0x54, 0xc4, 0x61, 0x13,
-	0xa0, 0xb5, 0xe5, 0x63, 0xb5, 0x0a, 0xc4, 0xe2, 0x86, 0x60, 0xb6, 0xbd, 0x8a, 0x28, 0xbe, 0x06,
-	0x75, 0x31, 0xb6, 0x32, 0xb3, 0x0d, 0x0a, 0xf8, 0x16, 0x07, 0x5c, 0x2f, 0x65, 0x43, 0x30, 0xbb,
-	0xc9, 0x7a, 0x91, 0x7f, 0x03, 0x07, 0x6b, 0xa0, 0x39, 0xd9, 0x3a, 0xc5, 0xd6, 0x5e, 0xc2, 0x5e,
-	0x50, 0xee, 0x25, 0x2f, 0xa8, 0xf9, 0x63, 0x68, 0x45, 0x7e, 0xe0, 0x2e, 0xc8, 0x36, 0x28, 0x20,
-	0xe2, 0x80, 0x85, 0xb5, 0x64, 0x08, 0xa6, 0x1c, 0x2d, 0x4d, 0xf4, 0x09, 0x6c, 0xf3, 0x44, 0x4e,
-	0x45, 0xa2, 0x99, 0x9d, 0x95, 0xcc, 0x45, 0xfd, 0x56, 0x54, 0xb0, 0xd1, 0xe7, 0x80, 0x02, 0x7c,
-	0x3f, 0xe6, 0x6d, 0xcd, 0x4b, 0x37, 0x57, 0x5e, 0xb0, 0xbc, 0x37, 0xf2, 0x17, 0x0c, 0xca, 0xbb,
-	0xe4, 0x02, 0x3a, 0x2b, 0x40, 0x9c, 0xca, 0xe6, 0xca, 0x13, 0x56, 0x74, 0x9b, 0x3f, 0x61, 0x50,
-	0x11, 0xf3, 0x25, 0xec, 0xd9, 0xb9, 0x20, 0xca, 0xb4, 0xb6, 0x28, 0xd8, 0x01, 0x07, 0xab, 0x6a,
-	0xd4, 0x10, 0x4c, 0x64, 0x57, 0x95, 0x7b, 0x0d, 0xfb, 0x25, 0x38, 0x4e, 0x0e, 0x28, 0x5e, 0x7f,
-	0x1d, 0xde, 0x82, 0x5e, 0xc7, 0x5e, 0x23, 0xcd, 0x11, 0x28, 0x31, 0x97, 0xd3, 0x82, 0x9c, 0x4c,
-	0xc1, 0xba, 0x1c, 0xac, 0xa4, 0x36, 0x43, 0x30, 0x77, 0xe3, 0x55, 0xd7, 0x50, 0x82, 0xfa, 0x24,
-	0x74, 0x1e, 0xde, 0xff, 0x55, 0x84, 0x26, 0x17, 0x2c, 0xda, 0x05, 0x99, 0x1f, 0xaf, 0xd2, 0xe9,
-	0x54, 0x11, 0xd0, 0x1e, 0x28, 0xdc, 0x31, 0xb4, 0x9c, 0x11, 0x15, 0xa1, 0x22, 0xa2, 0x7d, 0x68,
-	0x2f, 0xbd, 0x5f, 0xb1, 0x6f, 0xa2, 0x52, 0x43, 0x07, 0xb0, 0xbf, 0x74, 0x5f, 0xe7, 0x2b, 0xf7,
-	0x0b, 0x92, 0xaf, 0x4c, 0x65, 0x03, 0xf5, 0xa1, 0xbb, 0x0c, 0x99, 0x85, 0x75, 0xaa, 0xd4, 0x51,
-	0x0f, 0x3a, 0xf3, 0xa2, 0xe1, 0x4d, 0x6a, 0x7b, 0xac, 0x59, 0xa5, 0x51, 0xc0, 0x3b, 0x4d, 0xa3,
-	0xa9, 0x6f, 0x5b, 0x09, 0x3e, 0x71, 0x73, 0x06, 0x12, 0xd2, 0xa0, 0xcf, 0x43, 0xe7, 0x41, 0x82,
-	0x49, 0x60, 0x4d, 0x6f, 0x30, 0xb9, 0xc3, 0xe4, 0x8c, 0x90, 0x90, 0x28, 0xcd, 0xe1, 0x87, 0x8f,
-	0x33, 0x4d, 0x78, 0x9a, 0x69, 0xc2, 0x6f, 0x33, 0x4d, 0x78, 0x9e, 0x69, 0xe2, 0x8f, 0x99, 0x26,
-	0xfe, 0x9c, 0x69, 0xe2, 0x63, 0xa6, 0x89, 0x4f, 0x99, 0x26, 0xfe, 0x91, 0x69, 0xe2, 0x5f, 0x99,
-	0x26, 0x3c, 0x67, 0x9a, 0xf8, 0xd3, 0x9f, 0x9a, 0x30, 0x91, 0xe8, 0x37, 0xe2, 0xa3, 0xbf, 0x03,
-	0x00, 0x00, 0xff, 0xff, 0xfe, 0xae, 0x12, 0xbc, 0x80, 0x08, 0x00, 0x00,
+	// 1663 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x58, 0x4f, 0x6f, 0x23, 0x49,
+	0x15, 0x77, 0xdb, 0xb1, 0x63, 0x3f, 0xff, 0x6b, 0x57, 0x26, 0x4e, 0x4f, 0x76, 0xd7, 0x19, 0x8c,
+	0x80, 0xdd, 0x01, 0x12, 0x29, 0x08, 0x21, 0xb8, 0x25, 0xf6, 0x2e, 0x1e, 0xc8, 0xce, 0x46, 0xed,
+	0xec, 0x48, 0x48, 0x48, 0xad, 0x4a, 0x77, 0x8d, 0xbb, 0x95, 0x4e, 0x77, 0x53, 0x55, 0x3d, 0xd9,
+	0x70, 0x02, 0xf1, 0x21, 0x90, 0x40, 0xe2, 0xcc, 0x27, 0x41, 0x2b, 0x4e, 0x2b, 0x2e, 0x70, 0x8a,
+	0x88, 0x4f, 0x1c, 0xf7, 0xc6, 0x15, 0xd5, 0x1f, 0xb7, 0xbb, 0x6d, 0x67, 0x17, 0x34, 0x0a, 0x97,
+	0xa8, 0xdf, 0xef, 0x55, 0xbd, 0xf7, 0x7b, 0xaf, 0x5e, 0xbd, 0x7a, 0x0e, 0xec, 0x5c, 0xb3, 0xd9,
+	0xd1, 0x35, 0x9b, 0x25, 0x97, 0xe2, 0xef, 0x61, 0x42, 0x63, 0x1e, 0xa3, 0xaa, 0x04, 0xf6, 0xbf,
+	0x3f, 0x0b, 0xb8, 0x9f, 0x5e, 0x1e, 0xba, 0xf1, 0xf5, 0xd1, 0x2c, 0x9e, 0xc5, 0x47, 0x52, 0x7b,
+	0x99, 0xbe, 0x96, 0x92, 0x14, 0xe4, 0x97, 0xda, 0x35, 0x4c, 0xc0, 0x9c, 0xe0, 0xc8, 0x63, 0x3e,
+	0xbe, 0x22, 0x36, 0xf9, 0x55, 0x4a, 0x18, 0x47, 0x7d, 0x28, 0x07, 0x9e, 0x65, 0x3c, 0x33, 0xde,
+	0x6f, 0x9c, 0xd6, 0xe6, 0x77, 0x07, 0xe5, 0x17, 0x63, 0xbb, 0x1c, 0x78, 0x08, 0xc1, 0x96, 0x8f,
+	0x99, 0x6f, 0x95, 0x85, 0xc6, 0x96, 0xdf, 0xc8, 0x82, 0xed, 0x37, 0x84, 0xb2, 0x20, 0x8e, 0xac,
+	0x8a, 0x84, 0x17, 0x22, 0xea, 0x43, 0xcd, 0x23, 0x6f, 0x02, 0x97, 0x58, 0x5b, 0x52, 0xa1, 0xa5,
+	0xa1, 0x0b, 0xbd, 0x9c, 0x47, 0x96, 0xc4, 0x11, 0x23, 0xe8, 0x03, 0xa8, 0x13, 0x4a, 0x1d, 0x37,
+	0xf6, 0x88, 0x74, 0xdc, 0x39, 0xee, 0x1c, 0xca, 0x78, 0x0e, 0x3f, 0xa4, 0x74, 0x14, 0x7b, 0xc4,
+	0xde, 0x26, 0xea, 0x03, 0x7d, 0x13, 0xda, 0x94, 0xcc, 0x02, 0xc6, 0xe9, 0xad, 0x83, 0x3d, 0x8f,
+	0x6a, 0x3a, 0xad, 0x05, 0x78, 0xe2, 0x79, 0x74, 0xd8, 0x86, 0xe6, 0x79, 0x10, 0xcd, 0x74, 0x44,
+	0xc3, 0x0e, 0xb4, 0x94, 0xa8, 0xdc, 0x0d, 0xff, 0x6e, 0x40, 0xff, 0x22, 0x8d, 0x22, 0x12, 0xfe,
+	0xd7, 0xc1, 0x1f, 0x40, 0xd3, 0x0d, 0x03, 0x12, 0x71, 0x27, 0x97, 0x03, 0x50, 0xd0, 0x44, 0x64,
+	0xe2, 0x00, 0x9a, 0x5c, 0x9a, 0x54, 0x0b, 0x54, 0x36, 0x80, 0x6b, 0x2f, 0x3a, 0x55, 0x01, 0x0b,
+	0x78, 0x4c, 0x65, 0x46, 0xea, 0xf6, 0x42, 0x44, 0xef, 0x01, 0x30, 0xe2, 0x52, 0xc2, 0x9d, 0x2b,
+	0x72, 0x6b, 0x55, 0xe5, 0xce, 0x86, 0x42, 0x7e, 0x4e, 0x6e, 0xd1, 0x13, 0xa8, 0x26, 0x69, 0xe4,
+	0xfa, 0x56, 0x4d, 0x6e, 0x53, 0x82, 0x40, 0xa3, 0x38, 0x72, 0x89, 0xb5, 0x2d, 0xd7, 0x2b, 0x61,
+	0x78, 0x03, 0x7b, 0x6b, 0x81, 0xfd, 0xef, 0x39, 0x7e, 0x07, 0x1a, 0x09, 0x21, 0x34, 0x9f, 0xdf,
+	0xba, 0x00, 0x44, 0x6e, 0x97, 0x8e, 0x2b, 0x79, 0xc7, 0x7f, 0xad, 0x82, 0xf9, 0x92, 0xdc, 0x28,
+	0xe7, 0x8f, 0x9f, 0x4c, 0x91, 0x13, 0x51, 0xc0, 0xba, 0xb8, 0x94, 0x20, 0xb6, 0x91, 0xcf, 0x92,
+	0x98, 0x72, 0xc5, 0x5c, 0x65, 0x12, 0x14, 0x24, 0xb9, 0xaf, 0x15, 0x4f, 0x6d, 0xbd, 0x78, 0xd0,
+	0x73, 0xe8, 0xf9, 0x04, 0x87, 0xdc, 0x77, 0x5c, 0x9f, 0xb8, 0x57, 0x4e, 0x82, 0xb9, 0xaf, 0xb3,
+	0xdc, 0x55, 0x8a, 0x91, 0xc0, 0xcf, 0x31, 0xf7, 0xd1, 0x11, 0x34, 0x71, 0x18, 0xc6, 0x37, 0x8e,
+	0x1b, 0x78, 0x94, 0x59, 0x75, 0x19, 0x6a, 0x67, 0x7e, 0x77, 0x00, 0x27, 0x02, 0x1e, 0xbd, 0x18,
+	0xdb, 0xcc, 0x06, 0xb9, 0x64, 0x24, 0x56, 0x08, 0x8a, 0x6a, 0x83, 0xe0, 0xc4, 0xac, 0x86, 0xa2,
+	0x28, 0xa1, 0x73, 0x81, 0x88, 0x62, 0x48, 0x68, 0xfc, 0xd9, 0xad, 0x93, 0x32, 0x42, 0x2d, 0x50,
+	0xc5, 0x20, 0x91, 0x4f, 0x19, 0xa1, 0xe8, 0x5b, 0xd0, 0x51, 0xea, 0x04, 0x33, 0x76, 0x13, 0x53,
+	0xcf, 0x6a, 0xca, 0x25, 0x6d, 0x89, 0x9e, 0x6b, 0x10, 0x7d, 0x00, 0x8d, 0x30, 0x76, 0x71, 0xe8,
+	0xf0, 0x90, 0x59, 0x2d, 0x51, 0x37, 0xa7, 0xad, 0xf9, 0xdd, 0x41, 0xfd, 0x4c, 0x80, 0x17, 0x67,
+	0x53, 0xbb, 0x2e, 0xd5, 0x17, 0x21, 0x43, 0x3f, 0x86, 0x2e, 0x0f, 0x99, 0xc3, 0x08, 0x7d, 0x43,
+	0xa8, 0x13, 0xe1, 0x6b, 0x62, 0xb5, 0x65, 0x18, 0xbd, 0xf9, 0xdd, 0x41, 0xfb, 0xe2, 0x6c, 0x3a,
+	0x95, 0x9a, 0x97, 0xf8, 0x9a, 0xd8, 0x6d, 0x1e, 0xb2, 0xa5, 0x98, 0x6d, 0xbd, 0x0a, 0x12, 0xe7,
+	0x0d, 0xa1, 0xc1, 0xeb, 0x5b, 0xab, 0x23, 0x7d, 0x65, 0x5b, 0xaf, 0x82, 0xe4, 0x95, 0x54, 0xa8,
+	0xad, 0x99, 0x88, 0x9e, 0x41, 0x4d, 0x6c, 0x75, 0xb1, 0xd5, 0x95, 0xce, 0x1a, 0xf3, 0xbb, 0x83,
+	0xea, 0xc5, 0xd9, 0x74, 0x74, 0x62, 0x57, 0x79, 0xc8, 0x46, 0x18, 0x7d, 0x03, 0x5a, 0xaf, 0x83,
+	0x90, 0x38, 0x61, 0xc0, 0x78, 0x10, 0xcd, 0x2c, 0x53, 0x56, 0x7f, 0x53, 0x60, 0x67, 0x0a, 0x42,
+	0xdf, 0x86, 0xee, 0x25, 0x66, 0x81, 0xeb, 0xe0, 0x94, 0xfb, 0x2a, 0x61, 0x3d, 0x95, 0x0d, 0x09,
+	0x9f, 0xa4, 0xdc, 0x97, 0x49, 0x3b, 0x84, 0x9d, 0xdc, 0xba, 0x2c, 0x73, 0x48, 0xae, 0xed, 0x65,
+	0x6b, 0x17, 0xd9, 0x1b, 0x3a, 0xd0, 0xcb, 0xd5, 0xb2, 0xbe, 0x3f, 0x2b, 0x35, 0x69, 0xac, 0xd5,
+	0x64, 0xfe, 0x82, 0x95, 0xbf, 0xf2, 0x82, 0x0d, 0x23, 0x40, 0xa3, 0x30, 0x66, 0xe4, 0xff, 0x74,
+	0x5d, 0x86, 0x18, 0x76, 0x0a, 0xfe, 0x1e, 0x21, 0xa4, 0xbf, 0x19, 0xd0, 0x9d, 0xfa, 0x29, 0xf7,
+	0xe2, 0x9b, 0xe8, 0xad, 0x03, 0x7a, 0x17, 0x1a, 0x94, 0xb8, 0x71, 0x14, 0x11, 0x97, 0xcb, 0x70,
+	0xea, 0xf6, 0x12, 0x10, 0x77, 0x20, 0x13, 0xd4, 0x35, 0x56, 0x5d, 0xa0, 0x9d, 0xa1, 0xf2, 0x1e,
+	0x7f, 0x07, 0xba, 0xcb, 0x65, 0x1e, 0x09, 0xb1, 0xea, 0xad, 0x6d, 0x7b, 0xb9, 0x7b, 0x2c, 0x50,
+	0xd1, 0x99, 0x29, 0x61, 0x1c, 0x53, 0xae, 0x5b, 0xec, 0x42, 0x1c, 0xfe, 0xd1, 0x80, 0xd6, 0xb9,
+	0x68, 0xb7, 0x8f, 0xdf, 0xd1, 0x0a, 0x3d, 0x77, 0xeb, 0xa1, 0x9e, 0x5b, 0xcd, 0xf7, 0xdc, 0x3f,
+	0x19, 0xf0, 0x64, 0x4c, 0xdc, 0x10, 0x53, 0xe2, 0x65, 0x27, 0x9b, 0x86, 0x1c, 0x99, 0x50, 0xe1,
+	0x78, 0xa6, 0xcf, 0x53, 0x7c, 0xa2, 0x7d, 0xa8, 0x63, 0xd7, 0x25, 0x09, 0x27, 0x9e, 0x24, 0x57,
+	0xb7, 0x33, 0xf9, 0xeb, 0xa9, 0x1d, 0x40, 0x53, 0x77, 0x87, 0x1c, 0x39, 0x50, 0x90, 0xa4, 0xd7,
+	0x87, 0x1a, 0x25, 0x98, 0xc5, 0x91, 0xe6, 0xa7, 0xa5, 0xe1, 0x27, 0xd0, 0xd7, 0xfc, 0x14, 0x3d,
+	0x96, 0x55, 0xde, 0x0f, 0x65, 0xca, 0xd3, 0x90, 0x33, 0xcb, 0x78, 0x56, 0x79, 0xbf, 0x79, 0xfc,
+	0x8e, 0xae, 0xab, 0x4d, 0xf1, 0xd8, 0x8b, 0xb5, 0xc3, 0x3f, 0x18, 0xd0, 0x9a, 0xc8, 0x16, 0x6c,
+	0x13, 0xd1, 0x40, 0x1f, 0xf7, 0xb9, 0x56, 0xcd, 0xfe, 0x76, 0xf1, 0x5c, 0x6b, 0xf1, 0xc1, 0x68,
+	0xff, 0x62, 0x40, 0x77, 0x1c, 0xe0, 0xf0, 0x43, 0x4a, 0x63, 0xfa, 0xe8, 0xfc, 0xbe, 0x0b, 0x55,
+	0x37, 0xc4, 0x8c, 0x49, 0x76, 0x9d, 0xe3, 0xdd, 0x45, 0xfe, 0x16, 0x04, 0x46, 0x42, 0x69, 0xab,
+	0x35, 0x0f, 0x51, 0x16, 0x75, 0xe5, 0xc6, 0x69, 0xa4, 0xea, 0xbe, 0x6d, 0x2b, 0x61, 0xf8, 0x7b,
+	0x03, 0x9a, 0xba, 0xea, 0x1f, 0x39, 0x08, 0x31, 0x24, 0x06, 0x54, 0x5c, 0x72, 0x95, 0x63, 0x2d,
+	0x3d, 0x98, 0xe2, 0x5f, 0x43, 0xa7, 0x58, 0x20, 0x1b, 0x4a, 0x3d, 0x1b, 0x0d, 0xca, 0x5f, 0x31,
+	0x1a, 0x54, 0xd6, 0x46, 0x83, 0xaf, 0x2b, 0xf2, 0xe1, 0x6f, 0x0d, 0xd8, 0x5d, 0xad, 0xe6, 0xb7,
+	0x6c, 0x0a, 0x47, 0xb0, 0xad, 0x92, 0xc1, 0xac, 0x8a, 0xbc, 0x05, 0xbb, 0x9b, 0x6f, 0xc1, 0x62,
+	0xd5, 0xf0, 0xdf, 0x0d, 0xd8, 0xfe, 0x98, 0x30, 0x86, 0x67, 0x04, 0x7d, 0x04, 0x3d, 0x7f, 0x31,
+	0xe4, 0x39, 0x54, 0x51, 0x91, 0x24, 0x9a, 0xc7, 0x7b, 0xda, 0xcc, 0xea, 0x74, 0x3b, 0x29, 0xd9,
+	0xa6, 0xbf, 0x82, 0xa1, 0x17, 0x80, 0xf2, 0x76, 0xd4, 0x05, 0x95, 0x64, 0x9b, 0xc7, 0xd6, 0xba,
+	0x21, 0xa5, 0x9f, 0x94, 0xec, 0x9e, 0xbf, 0x0a, 0xa2, 0x5f, 0x80, 0x95, 0x9d, 0xf7, 0x2a, 0xb3,
+	0x8a, 0x34, 0xf8, 0x9e, 0x36, 0xb8, 0x79, 0xfa, 0x9e, 0x94, 0xec, 0x3e, 0xdf, 0xa8, 0x41, 0xbf,
+	0x84, 0xa7, 0x1b, 0x4c, 0x6b, 0xb2, 0x5b, 0xd2, 0xf6, 0xe0, 0x21, 0xdb, 0x19, 0xe5, 0x3d, 0xbe,
+	0x59, 0x85, 0x7e, 0x04, 0xad, 0x24, 0x88, 0x66, 0x19, 0xd9, 0xaa, 0x34, 0x88, 0xb4, 0xc1, 0xdc,
+	0x4f, 0x89, 0x49, 0xc9, 0x6e, 0x26, 0x4b, 0x11, 0xfd, 0x04, 0xda, 0x7a, 0xa3, 0xa6, 0x52, 0x93,
+	0x3b, 0x77, 0x0a, 0x3b, 0x33, 0xff, 0xad, 0x24, 0x27, 0xa3, 0x9f, 0x02, 0x8a, 0xc8, 0x8d, 0xa3,
+	0xc3, 0x5a, 0xb8, 0xde, 0x2e, 0x9c, 0xe0, 0xea, 0x48, 0x2d, 0x4e, 0x30, 0x5a, 0xc1, 0xd0, 0xcf,
+	0x60, 0xa7, 0x60, 0x48, 0x53, 0xa9, 0x17, 0x8e, 0x70, 0x6d, 0xa0, 0x11, 0x47, 0x18, 0xad, 0x82,
+	0xe8, 0x63, 0x78, 0xe2, 0x8a, 0x49, 0x61, 0x95, 0x56, 0x43, 0x1a, 0x7b, 0xaa, 0x8d, 0xad, 0x0f,
+	0x2f, 0x93, 0x92, 0x8d, 0xdc, 0x35, 0x14, 0x9d, 0xc3, 0xee, 0x8a, 0x39, 0x4d, 0x0e, 0xa4, 0xbd,
+	0xfd, 0x4d, 0xf6, 0x32, 0x7a, 0x3b, 0xee, 0x3a, 0x8c, 0x46, 0x60, 0x32, 0x3d, 0x66, 0x64, 0xe4,
+	0x9a, 0xd2, 0x58, 0x5f, 0x1b, 0x5b, 0x99, 0x42, 0x26, 0x25, 0xbb, 0xcb, 0x8a, 0x90, 0x38, 0x36,
+	0x3d, 0xe2, 0x53, 0xd9, 0xe2, 0xac, 0x56, 0xe1, 0xd8, 0xf2, 0x4f, 0x8c, 0x38, 0x36, 0x3f, 0x27,
+	0xa3, 0x31, 0xf4, 0xbc, 0x00, 0x87, 0x0e, 0x11, 0x5d, 0x76, 0xb1, 0xbf, 0x5d, 0x60, 0xb0, 0xf2,
+	0x0a, 0x08, 0x06, 0x5e, 0x11, 0x92, 0x85, 0x23, 0x5a, 0x6c, 0x16, 0x43, 0xa7, 0x58, 0x38, 0xb9,
+	0xa1, 0x43, 0x16, 0x4e, 0x4e, 0x96, 0xd5, 0xaa, 0xf7, 0x4a, 0xe7, 0xdd, 0x62, 0xb5, 0x2e, 0x3b,
+	0xb7, 0xac, 0xd6, 0xa5, 0x88, 0x5e, 0xc1, 0x9e, 0xa7, 0x3a, 0x8b, 0x3e, 0x0f, 0x96, 0xb9, 0x37,
+	0xa5, 0x8d, 0x77, 0x8b, 0xfd, 0xa7, 0xd8, 0xe7, 0x26, 0x25, 0x7b, 0xd7, 0xdb, 0xa4, 0x10, 0xf7,
+	0x7e, 0xdd, 0xae, 0x3e, 0xe8, 0x5e, 0xe1, 0xde, 0x6f, 0x1e, 0x07, 0xc4, 0xbd, 0xf7, 0x36, 0x6a,
+	0x4e, 0x6b, 0xb0, 0x75, 0x19, 0x7b, 0xb7, 0xcf, 0x7f, 0x57, 0x86, 0x6d, 0x3d, 0x73, 0xa2, 0x2e,
+	0x34, 0xf5, 0xe7, 0xcb, 0x34, 0x0c, 0xcd, 0x12, 0x7a, 0x02, 0xa6, 0x06, 0x4e, 0xb1, 0x37, 0x92,
+	0xfd, 0xd5, 0x34, 0xd0, 0x2e, 0xf4, 0x96, 0xe8, 0x2b, 0xf5, 0x6f, 0x09, 0xb3, 0x8c, 0x9e, 0xc2,
+	0xee, 0x12, 0x3e, 0x17, 0x8f, 0xc3, 0x27, 0xb2, 0xc1, 0x9b, 0x15, 0xb4, 0x0f, 0xfd, 0xa5, 0xca,
+	0xce, 0xfd, 0x26, 0x34, 0xb7, 0xd0, 0x1e, 0xec, 0x2c, 0x9c, 0xc6, 0xd3, 0xd4, 0xf5, 0x15, 0x51,
+	0xb3, 0x9a, 0xb3, 0x37, 0x4e, 0x93, 0x30, 0x70, 0x31, 0x27, 0x27, 0x33, 0xc1, 0xa0, 0x86, 0x06,
+	0xb0, 0xaf, 0x55, 0x2f, 0x22, 0x4e, 0x68, 0x84, 0x43, 0xf5, 0xe3, 0x49, 0x16, 0x82, 0xb9, 0x9d,
+	0xb3, 0x79, 0x8a, 0xbd, 0xe9, 0xe2, 0x07, 0xbf, 0x59, 0x47, 0x7d, 0x40, 0x5a, 0x21, 0x4f, 0xf3,
+	0x23, 0x1c, 0x84, 0xc4, 0x33, 0x1b, 0xcf, 0x3d, 0xe8, 0x14, 0x1f, 0x78, 0x11, 0x7a, 0x86, 0x7c,
+	0x1a, 0x5d, 0x45, 0xf1, 0x4d, 0x64, 0x96, 0x0a, 0xa8, 0x4d, 0x5e, 0xa7, 0x8c, 0x78, 0xa6, 0x51,
+	0x40, 0x2f, 0x82, 0x6b, 0x12, 0xa7, 0xdc, 0x2c, 0x23, 0x13, 0x5a, 0x19, 0x3a, 0x7e, 0x39, 0x35,
+	0x2b, 0xa7, 0xdf, 0xfb, 0xfc, 0x7e, 0x50, 0xfa, 0xe2, 0x7e, 0x50, 0xfa, 0xc7, 0xfd, 0xa0, 0xf4,
+	0xe5, 0xfd, 0xc0, 0xf8, 0xcd, 0x7c, 0x60, 0xfc, 0x79, 0x3e, 0x30, 0x3e, 0x9f, 0x0f, 0x8c, 0x2f,
+	0xe6, 0x03, 0xe3, 0x9f, 0xf3, 0x81, 0xf1, 0xaf, 0xf9, 0xa0, 0xf4, 0xe5, 0x7c, 0x60, 0x5c, 0xd6,
+	0xe4, 0x03, 0xfb, 0x83, 0xff, 0x0c, 0x00, 0xb6, 0x04, 0x00, 0xb4, 0x96, 0x12, 0x00, 0x00,
 }
@@ -0,0 +1,118 @@
+// Package allowlist matches destinations against CIDRs and port ranges.
+package allowlist
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+type portRange struct {
+	min, max int
+}
+
+// Allowlist allows the destinations which are in one of the networks
+// and one of the port ranges, all ports are allowed if no range provided.
+type Allowlist struct {
+	nets  []*net.IPNet
+	ports []portRange
+}
+
+// Parse parses the comma separated CIDRs and ports, e.g. "10.0.0.0/8,192.168.1.1"
+// and "22,80,8000-9000", a single IP is treated as a host network.
+func Parse(cidrs, ports string) (*Allowlist, error) {
+	al := &Allowlist{}
+	for _, s := range split(cidrs) {
+		if !strings.Contains(s, "/") {
+			ip := net.ParseIP(s)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP: %s", s)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				bits = 8 * net.IPv4len
+			}
+			s = fmt.Sprintf("%s/%d", s, bits)
+		}
+		_, ipnet, err := net.ParseCIDR(s)
+		if err != nil {
+			return nil, err
+		}
+		al.nets = append(al.nets, ipnet)
+	}
+	if len(al.nets) == 0 {
+		return nil, fmt.Errorf("at least one CIDR is required")
+	}
+
+	for _, s := range split(ports) {
+		pr, err := parsePortRange(s)
+		if err != nil {
+			return nil, err
+		}
+		al.ports = append(al.ports, pr)
+	}
+	return al, nil
+}
+
+func split(s string) []string {
+	var ss []string
+	for _, e := range strings.Split(s, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			ss = append(ss, e)
+		}
+	}
+	return ss
+}
+
+func parsePortRange(s string) (portRange, error) {
+	bounds := strings.SplitN(s, "-", 2)
+	min, err := parsePort(bounds[0])
+	if err != nil {
+		return portRange{}, err
+	}
+	max := min
+	if len(bounds) == 2 {
+		if max, err = parsePort(bounds[1]); err != nil {
+			return portRange{}, err
+		}
+	}
+	if min > max {
+		return portRange{}, fmt.Errorf("invalid port range: %s", s)
+	}
+	return portRange{min: min, max: max}, nil
+}
+
+func parsePort(s string) (int, error) {
+	port, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || port < 1 || port > 65535 {
+		return 0, fmt.Errorf("invalid port: %s", s)
+	}
+	return port, nil
+}
+
+// Allow reports whether the ip and port is allowed.
+func (al *Allowlist) Allow(ip net.IP, port int) bool {
+	return al.allowIP(ip) && al.allowPort(port)
+}
+
+func (al *Allowlist) allowIP(ip net.IP) bool {
+	for _, ipnet := range al.nets {
+		if ipnet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func (al *Allowlist) allowPort(port int) bool {
+	if len(al.ports) == 0 {
+		return true
+	}
+	for _, pr := range al.ports {
+		if port >= pr.min && port <= pr.max {
+			return true
+		}
+	}
+	return false
+}
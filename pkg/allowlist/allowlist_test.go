@@ -0,0 +1,54 @@
+package allowlist
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	for _, c := range []struct {
+		cidrs string
+		ports string
+	}{
+		{"", ""},
+		{"10.0.0.0/33", ""},
+		{"10.0.0.x", ""},
+		{"10.0.0.0/8", "0"},
+		{"10.0.0.0/8", "65536"},
+		{"10.0.0.0/8", "90-80"},
+		{"10.0.0.0/8", "a-b"},
+	} {
+		_, err := Parse(c.cidrs, c.ports)
+		assert.NotNil(t, err, "%+v", c)
+	}
+}
+
+func TestAllow(t *testing.T) {
+	al, err := Parse("10.0.0.0/8, 192.168.1.1,fd00::/8", "22, 80,8000-9000")
+	require.Nil(t, err)
+
+	for _, c := range []struct {
+		ip    string
+		port  int
+		allow bool
+	}{
+		{"10.1.2.3", 22, true},
+		{"10.1.2.3", 8500, true},
+		{"10.1.2.3", 9001, false},
+		{"192.168.1.1", 80, true},
+		{"192.168.1.2", 80, false},
+		{"fd00::1", 80, true},
+		{"fe80::1", 80, false},
+		{"127.0.0.1", 22, false},
+	} {
+		assert.Equal(t, c.allow, al.Allow(net.ParseIP(c.ip), c.port), "%+v", c)
+	}
+
+	al, err = Parse("127.0.0.1", "")
+	require.Nil(t, err)
+	assert.True(t, al.Allow(net.ParseIP("127.0.0.1"), 65535))
+	assert.False(t, al.Allow(net.ParseIP("127.0.0.2"), 1))
+}
@@ -0,0 +1,184 @@
+// Package connmux serves several protocols on a single listener, the protocol
+// of a connection is detected from the first bytes it sends.
+package connmux
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+var errClosed = fmt.Errorf("listener already closed")
+
+// Matcher reports whether the connection speaks the protocol, r replays
+// the bytes read by previous matchers.
+type Matcher func(r io.Reader) bool
+
+// Head matches the first n bytes.
+func Head(n int, match func(head []byte) bool) Matcher {
+	return func(r io.Reader) bool {
+		head := make([]byte, n)
+		if _, err := io.ReadFull(r, head); err != nil {
+			return false
+		}
+		return match(head)
+	}
+}
+
+// TLS matches the TLS handshake record, which starts with a ClientHello.
+func TLS() Matcher {
+	return Head(2, func(head []byte) bool {
+		return head[0] == 0x16 && head[1] == 0x03
+	})
+}
+
+// HTTP matches the HTTP/1.x requests whose host is accepted by match,
+// all hosts are accepted if match is nil.
+func HTTP(match func(host string) bool) Matcher {
+	return func(r io.Reader) bool {
+		req, err := http.ReadRequest(bufio.NewReader(r))
+		if err != nil {
+			return false
+		}
+		if match == nil {
+			return true
+		}
+		host := req.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		return match(strings.ToLower(host))
+	}
+}
+
+// Mux dispatches the connections accepted from the root listener to the
+// listener of the first matched protocol, the unmatched ones are closed.
+type Mux struct {
+	root    net.Listener
+	timeout time.Duration
+	routes  []route
+	closed  chan struct{}
+	once    sync.Once
+}
+
+type route struct {
+	match Matcher
+	l     *listener
+}
+
+// New creates a Mux, timeout limits the time to detect the protocol.
+func New(root net.Listener, timeout time.Duration) *Mux {
+	return &Mux{
+		root:    root,
+		timeout: timeout,
+		closed:  make(chan struct{}),
+	}
+}
+
+// Match returns a listener which accepts the connections matched by match,
+// the matchers are tried in the order they are added, it must be called
+// before Serve.
+func (m *Mux) Match(match Matcher) net.Listener {
+	l := &listener{
+		mux:    m,
+		connCh: make(chan net.Conn),
+		closed: make(chan struct{}),
+	}
+	m.routes = append(m.routes, route{match: match, l: l})
+	return l
+}
+
+// Addr returns the address of root listener.
+func (m *Mux) Addr() net.Addr {
+	return m.root.Addr()
+}
+
+func (m *Mux) Serve() error {
+	for {
+		conn, err := m.root.Accept()
+		if err != nil {
+			select {
+			case <-m.closed:
+				return nil
+			default:
+			}
+			return err
+		}
+		go m.ServeConn(conn)
+	}
+}
+
+// ServeConn detects the protocol of conn, then hands it to the matched
+// listener, it is useful to detect again after TLS terminated.
+func (m *Mux) ServeConn(conn net.Conn) {
+	conn.SetReadDeadline(time.Now().Add(m.timeout))
+	buf := &bytes.Buffer{}
+	for _, r := range m.routes {
+		if !r.match(io.MultiReader(bytes.NewReader(buf.Bytes()), io.TeeReader(conn, buf))) {
+			continue
+		}
+		conn.SetReadDeadline(time.Time{})
+		r.l.put(&replayConn{Conn: conn, r: io.MultiReader(buf, conn)})
+		return
+	}
+	conn.Close()
+}
+
+func (m *Mux) Close() error {
+	m.once.Do(func() { close(m.closed) })
+	return m.root.Close()
+}
+
+type listener struct {
+	mux    *Mux
+	connCh chan net.Conn
+	closed chan struct{}
+	once   sync.Once
+}
+
+func (l *listener) put(conn net.Conn) {
+	select {
+	case l.connCh <- conn:
+	case <-l.closed:
+		conn.Close()
+	case <-l.mux.closed:
+		conn.Close()
+	}
+}
+
+func (l *listener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.connCh:
+		return conn, nil
+	case <-l.closed:
+		return nil, errClosed
+	case <-l.mux.closed:
+		return nil, errClosed
+	}
+}
+
+// Close stops accepting connections, the root listener is not closed.
+func (l *listener) Close() error {
+	l.once.Do(func() { close(l.closed) })
+	return nil
+}
+
+func (l *listener) Addr() net.Addr {
+	return l.mux.Addr()
+}
+
+// replayConn reads the bytes consumed by matchers first.
+type replayConn struct {
+	net.Conn
+	r io.Reader
+}
+
+func (c *replayConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
@@ -0,0 +1,159 @@
+package connmux
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func serveEcho(l net.Listener, name string) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer conn.Close()
+			line, err := bufio.NewReader(conn).ReadString('\n')
+			if err != nil {
+				return
+			}
+			fmt.Fprintf(conn, "%s:%s", name, line)
+		}()
+	}
+}
+
+func request(t *testing.T, addr string, data string) string {
+	conn, err := net.Dial("tcp", addr)
+	require.Nil(t, err)
+	defer conn.Close()
+	_, err = io.WriteString(conn, data)
+	require.Nil(t, err)
+	resp, _ := io.ReadAll(conn)
+	return string(resp)
+}
+
+func TestMux(t *testing.T) {
+	root, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	m := New(root, 200*time.Millisecond)
+	defer m.Close()
+
+	go serveEcho(m.Match(Head(3, func(head []byte) bool { return string(head) == "abc" })), "abc")
+	go serveEcho(m.Match(HTTP(func(host string) bool { return strings.HasSuffix(host, ".sunflower.test") })), "sub")
+	go serveEcho(m.Match(HTTP(nil)), "http")
+	go serveEcho(m.Match(TLS()), "tls")
+	go m.Serve()
+	addr := m.Addr().String()
+
+	assert.Equal(t, "abc:abcdef\n", request(t, addr, "abcdef\n"))
+	assert.Equal(t, "sub:GET / HTTP/1.1\r\n", request(t, addr, "GET / HTTP/1.1\r\nHost: x.Sunflower.test:80\r\n\r\n"))
+	assert.Equal(t, "http:GET / HTTP/1.1\r\n", request(t, addr, "GET / HTTP/1.1\r\nHost: sunflower.test\r\n\r\n"))
+	assert.Equal(t, "tls:\x16\x03\x01\n", request(t, addr, "\x16\x03\x01\n"))
+	// Unknown protocols are closed.
+	assert.Equal(t, "", request(t, addr, "xyz\n"))
+}
+
+func TestListenerClose(t *testing.T) {
+	root, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	m := New(root, 200*time.Millisecond)
+	l := m.Match(HTTP(nil))
+	assert.Equal(t, root.Addr(), l.Addr())
+
+	require.Nil(t, l.Close())
+	_, err = l.Accept()
+	assert.NotNil(t, err)
+
+	l = m.Match(TLS())
+	errCh := make(chan error, 1)
+	go func() { errCh <- m.Serve() }()
+	require.Nil(t, m.Close())
+	assert.Nil(t, <-errCh)
+	_, err = l.Accept()
+	assert.NotNil(t, err)
+}
+
+func TestHTTPServer(t *testing.T) {
+	root, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	m := New(root, time.Second)
+	defer m.Close()
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, r.Host)
+	})}
+	go server.Serve(m.Match(HTTP(nil)))
+	defer server.Close()
+	go m.Serve()
+
+	resp, err := http.Get("http://" + m.Addr().String() + "/")
+	require.Nil(t, err)
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, m.Addr().String(), string(body))
+}
+
+func TestSameHost(t *testing.T) {
+	root, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	m := New(root, time.Second)
+	defer m.Close()
+	serve := func(l net.Listener, name string) {
+		server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			fmt.Fprintf(w, "%s:%s:%s", name, r.Host, body)
+		})}
+		go server.Serve(SameHost(l))
+		t.Cleanup(func() { server.Close() })
+	}
+	serve(m.Match(HTTP(func(host string) bool { return strings.HasSuffix(host, ".sunflower.test") })), "sub")
+	serve(m.Match(HTTP(nil)), "http")
+	go m.Serve()
+
+	conn, err := net.Dial("tcp", m.Addr().String())
+	require.Nil(t, err)
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	br := bufio.NewReader(conn)
+	roundTrip := func(req string) *http.Response {
+		_, err := io.WriteString(conn, req)
+		require.Nil(t, err)
+		resp, err := http.ReadResponse(br, nil)
+		require.Nil(t, err)
+		return resp
+	}
+	readBody := func(resp *http.Response) string {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+
+	resp := roundTrip("GET / HTTP/1.1\r\nHost: a.sunflower.test\r\n\r\n")
+	assert.Equal(t, "sub:a.sunflower.test:", readBody(resp))
+	resp = roundTrip("POST / HTTP/1.1\r\nHost: a.sunflower.test\r\nContent-Length: 3\r\n\r\nabc")
+	assert.Equal(t, "sub:a.sunflower.test:abc", readBody(resp))
+	resp = roundTrip("POST / HTTP/1.1\r\nHost: A.sunflower.test:80\r\nTransfer-Encoding: chunked\r\n\r\n3\r\nabc\r\n2;x=y\r\nde\r\n0\r\nX-Trailer: 1\r\n\r\n")
+	assert.Equal(t, "sub:A.sunflower.test:80:abcde", readBody(resp))
+
+	// The request for another host is sent again over a new connection.
+	resp = roundTrip("GET /panel?x=1 HTTP/1.1\r\nHost: sunflower.test\r\n\r\n")
+	readBody(resp)
+	assert.Equal(t, http.StatusTemporaryRedirect, resp.StatusCode)
+	assert.Equal(t, "/panel?x=1", resp.Header.Get("Location"))
+	assert.True(t, resp.Close)
+	_, err = br.ReadByte()
+	assert.Equal(t, io.EOF, err)
+
+	resp, err = http.ReadResponse(bufio.NewReader(strings.NewReader(
+		request(t, m.Addr().String(), "GET /panel?x=1 HTTP/1.1\r\nHost: sunflower.test\r\nConnection: close\r\n\r\n"))), nil)
+	require.Nil(t, err)
+	assert.Equal(t, "http:sunflower.test:", readBody(resp))
+}
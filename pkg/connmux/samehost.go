@@ -0,0 +1,212 @@
+package connmux
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	maxHeaderBytes = http.DefaultMaxHeaderBytes
+	misdirected    = "HTTP/1.1 307 Temporary Redirect\r\nLocation: %s\r\nConnection: close\r\nContent-Length: 0\r\n\r\n"
+)
+
+// SameHost wraps the listener of HTTP/1.x connections, which are routed by
+// the host of the first request. The requests are passed until one asks for
+// another host, then the connection ends with a redirect to the same URL,
+// so that the client sends it over a new connection, which is routed again.
+func SameHost(l net.Listener) net.Listener {
+	return &sameHostListener{Listener: l}
+}
+
+type sameHostListener struct {
+	net.Listener
+}
+
+func (l *sameHostListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &sameHostConn{Conn: conn, br: bufio.NewReader(conn)}, nil
+}
+
+// sameHostConn passes the bytes as they are, it only follows the framing
+// of requests, the partial data is kept if reading fails, e.g. timed out.
+type sameHostConn struct {
+	net.Conn
+	br      *bufio.Reader
+	host    string
+	line    []byte // The partial line
+	head    []byte // The partial header
+	out     []byte // The bytes checked but not read yet
+	remain  int64  // The bytes of body left, -1 if passes all
+	chunked bool
+	trailer bool
+
+	mu       sync.Mutex
+	location string // Not empty if a request asks for another host
+	once     sync.Once
+}
+
+func (c *sameHostConn) Read(b []byte) (int, error) {
+	for len(c.out) == 0 {
+		if c.misdirected() {
+			return 0, io.EOF
+		}
+		if c.remain != 0 {
+			if c.remain > 0 && int64(len(b)) > c.remain {
+				b = b[:c.remain]
+			}
+			n, err := c.br.Read(b)
+			if c.remain > 0 {
+				c.remain -= int64(n)
+			}
+			return n, err
+		}
+		var err error
+		if c.chunked {
+			err = c.readChunk()
+		} else {
+			err = c.readHeader()
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+	n := copy(b, c.out)
+	c.out = c.out[n:]
+	return n, nil
+}
+
+func (c *sameHostConn) readLine() ([]byte, error) {
+	for {
+		frag, err := c.br.ReadSlice('\n')
+		c.line = append(c.line, frag...)
+		if err == nil {
+			line := c.line
+			c.line = nil
+			return line, nil
+		}
+		if err != bufio.ErrBufferFull {
+			return nil, err
+		}
+		if len(c.line) > maxHeaderBytes {
+			// Let the server reject it.
+			c.out, c.line, c.remain = c.line, nil, -1
+			return nil, nil
+		}
+	}
+}
+
+func (c *sameHostConn) readChunk() error {
+	line, err := c.readLine()
+	if line == nil {
+		return err
+	}
+	c.out = line
+	if c.trailer {
+		if isBlank(line) {
+			c.chunked, c.trailer = false, false
+		}
+		return nil
+	}
+	size := strings.TrimSpace(string(line))
+	if i := strings.IndexByte(size, ';'); i >= 0 {
+		size = size[:i]
+	}
+	n, err := strconv.ParseInt(size, 16, 64)
+	if err != nil || n < 0 {
+		c.remain = -1 // Let the server reject it.
+		return nil
+	}
+	if n == 0 {
+		c.trailer = true
+	} else {
+		c.remain = n + 2 // With CRLF
+	}
+	return nil
+}
+
+func (c *sameHostConn) readHeader() error {
+	for {
+		line, err := c.readLine()
+		if line == nil {
+			return err
+		}
+		if len(c.head) == 0 && isBlank(line) {
+			c.out = line
+			return nil
+		}
+		c.head = append(c.head, line...)
+		if len(c.head) > maxHeaderBytes {
+			c.out, c.head, c.remain = c.head, nil, -1
+			return nil
+		}
+		if isBlank(line) {
+			break
+		}
+	}
+	head := c.head
+	c.head = nil
+	req, err := http.ReadRequest(bufio.NewReader(bytes.NewReader(head)))
+	if err != nil {
+		c.out, c.remain = head, -1
+		return nil
+	}
+	host := req.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(host)
+	if c.host == "" {
+		c.host = host
+	} else if host != c.host {
+		c.mu.Lock()
+		c.location = req.RequestURI
+		c.mu.Unlock()
+		return io.EOF
+	}
+
+	c.out = head
+	switch {
+	case req.Method == http.MethodConnect || req.Header.Get("Upgrade") != "":
+		c.remain = -1 // It is not HTTP any more if upgraded.
+	case len(req.TransferEncoding) > 0 && req.TransferEncoding[0] == "chunked":
+		c.chunked = true
+	case req.ContentLength > 0:
+		c.remain = req.ContentLength
+	}
+	return nil
+}
+
+func (c *sameHostConn) misdirected() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.location != ""
+}
+
+// Close tells the client to send the request for another host again.
+func (c *sameHostConn) Close() error {
+	c.once.Do(func() {
+		c.mu.Lock()
+		location := c.location
+		c.mu.Unlock()
+		if location != "" {
+			c.Conn.SetWriteDeadline(time.Now().Add(time.Second))
+			fmt.Fprintf(c.Conn, misdirected, location)
+		}
+	})
+	return c.Conn.Close()
+}
+
+func isBlank(line []byte) bool {
+	return len(line) <= 2 && len(bytes.TrimRight(line, "\r\n")) == 0
+}
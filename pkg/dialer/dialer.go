@@ -0,0 +1,321 @@
+// Package dialer connects to the remote address directly, or through
+// a HTTP CONNECT or SOCKS5 proxy.
+package dialer
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Direct connects without proxy, the same as empty.
+	Direct = "direct"
+	// Env uses the proxy from environment variables, see FromEnvironment,
+	// it is not the default since NO_PROXY is not supported.
+	Env = "env"
+)
+
+// DialFunc connects to the address in timeout, the timeout includes
+// the handshake with proxy.
+type DialFunc func(network, address string, timeout time.Duration) (net.Conn, error)
+
+// FromEnvironment returns the proxy URL from environment variables,
+// ALL_PROXY takes precedence over HTTPS_PROXY and HTTP_PROXY.
+func FromEnvironment() string {
+	for _, key := range []string{"ALL_PROXY", "HTTPS_PROXY", "HTTP_PROXY"} {
+		if v := os.Getenv(key); v != "" {
+			return v
+		}
+		if v := os.Getenv(strings.ToLower(key)); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// New creates a DialFunc from the proxy URL, it is one of:
+//   - http://[user:password@]host[:port]
+//   - https://[user:password@]host[:port]
+//   - socks5://[user:password@]host[:port]
+//
+// The connections are direct if the URL is empty or Direct.
+func New(rawurl string) (DialFunc, error) {
+	if rawurl == "" || rawurl == Direct {
+		return net.DialTimeout, nil
+	}
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("no host in proxy URL: %s", Redact(rawurl))
+	}
+
+	var d proxyDialer
+	switch u.Scheme {
+	case "http":
+		d = &httpDialer{addr: withPort(u, "80"), user: u.User}
+	case "https":
+		d = &httpDialer{addr: withPort(u, "443"), user: u.User, tlsConf: &tls.Config{ServerName: u.Hostname()}}
+	case "socks5", "socks5h":
+		d = &socks5Dialer{addr: withPort(u, "1080"), user: u.User}
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme: %s", u.Scheme)
+	}
+	return func(network, address string, timeout time.Duration) (net.Conn, error) {
+		return dial(d, network, address, timeout)
+	}, nil
+}
+
+// Redact masks the password in proxy URL.
+func Redact(rawurl string) string {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return rawurl
+	}
+	return u.Redacted()
+}
+
+func withPort(u *url.URL, port string) string {
+	if u.Port() != "" {
+		return u.Host
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
+
+type proxyDialer interface {
+	// connect asks the proxy to connect to the address, it returns
+	// the connection to the remote through proxy.
+	connect(conn net.Conn, address string) (net.Conn, error)
+	proxyAddr() string
+}
+
+func dial(d proxyDialer, network, address string, timeout time.Duration) (net.Conn, error) {
+	switch network {
+	case "tcp", "tcp4", "tcp6":
+	default:
+		return nil, fmt.Errorf("unsupported network through proxy: %s", network)
+	}
+
+	deadline := time.Now().Add(timeout)
+	conn, err := net.DialTimeout("tcp", d.proxyAddr(), timeout)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetDeadline(deadline)
+	remoteConn, err := d.connect(conn, address)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("proxy %s: %v", d.proxyAddr(), err)
+	}
+	remoteConn.SetDeadline(time.Time{})
+	return remoteConn, nil
+}
+
+// readerConn reads from r, which buffers the data read from conn.
+type readerConn struct {
+	net.Conn
+	r io.Reader
+}
+
+func (c *readerConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
+
+type httpDialer struct {
+	addr    string
+	user    *url.Userinfo
+	tlsConf *tls.Config // Not nil if speaks TLS with proxy
+}
+
+func (d *httpDialer) proxyAddr() string {
+	return d.addr
+}
+
+func (d *httpDialer) connect(conn net.Conn, address string) (net.Conn, error) {
+	if d.tlsConf != nil {
+		tlsConn := tls.Client(conn, d.tlsConf)
+		if err := tlsConn.Handshake(); err != nil {
+			return nil, err
+		}
+		conn = tlsConn
+	}
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: address},
+		Host:   address,
+		Header: http.Header{},
+	}
+	if d.user != nil {
+		password, _ := d.user.Password()
+		auth := base64.StdEncoding.EncodeToString([]byte(d.user.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+auth)
+	}
+	if err := req.Write(conn); err != nil {
+		return nil, err
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s", resp.Status)
+	}
+	if br.Buffered() == 0 {
+		return conn, nil
+	}
+	return &readerConn{Conn: conn, r: br}, nil
+}
+
+const (
+	socks5Version       = 0x05
+	socks5AuthVersion   = 0x01
+	socks5MethodNoAuth  = 0x00
+	socks5MethodUserPwd = 0x02
+	socks5CmdConnect    = 0x01
+	socks5AtypIPv4      = 0x01
+	socks5AtypDomain    = 0x03
+	socks5AtypIPv6      = 0x04
+	socks5Succeeded     = 0x00
+)
+
+var socks5Replies = map[byte]string{
+	0x01: "general failure",
+	0x02: "connection not allowed by ruleset",
+	0x03: "network unreachable",
+	0x04: "host unreachable",
+	0x05: "connection refused",
+	0x06: "TTL expired",
+	0x07: "command not supported",
+	0x08: "address type not supported",
+}
+
+// socks5Dialer implements the client side of RFC 1928 and RFC 1929,
+// the domain name is resolved by proxy.
+type socks5Dialer struct {
+	addr string
+	user *url.Userinfo
+}
+
+func (d *socks5Dialer) proxyAddr() string {
+	return d.addr
+}
+
+func (d *socks5Dialer) connect(conn net.Conn, address string) (net.Conn, error) {
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port < 1 || port > 0xffff {
+		return nil, fmt.Errorf("invalid port: %s", portStr)
+	}
+
+	method := byte(socks5MethodNoAuth)
+	if d.user != nil {
+		method = socks5MethodUserPwd
+	}
+	if _, err := conn.Write([]byte{socks5Version, 1, method}); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 2, 263)
+	if _, err := io.ReadFull(conn, buf); err != nil {
+		return nil, err
+	}
+	if buf[0] != socks5Version {
+		return nil, fmt.Errorf("unexpected SOCKS version: %d", buf[0])
+	}
+	if buf[1] != method {
+		return nil, fmt.Errorf("no acceptable authentication methods")
+	}
+	if method == socks5MethodUserPwd {
+		if err := d.authenticate(conn); err != nil {
+			return nil, err
+		}
+	}
+
+	req := []byte{socks5Version, socks5CmdConnect, 0}
+	if ip := net.ParseIP(host); ip == nil {
+		if len(host) > 255 {
+			return nil, fmt.Errorf("host name too long: %s", host)
+		}
+		req = append(req, socks5AtypDomain, byte(len(host)))
+		req = append(req, host...)
+	} else if ip4 := ip.To4(); ip4 != nil {
+		req = append(req, socks5AtypIPv4)
+		req = append(req, ip4...)
+	} else {
+		req = append(req, socks5AtypIPv6)
+		req = append(req, ip...)
+	}
+	req = append(req, byte(port>>8), byte(port))
+	if _, err := conn.Write(req); err != nil {
+		return nil, err
+	}
+
+	buf = buf[:4]
+	if _, err := io.ReadFull(conn, buf); err != nil {
+		return nil, err
+	}
+	if buf[1] != socks5Succeeded {
+		if reason, ok := socks5Replies[buf[1]]; ok {
+			return nil, fmt.Errorf("%s", reason)
+		}
+		return nil, fmt.Errorf("unknown reply: %d", buf[1])
+	}
+	// Skip the bound address.
+	var n int
+	switch buf[3] {
+	case socks5AtypIPv4:
+		n = net.IPv4len
+	case socks5AtypIPv6:
+		n = net.IPv6len
+	case socks5AtypDomain:
+		if _, err := io.ReadFull(conn, buf[:1]); err != nil {
+			return nil, err
+		}
+		n = int(buf[0])
+	default:
+		return nil, fmt.Errorf("unknown address type: %d", buf[3])
+	}
+	if _, err := io.ReadFull(conn, buf[:n+2]); err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
+
+func (d *socks5Dialer) authenticate(conn net.Conn) error {
+	username := d.user.Username()
+	password, _ := d.user.Password()
+	if len(username) == 0 || len(username) > 255 || len(password) > 255 {
+		return fmt.Errorf("invalid username or password length")
+	}
+	req := []byte{socks5AuthVersion, byte(len(username))}
+	req = append(req, username...)
+	req = append(req, byte(len(password)))
+	req = append(req, password...)
+	if _, err := conn.Write(req); err != nil {
+		return err
+	}
+	buf := make([]byte, 2)
+	if _, err := io.ReadFull(conn, buf); err != nil {
+		return err
+	}
+	if buf[1] != socks5Succeeded {
+		return fmt.Errorf("authentication failed")
+	}
+	return nil
+}
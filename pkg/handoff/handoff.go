@@ -0,0 +1,202 @@
+// Package handoff passes the listening sockets to a new process, the
+// connections queued in them are accepted by the new one, so the server
+// can restart without refusing connections.
+package handoff
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// The keys of inherited listeners, in the order of file descriptors.
+	envListeners = "SUNFLOWER_HANDOFF_LISTENERS"
+	// The write end of a pipe, the new process writes a byte once it is ready.
+	readyFd         = 3
+	firstListenerFd = 4
+)
+
+// Listeners creates the listeners, reuses the inherited ones if any.
+type Listeners struct {
+	mu        sync.Mutex
+	inherited map[string][]*os.File
+	active    map[*listener]struct{}
+	ready     *os.File // Not nil if started by Restart
+}
+
+// Inherit takes over the listeners passed by the parent process,
+// it must be called once before any other files are opened.
+func Inherit() *Listeners {
+	ls := &Listeners{
+		inherited: map[string][]*os.File{},
+		active:    map[*listener]struct{}{},
+	}
+	keys := os.Getenv(envListeners)
+	if keys == "" {
+		return ls
+	}
+	os.Unsetenv(envListeners) // Not for the children
+	ls.ready = os.NewFile(readyFd, "handoff-ready")
+	for i, key := range strings.Split(keys, ",") {
+		f := os.NewFile(uintptr(firstListenerFd+i), key)
+		ls.inherited[key] = append(ls.inherited[key], f)
+	}
+	return ls
+}
+
+// IsInherited returns true if the process is started by Restart.
+func (ls *Listeners) IsInherited() bool {
+	return ls.ready != nil
+}
+
+// Listen returns the inherited listener which listens on the same address,
+// or announces on the address.
+func (ls *Listeners) Listen(network, address string) (net.Listener, error) {
+	key := network + ":" + address
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	var l net.Listener
+	if files := ls.inherited[key]; len(files) > 0 {
+		f := files[0]
+		if len(files) == 1 {
+			delete(ls.inherited, key)
+		} else {
+			ls.inherited[key] = files[1:]
+		}
+		var err error
+		l, err = net.FileListener(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("inherit %s: %v", key, err)
+		}
+	} else {
+		var err error
+		if l, err = net.Listen(network, address); err != nil {
+			return nil, err
+		}
+	}
+	hl := &listener{Listener: l, key: key, ls: ls}
+	ls.active[hl] = struct{}{}
+	return hl, nil
+}
+
+// Ready tells the parent process that the listeners have been taken over,
+// the inherited ones which are not listened yet will be closed after delay,
+// since some of them are listened later, e.g. the ones of tunnels.
+func (ls *Listeners) Ready(delay time.Duration) error {
+	if ls.ready == nil {
+		return nil
+	}
+	_, err := ls.ready.Write([]byte{1})
+	ls.ready.Close()
+	time.AfterFunc(delay, ls.closeInherited)
+	return err
+}
+
+func (ls *Listeners) closeInherited() {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	for key, files := range ls.inherited {
+		for _, f := range files {
+			f.Close()
+		}
+		delete(ls.inherited, key)
+	}
+}
+
+// Restart starts the executable with args, passes the active listeners to it,
+// and waits until it is ready, the new process is killed if it is not ready
+// within timeout.
+func (ls *Listeners) Restart(exePath string, args []string, timeout time.Duration) (*os.Process, error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	files := []*os.File{w}
+	defer func() {
+		for _, f := range files {
+			f.Close()
+		}
+	}()
+	keys := []string{}
+	ls.mu.Lock()
+	for l := range ls.active {
+		f, err := l.file()
+		if err != nil {
+			ls.mu.Unlock()
+			return nil, fmt.Errorf("handoff %s: %v", l.key, err)
+		}
+		files = append(files, f)
+		keys = append(keys, l.key)
+	}
+	ls.mu.Unlock()
+
+	env := []string{}
+	for _, kv := range os.Environ() {
+		if !strings.HasPrefix(kv, envListeners+"=") {
+			env = append(env, kv)
+		}
+	}
+	cmd := exec.Command(exePath, args...)
+	cmd.Env = append(env, envListeners+"="+strings.Join(keys, ","))
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.ExtraFiles = files
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	w.Close() // Read returns EOF if the new process exits before ready
+
+	readyCh := make(chan bool, 1)
+	go func() {
+		b := make([]byte, 1)
+		n, _ := r.Read(b)
+		readyCh <- n == 1
+	}()
+	select {
+	case ok := <-readyCh:
+		if ok {
+			return cmd.Process, nil
+		}
+		err = fmt.Errorf("new process exited before ready")
+	case <-time.After(timeout):
+		err = fmt.Errorf("new process is not ready within %v", timeout)
+	}
+	cmd.Process.Kill()
+	cmd.Wait()
+	return nil, err
+}
+
+// listener is removed from the active ones once closed.
+type listener struct {
+	net.Listener
+	key  string
+	ls   *Listeners
+	once sync.Once
+}
+
+func (l *listener) file() (*os.File, error) {
+	fl, ok := l.Listener.(interface {
+		File() (*os.File, error)
+	})
+	if !ok {
+		return nil, fmt.Errorf("unsupported listener: %T", l.Listener)
+	}
+	return fl.File()
+}
+
+func (l *listener) Close() error {
+	l.once.Do(func() {
+		l.ls.mu.Lock()
+		delete(l.ls.active, l)
+		l.ls.mu.Unlock()
+	})
+	return l.Listener.Close()
+}
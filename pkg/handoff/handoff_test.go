@@ -0,0 +1,83 @@
+package handoff
+
+import (
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const envHelper = "SUNFLOWER_HANDOFF_HELPER"
+
+// TestHelperProcess acts as the new process started by Restart.
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv(envHelper) == "" {
+		return
+	}
+	ls := Inherit()
+	if !ls.IsInherited() {
+		os.Exit(2)
+	}
+	l, err := ls.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		os.Exit(3)
+	}
+	if os.Getenv(envHelper) == "fail" {
+		os.Exit(4)
+	}
+	ls.Ready(time.Second)
+	conn, err := l.Accept()
+	if err != nil {
+		os.Exit(5)
+	}
+	io.WriteString(conn, "new")
+	conn.Close()
+	os.Exit(0)
+}
+
+func TestListen(t *testing.T) {
+	ls := Inherit()
+	assert.False(t, ls.IsInherited())
+	assert.Nil(t, ls.Ready(time.Second))
+
+	l, err := ls.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	assert.Len(t, ls.active, 1)
+	require.Nil(t, l.Close())
+	assert.Len(t, ls.active, 0)
+}
+
+func TestRestart(t *testing.T) {
+	ls := Inherit()
+	l, err := ls.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	defer l.Close()
+	args := []string{"-test.run=TestHelperProcess"}
+
+	os.Setenv(envHelper, "fail")
+	_, err = ls.Restart(os.Args[0], args, 5*time.Second)
+	assert.NotNil(t, err)
+
+	os.Setenv(envHelper, "ok")
+	defer os.Unsetenv(envHelper)
+	p, err := ls.Restart(os.Args[0], args, 5*time.Second)
+	require.Nil(t, err)
+	// The queued connections are accepted by the new process.
+	addr := l.Addr().String()
+	l.Close()
+	conn, err := net.Dial("tcp", addr)
+	require.Nil(t, err)
+	defer conn.Close()
+	data, err := ioutil.ReadAll(conn)
+	require.Nil(t, err)
+	assert.Equal(t, "new", string(data))
+
+	state, err := p.Wait()
+	require.Nil(t, err)
+	assert.True(t, state.Success())
+}
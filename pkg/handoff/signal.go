@@ -0,0 +1,16 @@
+// +build !windows
+
+package handoff
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// WatchSignal notifies the signal which asks for restart, SIGUSR2.
+func WatchSignal() <-chan os.Signal {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGUSR2)
+	return sigCh
+}
@@ -0,0 +1,11 @@
+package handoff
+
+import (
+	"os"
+)
+
+// WatchSignal never notifies, since file descriptors can not be passed
+// to the new process on windows.
+func WatchSignal() <-chan os.Signal {
+	return make(chan os.Signal)
+}
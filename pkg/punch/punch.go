@@ -0,0 +1,149 @@
+// Package punch implements TCP hole punching by simultaneous open.
+//
+// Both peers connect to a rendezvous server by Dial first, the server tells
+// each of them the address of the other one as it observed, then both peers
+// call Punch with the same local address, so that the mappings of NATs can be
+// reused.
+package punch
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+const dialInterval = 100 * time.Millisecond
+
+var (
+	ErrTimeout     = errors.New("punch: timed out")
+	ErrUnsupported = errors.New("punch: unsupported platform")
+)
+
+// Handshake verifies the connection established during punching, since
+// more than one connection may be established, e.g. one is dialed and another
+// one is accepted, claim reports whether the connection is the chosen one,
+// one side must call it before confirming, so that both sides agree with it.
+type Handshake func(conn net.Conn, claim func() bool) error
+
+// Dial connects to the rendezvous server with address reusing enabled,
+// the local address of returned connection can be used by Punch later.
+func Dial(raddr string, timeout time.Duration) (net.Conn, error) {
+	d := net.Dialer{Timeout: timeout, Control: reuseAddr}
+	return d.Dial("tcp", raddr)
+}
+
+// Punch tries to connect to raddr from laddr and accepts connections on
+// laddr at the same time, until a connection passed the handshake or timed out.
+func Punch(laddr, raddr string, timeout time.Duration, handshake Handshake) (net.Conn, error) {
+	localAddr, err := net.ResolveTCPAddr("tcp", laddr)
+	if err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(timeout)
+	lc := net.ListenConfig{Control: reuseAddr}
+	l, err := lc.Listen(context.Background(), "tcp", laddr)
+	if err != nil {
+		return nil, err
+	}
+	defer l.Close()
+
+	p := &puncher{
+		deadline:  deadline,
+		handshake: handshake,
+		done:      make(chan struct{}),
+		winner:    make(chan net.Conn, 1),
+	}
+	go p.accept(l)
+	go p.dial(localAddr, raddr)
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case conn := <-p.winner:
+		p.stop(conn)
+		return conn, nil
+	case <-timer.C:
+		p.stop(nil)
+		return nil, ErrTimeout
+	}
+}
+
+type puncher struct {
+	sync.Mutex
+	deadline  time.Time
+	handshake Handshake
+	conns     []net.Conn
+	claimedBy net.Conn
+	stopped   bool
+	done      chan struct{}
+	winner    chan net.Conn
+}
+
+func (p *puncher) accept(l net.Listener) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		go p.verify(conn)
+	}
+}
+
+func (p *puncher) dial(laddr *net.TCPAddr, raddr string) {
+	d := net.Dialer{LocalAddr: laddr, Deadline: p.deadline, Control: reuseAddr}
+	for time.Now().Before(p.deadline) {
+		if conn, err := d.Dial("tcp", raddr); err == nil {
+			p.verify(conn)
+			return
+		}
+		select {
+		case <-p.done:
+			return
+		case <-time.After(dialInterval):
+		}
+	}
+}
+
+func (p *puncher) verify(conn net.Conn) {
+	p.Lock()
+	if p.stopped {
+		p.Unlock()
+		conn.Close()
+		return
+	}
+	p.conns = append(p.conns, conn)
+	p.Unlock()
+
+	claim := func() bool { return p.claim(conn) }
+	conn.SetDeadline(p.deadline)
+	if err := p.handshake(conn, claim); err != nil || !claim() {
+		conn.Close()
+		return
+	}
+	conn.SetDeadline(time.Time{})
+	p.winner <- conn // Only one can get here
+}
+
+// claim returns true if conn is the first one claimed.
+func (p *puncher) claim(conn net.Conn) bool {
+	p.Lock()
+	defer p.Unlock()
+	if p.claimedBy == nil && !p.stopped {
+		p.claimedBy = conn
+	}
+	return p.claimedBy == conn
+}
+
+func (p *puncher) stop(winner net.Conn) {
+	p.Lock()
+	defer p.Unlock()
+	p.stopped = true
+	close(p.done)
+	for _, conn := range p.conns {
+		if conn != winner {
+			conn.Close()
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package punch
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func freeAddr(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	defer l.Close()
+	return l.Addr().String()
+}
+
+func TestPunch(t *testing.T) {
+	addrA, addrB := freeAddr(t), freeAddr(t)
+
+	type result struct {
+		conn net.Conn
+		err  error
+	}
+	resA, resB := make(chan result, 1), make(chan result, 1)
+	go func() {
+		conn, err := Punch(addrA, addrB, 3*time.Second, func(conn net.Conn, claim func() bool) error {
+			if _, err := conn.Write([]byte("ping")); err != nil {
+				return err
+			}
+			buf := make([]byte, 4)
+			_, err := io.ReadFull(conn, buf)
+			return err
+		})
+		resA <- result{conn, err}
+	}()
+	go func() {
+		conn, err := Punch(addrB, addrA, 3*time.Second, func(conn net.Conn, claim func() bool) error {
+			buf := make([]byte, 4)
+			if _, err := io.ReadFull(conn, buf); err != nil {
+				return err
+			}
+			if !claim() {
+				return fmt.Errorf("not claimed")
+			}
+			_, err := conn.Write([]byte("pong"))
+			return err
+		})
+		resB <- result{conn, err}
+	}()
+
+	a, b := <-resA, <-resB
+	require.Nil(t, a.err)
+	require.Nil(t, b.err)
+	defer a.conn.Close()
+	defer b.conn.Close()
+
+	_, err := a.conn.Write([]byte("hello"))
+	require.Nil(t, err)
+	buf := make([]byte, 5)
+	b.conn.SetReadDeadline(time.Now().Add(time.Second))
+	_, err = io.ReadFull(b.conn, buf)
+	require.Nil(t, err)
+	assert.Equal(t, "hello", string(buf))
+}
+
+func TestPunchTimeout(t *testing.T) {
+	addrA, addrB := freeAddr(t), freeAddr(t)
+	_, err := Punch(addrA, addrB, 300*time.Millisecond, func(net.Conn, func() bool) error { return nil })
+	assert.Equal(t, ErrTimeout, err)
+}
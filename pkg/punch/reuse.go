@@ -0,0 +1,23 @@
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package punch
+
+import (
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+func reuseAddr(network, address string, c syscall.RawConn) error {
+	var err error
+	cerr := c.Control(func(fd uintptr) {
+		if err = unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_REUSEADDR, 1); err != nil {
+			return
+		}
+		err = unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_REUSEPORT, 1)
+	})
+	if cerr != nil {
+		return cerr
+	}
+	return err
+}
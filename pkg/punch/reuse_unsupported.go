@@ -0,0 +1,11 @@
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package punch
+
+import (
+	"syscall"
+)
+
+func reuseAddr(network, address string, c syscall.RawConn) error {
+	return ErrUnsupported
+}
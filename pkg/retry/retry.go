@@ -1,6 +1,7 @@
 package retry
 
 import (
+	"context"
 	"errors"
 	"math/rand"
 	"time"
@@ -9,17 +10,26 @@ import (
 // ErrCanceled is used to cancel the retry process.
 var ErrCanceled = errors.New("retry canceled")
 
+// Forever is the max attempts to retry forever.
+const Forever = -1
+
 // RetryFunc is a type which used for Retrier.
 type RetryFunc func() error
 
+// Hook is called after each failed attempt, wait is the backoff
+// before the next attempt, it is 0 if no more attempts.
+type Hook func(attempt int, err error, wait time.Duration)
+
 // Retrier is a simple retry implemention.
 type Retrier struct {
-	backoff time.Duration
-	max     int
+	backoff    time.Duration
+	maxBackoff time.Duration // Exponential if not zero
+	max        int           // Retries forever if negative
+	hook       Hook
 }
 
 // New creates a Retrier, the backoff increment is not exponential,
-// it is a random value.
+// it is a random value, fn is never called if max is 0.
 func New(backoff time.Duration, max int) *Retrier {
 	return &Retrier{
 		backoff: backoff,
@@ -27,22 +37,130 @@ func New(backoff time.Duration, max int) *Retrier {
 	}
 }
 
-// Run runs the RetryFunc until success or excceed the max retry times.
-func (r *Retrier) Run(fn RetryFunc) (err error) {
-	backoff := r.backoff
+// NewExponential creates a Retrier with capped exponential backoff and
+// full jitter, the n-th wait is a random value in [0, min(maxBackoff, backoff*2^n)),
+// it retries forever if max is Forever.
+func NewExponential(backoff, maxBackoff time.Duration, max int) *Retrier {
+	if maxBackoff < backoff {
+		maxBackoff = backoff
+	}
+	return &Retrier{
+		backoff:    backoff,
+		maxBackoff: maxBackoff,
+		max:        max,
+	}
+}
+
+// WithHook returns a copy of Retrier which calls hook after each failed attempt.
+func (r *Retrier) WithHook(hook Hook) *Retrier {
+	rr := *r
+	rr.hook = hook
+	return &rr
+}
 
-	for i, max := 1, r.max+1; i < max; i++ {
-		if err = fn(); err == nil {
-			return
-		}
+// WithMax returns a copy of Retrier which gives up after max attempts,
+// it is useful to bound a Retrier which retries forever.
+func (r *Retrier) WithMax(max int) *Retrier {
+	rr := *r
+	rr.max = max
+	return &rr
+}
+
+// Run runs the RetryFunc until success or excceed the max retry times,
+// fn returns ErrCanceled to stop retrying.
+func (r *Retrier) Run(fn RetryFunc) error {
+	return r.RunContext(context.Background(), func(context.Context) error {
+		err := fn()
 		if err == ErrCanceled {
-			return
+			return Stop(err)
+		}
+		return err
+	})
+}
+
+// RunContext runs fn until success, excceed the max retry times or ctx is done,
+// the error of ctx is returned if it is done, fn returns Stop(err) to stop
+// retrying with err.
+func (r *Retrier) RunContext(ctx context.Context, fn func(ctx context.Context) error) error {
+	var timer *time.Timer
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+
+	var wait time.Duration
+	for attempt := 1; r.max < 0 || attempt <= r.max; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+		if stop, ok := err.(stopError); ok {
+			return stop.err
+		}
+		if cerr := ctx.Err(); cerr != nil {
+			return cerr
 		}
-		time.Sleep(backoff)
 
-		backoff = backoff*time.Duration(i) + time.Duration(float64(backoff)*rand.Float64())
+		if r.max < 0 || attempt < r.max {
+			wait = r.nextBackoff(attempt, wait)
+		} else {
+			wait = 0
+		}
+		if r.hook != nil {
+			r.hook(attempt, err, wait)
+		}
+		if wait == 0 {
+			return err
+		}
+
+		if timer == nil {
+			timer = time.NewTimer(wait)
+		} else {
+			timer.Reset(wait)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	return nil
+}
+
+// nextBackoff returns the wait after the attempt-th failure, prev is
+// the previous one, it is never 0.
+func (r *Retrier) nextBackoff(attempt int, prev time.Duration) time.Duration {
+	if r.maxBackoff == 0 {
+		// The legacy one: prev*(attempt-1) plus randomness, it grows quickly.
+		if prev == 0 {
+			return r.backoff
+		}
+		return prev*time.Duration(attempt-1) + time.Duration(float64(prev)*rand.Float64())
+	}
+	ceil := r.maxBackoff
+	if shift := uint(attempt - 1); shift < 32 {
+		if d := r.backoff << shift; d > 0 && d < ceil {
+			ceil = d
+		}
 	}
-	return
+	return time.Duration(rand.Int63n(int64(ceil))) + 1
+}
+
+// Stop wraps err to stop retrying, the err is returned by RunContext.
+func Stop(err error) error {
+	return stopError{err: err}
+}
+
+type stopError struct {
+	err error
+}
+
+func (s stopError) Error() string {
+	return s.err.Error()
 }
 
 // Retry creates a shortcut retry function for later easier reuse.
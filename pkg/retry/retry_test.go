@@ -1,6 +1,7 @@
 package retry
 
 import (
+	"context"
 	"fmt"
 	"testing"
 	"time"
@@ -34,3 +35,90 @@ func TestRetry(t *testing.T) {
 	assert.Equal(t, 1, cnt)
 	assert.True(t, time.Now().Sub(now) < backoff)
 }
+
+func TestLegacyBackoff(t *testing.T) {
+	r := New(10*time.Millisecond, Forever)
+	wait := r.nextBackoff(1, 0)
+	assert.Equal(t, 10*time.Millisecond, wait)
+	for attempt := 2; attempt < 6; attempt++ {
+		prev := wait
+		wait = r.nextBackoff(attempt, prev)
+		min := prev * time.Duration(attempt-1)
+		assert.True(t, wait >= min && wait < min+prev, "attempt %d: %v", attempt, wait)
+	}
+
+	// No attempts at all.
+	cnt := 0
+	err := New(time.Millisecond, 0).Run(func() error {
+		cnt++
+		return fmt.Errorf("rt")
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, cnt)
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	r := NewExponential(10*time.Millisecond, 50*time.Millisecond, Forever)
+	for attempt := 1; attempt < 100; attempt++ {
+		ceil := 10 * time.Millisecond << uint(attempt-1)
+		if attempt > 3 {
+			ceil = 50 * time.Millisecond
+		}
+		for i := 0; i < 10; i++ {
+			wait := r.nextBackoff(attempt, 0)
+			assert.True(t, wait > 0 && wait <= ceil, "attempt %d: %v", attempt, wait)
+		}
+	}
+}
+
+func TestRunContext(t *testing.T) {
+	var attempts []int
+	r := NewExponential(time.Millisecond, 2*time.Millisecond, 3).WithHook(func(attempt int, err error, wait time.Duration) {
+		attempts = append(attempts, attempt)
+		if attempt < 3 {
+			assert.True(t, wait > 0)
+		} else {
+			assert.Equal(t, time.Duration(0), wait)
+		}
+	})
+	err := r.RunContext(context.Background(), func(context.Context) error { return fmt.Errorf("rt") })
+	assert.NotNil(t, err)
+	assert.Equal(t, []int{1, 2, 3}, attempts)
+
+	attempts = nil
+	err = NewExponential(time.Millisecond, time.Millisecond, Forever).WithMax(2).WithHook(func(attempt int, err error, wait time.Duration) {
+		attempts = append(attempts, attempt)
+	}).RunContext(context.Background(), func(context.Context) error { return fmt.Errorf("rt") })
+	assert.NotNil(t, err)
+	assert.Equal(t, []int{1, 2}, attempts)
+
+	// Retries forever until canceled.
+	ctx, cancel := context.WithCancel(context.Background())
+	cnt := 0
+	err = NewExponential(time.Millisecond, time.Millisecond, Forever).RunContext(ctx, func(context.Context) error {
+		if cnt++; cnt == 20 {
+			cancel()
+		}
+		return fmt.Errorf("rt")
+	})
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, 20, cnt)
+
+	// Stop retrying with the error.
+	stopErr := fmt.Errorf("stop")
+	cnt = 0
+	err = NewExponential(time.Millisecond, time.Millisecond, Forever).RunContext(context.Background(), func(context.Context) error {
+		cnt++
+		return Stop(stopErr)
+	})
+	assert.Equal(t, stopErr, err)
+	assert.Equal(t, 1, cnt)
+
+	cnt = 0
+	err = New(time.Millisecond, 5).Run(func() error {
+		cnt++
+		return ErrCanceled
+	})
+	assert.Equal(t, ErrCanceled, err)
+	assert.Equal(t, 1, cnt)
+}
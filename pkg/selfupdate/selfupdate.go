@@ -0,0 +1,130 @@
+// Package selfupdate replaces the running executable with a signed one.
+package selfupdate
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// The headers of update requests and responses over HTTP.
+const (
+	HeaderAgentID   = "X-Flower-ID"
+	HeaderAgentHash = "X-Flower-Hash"
+	HeaderSignature = "X-Flower-Signature"
+	HeaderVersion   = "X-Flower-Version"
+)
+
+// envStarted is set if the process is started by Start.
+const envStarted = "SUNFLOWER_SELFUPDATE_STARTED"
+
+// ErrBadSignature means the binary is not signed by the expected key.
+var ErrBadSignature = errors.New("bad signature")
+
+// ParsePublicKey parses the base64 encoded ed25519 public key.
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil || len(data) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("bad public key")
+	}
+	return ed25519.PublicKey(data), nil
+}
+
+// Sign signs the binary, the signature is base64 encoded.
+func Sign(key ed25519.PrivateKey, data []byte) string {
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(key, data))
+}
+
+// Verify verifies the base64 encoded signature of binary.
+func Verify(key ed25519.PublicKey, data []byte, sig string) error {
+	rawSig, err := base64.StdEncoding.DecodeString(sig)
+	if err != nil || !ed25519.Verify(key, data, rawSig) {
+		return ErrBadSignature
+	}
+	return nil
+}
+
+// IsStarted returns true if the process is started by Start,
+// and Ready is not called yet.
+func IsStarted() bool {
+	return os.Getenv(envStarted) != ""
+}
+
+// BackupPath returns where the old executable is kept.
+func BackupPath(exePath string) string {
+	return exePath + ".old"
+}
+
+// Replace replaces the executable with data, the old one is kept in
+// BackupPath(exePath) for rollback. The new executable is written into
+// a temporary file in the same directory, then renamed, so it is atomic
+// on platforms which support hard links.
+func Replace(exePath string, data []byte) error {
+	info, err := os.Stat(exePath)
+	if err != nil {
+		return err
+	}
+	tmpf, err := ioutil.TempFile(filepath.Dir(exePath), "."+filepath.Base(exePath))
+	if err != nil {
+		return err
+	}
+	tmpPath := tmpf.Name()
+	defer os.Remove(tmpPath) // No-op if renamed
+	if _, err := tmpf.Write(data); err != nil {
+		tmpf.Close()
+		return err
+	}
+	if err := tmpf.Sync(); err != nil {
+		tmpf.Close()
+		return err
+	}
+	if err := tmpf.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpPath, info.Mode()); err != nil {
+		return err
+	}
+
+	backupPath := BackupPath(exePath)
+	os.Remove(backupPath)
+	if err := os.Link(exePath, backupPath); err == nil {
+		return os.Rename(tmpPath, exePath)
+	}
+	// The running executable can not be replaced on some platforms, but renamed.
+	if err := os.Rename(exePath, backupPath); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, exePath); err != nil {
+		os.Rename(backupPath, exePath)
+		return err
+	}
+	return nil
+}
+
+// Rollback restores the executable kept by Replace.
+func Rollback(exePath string) error {
+	backupPath := BackupPath(exePath)
+	if err := os.Rename(backupPath, exePath); err == nil {
+		return nil
+	}
+	failedPath := exePath + ".failed"
+	os.Remove(failedPath)
+	if err := os.Rename(exePath, failedPath); err != nil {
+		return err
+	}
+	return os.Rename(backupPath, exePath)
+}
+
+// Commit removes the executable kept by Replace.
+func Commit(exePath string) error {
+	err := os.Remove(BackupPath(exePath))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
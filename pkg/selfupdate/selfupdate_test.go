@@ -0,0 +1,107 @@
+package selfupdate
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.Nil(t, err)
+	key, err := ParsePublicKey(base64.StdEncoding.EncodeToString(pub))
+	require.Nil(t, err)
+
+	data := []byte("flower")
+	sig := Sign(priv, data)
+	assert.Nil(t, Verify(key, data, sig))
+	assert.Equal(t, ErrBadSignature, Verify(key, []byte("weed"), sig))
+	assert.Equal(t, ErrBadSignature, Verify(key, data, "bad"))
+
+	_, err = ParsePublicKey("bad")
+	assert.NotNil(t, err)
+}
+
+func TestReplaceAndRollback(t *testing.T) {
+	dir, err := ioutil.TempDir("", "selfupdate")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+	exePath := filepath.Join(dir, "flower")
+	require.Nil(t, ioutil.WriteFile(exePath, []byte("v1"), 0755))
+
+	require.Nil(t, Replace(exePath, []byte("v2")))
+	assertContent(t, exePath, "v2")
+	assertContent(t, BackupPath(exePath), "v1")
+	info, err := os.Stat(exePath)
+	require.Nil(t, err)
+	assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+
+	require.Nil(t, Rollback(exePath))
+	assertContent(t, exePath, "v1")
+
+	require.Nil(t, Replace(exePath, []byte("v3")))
+	require.Nil(t, Commit(exePath))
+	assertContent(t, exePath, "v3")
+	_, err = os.Stat(BackupPath(exePath))
+	assert.True(t, os.IsNotExist(err))
+	assert.Nil(t, Commit(exePath))
+
+	files, err := ioutil.ReadDir(dir)
+	require.Nil(t, err)
+	assert.Equal(t, 1, len(files))
+}
+
+const envHelper = "SUNFLOWER_SELFUPDATE_HELPER"
+
+// TestHelperProcess acts as the new process started by Start.
+func TestHelperProcess(t *testing.T) {
+	mode := os.Getenv(envHelper)
+	if mode == "" {
+		return
+	}
+	if !IsStarted() {
+		os.Exit(2)
+	}
+	switch mode {
+	case "fail":
+		os.Exit(3)
+	case "hang":
+		time.Sleep(time.Minute)
+	}
+	if err := Ready(); err != nil || IsStarted() {
+		os.Exit(4)
+	}
+	os.Exit(0)
+}
+
+func TestStart(t *testing.T) {
+	args := []string{"-test.run=TestHelperProcess"}
+	defer os.Unsetenv(envHelper)
+	assert.False(t, IsStarted())
+	assert.Nil(t, Ready())
+
+	os.Setenv(envHelper, "fail")
+	assert.NotNil(t, Start(os.Args[0], args, os.Environ(), 5*time.Second))
+
+	os.Setenv(envHelper, "hang")
+	start := time.Now()
+	assert.NotNil(t, Start(os.Args[0], args, os.Environ(), 200*time.Millisecond))
+	assert.True(t, time.Since(start) < 5*time.Second)
+
+	os.Setenv(envHelper, "ok")
+	assert.Nil(t, Start(os.Args[0], args, os.Environ(), 5*time.Second))
+}
+
+func assertContent(t *testing.T, fpath, expected string) {
+	data, err := ioutil.ReadFile(fpath)
+	require.Nil(t, err)
+	assert.Equal(t, expected, string(data))
+}
@@ -0,0 +1,65 @@
+// +build !windows
+
+package selfupdate
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// The write end of a pipe, the new process writes a byte once it is ready.
+const readyFd = 3
+
+// Start starts the executable with args, and waits until it is ready,
+// the new process is killed if it exits or it is not ready within timeout,
+// so that the caller can roll back.
+func Start(exePath string, args, env []string, timeout time.Duration) error {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	cmd := exec.Command(exePath, args...)
+	cmd.Env = append(env, envStarted+"=1")
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.ExtraFiles = []*os.File{w}
+	err = cmd.Start()
+	w.Close() // Read returns EOF if the new process exits before ready
+	if err != nil {
+		return err
+	}
+
+	readyCh := make(chan bool, 1)
+	go func() {
+		b := make([]byte, 1)
+		n, _ := r.Read(b)
+		readyCh <- n == 1
+	}()
+	select {
+	case ok := <-readyCh:
+		if ok {
+			return nil
+		}
+		err = fmt.Errorf("new process exited before ready")
+	case <-time.After(timeout):
+		err = fmt.Errorf("new process is not ready within %v", timeout)
+	}
+	cmd.Process.Kill()
+	cmd.Wait()
+	return err
+}
+
+// Ready tells the process which started this one by Start that it is ready.
+func Ready() error {
+	if !IsStarted() {
+		return nil
+	}
+	os.Unsetenv(envStarted) // Not for the children
+	f := os.NewFile(readyFd, "selfupdate-ready")
+	defer f.Close()
+	_, err := f.Write([]byte{1})
+	return err
+}
@@ -0,0 +1,37 @@
+package selfupdate
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// Start starts the executable with args, and waits until it is ready,
+// but extra files can not be inherited on windows, so the new process is
+// only checked for exiting within timeout.
+func Start(exePath string, args, env []string, timeout time.Duration) error {
+	cmd := exec.Command(exePath, args...)
+	cmd.Env = append(env, envStarted+"=1")
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	exitCh := make(chan error, 1)
+	go func() { exitCh <- cmd.Wait() }()
+	select {
+	case err := <-exitCh:
+		if err == nil {
+			err = fmt.Errorf("new process exited before ready")
+		}
+		return err
+	case <-time.After(timeout):
+		return nil
+	}
+}
+
+// Ready tells the process which started this one by Start that it is ready.
+func Ready() error {
+	os.Unsetenv(envStarted)
+	return nil
+}
@@ -107,7 +107,7 @@ func HostIP() (string, error) {
 }
 
 func WatchSignals() <-chan os.Signal {
-	sigCh := make(chan os.Signal)
+	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, os.Interrupt, syscall.SIGKILL, syscall.SIGTERM, syscall.SIGINT)
 	return sigCh
 }
@@ -0,0 +1,130 @@
+// Package wsconn carries stream connections over WebSocket, so they can
+// pass through the networks which allow only HTTP(S) traffic.
+package wsconn
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/damnever/sunflower/pkg/dialer"
+)
+
+// Dial connects to the WebSocket endpoint, ws://host[:port]/path or
+// wss://host[:port]/path, the underlying connection is made by dial,
+// the timeout includes the TLS and WebSocket handshakes.
+func Dial(endpoint string, dial dialer.DialFunc, timeout time.Duration) (net.Conn, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	var port string
+	switch u.Scheme {
+	case "ws":
+		port = "80"
+	case "wss":
+		port = "443"
+	default:
+		return nil, fmt.Errorf("unsupported WebSocket scheme: %s", u.Scheme)
+	}
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), port)
+	}
+	config, err := websocket.NewConfig(endpoint, endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(timeout)
+	rawConn, err := dial("tcp", addr, timeout)
+	if err != nil {
+		return nil, err
+	}
+	rawConn.SetDeadline(deadline)
+	if u.Scheme == "wss" {
+		tlsConn := tls.Client(rawConn, &tls.Config{ServerName: u.Hostname()})
+		if err := tlsConn.Handshake(); err != nil {
+			rawConn.Close()
+			return nil, err
+		}
+		rawConn = tlsConn
+	}
+	ws, err := websocket.NewClient(config, rawConn)
+	if err != nil {
+		rawConn.Close()
+		return nil, err
+	}
+	rawConn.SetDeadline(time.Time{})
+	return newConn(ws, rawConn.LocalAddr(), rawConn.RemoteAddr()), nil
+}
+
+// Handler upgrades the requests to WebSocket, then hands the connections
+// to handle, the connections are closed after handle returned and
+// the connections have been closed by the owner.
+func Handler(handle func(conn net.Conn)) http.Handler {
+	return websocket.Server{
+		// The clients are not browsers, the origin does not matter.
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(ws *websocket.Conn) {
+			req := ws.Request()
+			var local, remote net.Addr
+			local, _ = req.Context().Value(http.LocalAddrContextKey).(net.Addr)
+			if local == nil {
+				local = ws.LocalAddr()
+			}
+			if addr, err := net.ResolveTCPAddr("tcp", req.RemoteAddr); err == nil {
+				remote = addr
+			} else {
+				remote = ws.RemoteAddr()
+			}
+			ws.SetDeadline(time.Time{})
+			conn := newConn(ws, local, remote)
+			handle(conn)
+			// The connection is closed once the handler returned.
+			<-conn.closed
+		},
+	}
+}
+
+// conn reports the addresses of underlying connection rather than
+// the URLs, and it is closed only once.
+type conn struct {
+	*websocket.Conn
+	local, remote net.Addr
+	once          sync.Once
+	closed        chan struct{}
+	err           error
+}
+
+func newConn(ws *websocket.Conn, local, remote net.Addr) *conn {
+	ws.PayloadType = websocket.BinaryFrame
+	return &conn{
+		Conn:   ws,
+		local:  local,
+		remote: remote,
+		closed: make(chan struct{}),
+	}
+}
+
+func (c *conn) LocalAddr() net.Addr {
+	return c.local
+}
+
+func (c *conn) RemoteAddr() net.Addr {
+	return c.remote
+}
+
+func (c *conn) Close() error {
+	c.once.Do(func() {
+		c.err = c.Conn.Close()
+		close(c.closed)
+	})
+	return c.err
+}
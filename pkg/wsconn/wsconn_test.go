@@ -0,0 +1,59 @@
+package wsconn
+
+import (
+	"io"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDialAndHandler(t *testing.T) {
+	accepted := make(chan net.Conn, 1)
+	server := httptest.NewServer(Handler(func(conn net.Conn) {
+		accepted <- conn
+		go func() {
+			defer conn.Close()
+			io.Copy(conn, conn)
+		}()
+	}))
+	defer server.Close()
+
+	endpoint := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+	conn, err := Dial(endpoint, net.DialTimeout, time.Second)
+	require.Nil(t, err)
+	defer conn.Close()
+	assert.Equal(t, strings.TrimPrefix(server.URL, "http://"), conn.RemoteAddr().String())
+
+	serverConn := <-accepted
+	assert.Equal(t, conn.LocalAddr().String(), serverConn.RemoteAddr().String())
+	assert.Equal(t, conn.RemoteAddr().String(), serverConn.LocalAddr().String())
+
+	// Large enough to be split into several frames.
+	data := []byte(strings.Repeat("sunflower", 10000))
+	go conn.Write(data)
+	buf := make([]byte, len(data))
+	_, err = io.ReadFull(conn, buf)
+	require.Nil(t, err)
+	assert.Equal(t, data, buf)
+
+	assert.Nil(t, conn.Close())
+	assert.Nil(t, conn.Close())
+	_, err = conn.Read(buf)
+	assert.NotNil(t, err)
+}
+
+func TestDialBadEndpoint(t *testing.T) {
+	_, err := Dial("http://127.0.0.1:80/ws", net.DialTimeout, time.Second)
+	assert.NotNil(t, err)
+
+	server := httptest.NewServer(nil)
+	defer server.Close()
+	endpoint := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+	_, err = Dial(endpoint, net.DialTimeout, time.Second)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,390 @@
+package sun
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/damnever/sunflower/pkg/input"
+	"github.com/damnever/sunflower/pkg/util"
+	"github.com/damnever/sunflower/sun/storage"
+	"github.com/damnever/sunflower/sun/web"
+)
+
+// The administration commands either operate on the database in datadir
+// directly, or are sent to the control panel API of the running server with
+// "-api", then the connected agents are notified as if they were done in the
+// control panel. The running server is not aware of the changes made to the
+// datadir, so the commands which change the connected agents and opened
+// tunnels require "-offline" to confirm that it is stopped.
+
+var errUsage = fmt.Errorf("unknown command")
+
+const adminTimeFormat = "2006-01-02 15:04:05"
+
+// admin is where the administration commands take effect.
+type admin interface {
+	AddUser(name, password, email string, isAdmin bool) error
+	Users() ([]storage.User, error)
+	DeleteUser(name string) error
+	ChangePasswd(name, password string) error
+	Agents(username string) ([]storage.Agent, error)
+	CreateAgent(username, tag string) (string, error)
+	DeleteAgent(username, ahash string) error
+	Tunnels(username, ahash string) ([]storage.Tunnel, error)
+	CreateTunnel(username, ahash string, fields map[string]string) (storage.Tunnel, error)
+	EnableTunnel(username, ahash, thash string, enabled bool) error
+}
+
+// runAdminCommand runs the command like "user add -name foo", errUsage
+// is returned if the command is unknown.
+func runAdminCommand(adm admin, args []string) error {
+	if len(args) < 2 {
+		return errUsage
+	}
+	name := args[0] + " " + args[1]
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	args = args[2:]
+	switch name {
+	case "user add":
+		return addUser(adm, fs, args)
+	case "user list":
+		return listUsers(adm, fs, args)
+	case "user delete":
+		return deleteUser(adm, fs, args)
+	case "user passwd":
+		return changePasswd(adm, fs, args)
+	case "agent list":
+		return listAgents(adm, fs, args)
+	case "agent create":
+		return createAgent(adm, fs, args)
+	case "agent delete":
+		return deleteAgent(adm, fs, args)
+	case "tunnel list":
+		return listTunnels(adm, fs, args)
+	case "tunnel create":
+		return createTunnel(adm, fs, args)
+	case "tunnel enable":
+		return enableTunnel(adm, fs, args, true)
+	case "tunnel disable":
+		return enableTunnel(adm, fs, args, false)
+	}
+	return errUsage
+}
+
+func addUser(adm admin, fs *flag.FlagSet, args []string) error {
+	name := fs.String("name", "", "Username.")
+	email := fs.String("email", "", "E-mail of user.")
+	isAdmin := fs.Bool("admin", false, "Add an administrator.")
+	fs.Parse(args)
+
+	if err := web.ValidateUsername(*name); err != nil {
+		return err
+	}
+	if err := web.ValidateEmail(*email); err != nil {
+		return err
+	}
+	password, err := readPasswd()
+	if err != nil {
+		return err
+	}
+	return adm.AddUser(*name, password, *email, *isAdmin)
+}
+
+func listUsers(adm admin, fs *flag.FlagSet, args []string) error {
+	fs.Parse(args)
+	users, err := adm.Users()
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tEMAIL\tADMIN\tCREATED AT")
+	for _, user := range users {
+		fmt.Fprintf(tw, "%s\t%s\t%v\t%s\n", user.Name, user.Email, user.IsAdmin,
+			user.CreatedAt.Local().Format(adminTimeFormat))
+	}
+	return tw.Flush()
+}
+
+func deleteUser(adm admin, fs *flag.FlagSet, args []string) error {
+	name := fs.String("name", "", "Username.")
+	fs.Parse(args)
+
+	return adm.DeleteUser(*name)
+}
+
+func changePasswd(adm admin, fs *flag.FlagSet, args []string) error {
+	name := fs.String("name", "", "Username.")
+	fs.Parse(args)
+
+	password, err := readPasswd()
+	if err != nil {
+		return err
+	}
+	return adm.ChangePasswd(*name, password)
+}
+
+// readPasswd reads the password from stdin, the first line is taken if
+// stdin is not a terminal, so that it can be piped in scripts.
+func readPasswd() (string, error) {
+	var password string
+	if fi, err := os.Stdin.Stat(); err == nil && fi.Mode()&os.ModeCharDevice != 0 {
+		if password, err = input.GetPasswd("> Password: "); err != nil {
+			return "", err
+		}
+		password2, err := input.GetPasswd("> Password(confirm): ")
+		if err != nil {
+			return "", err
+		}
+		if password2 != password {
+			return "", fmt.Errorf("two passwords doesn't match")
+		}
+	} else {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			return "", fmt.Errorf("read password from stdin: %v", err)
+		}
+		password = strings.TrimRight(line, "\r\n")
+	}
+	if err := web.ValidatePassword(password); err != nil {
+		return "", err
+	}
+	return password, nil
+}
+
+func listAgents(adm admin, fs *flag.FlagSet, args []string) error {
+	username := fs.String("user", "", "Username.")
+	fs.Parse(args)
+
+	agents, err := adm.Agents(*username)
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "HASH\tTAG\tSTATUS\tVERSION\tDEVICE")
+	for _, agent := range agents {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", agent.Hash, agent.Tag, agent.Status, agent.Version, agent.Device)
+	}
+	return tw.Flush()
+}
+
+// createAgent creates an agent and prints its hash.
+func createAgent(adm admin, fs *flag.FlagSet, args []string) error {
+	username := fs.String("user", "", "Username.")
+	tag := fs.String("tag", "", "Tag of agent.")
+	fs.Parse(args)
+
+	if err := web.ValidateTag(*tag); err != nil {
+		return err
+	}
+	ahash, err := adm.CreateAgent(*username, *tag)
+	if err != nil {
+		return err
+	}
+	fmt.Println(ahash)
+	return nil
+}
+
+func deleteAgent(adm admin, fs *flag.FlagSet, args []string) error {
+	username := fs.String("user", "", "Username.")
+	ahash := fs.String("agent", "", "Hash of agent.")
+	fs.Parse(args)
+
+	return adm.DeleteAgent(*username, *ahash)
+}
+
+func listTunnels(adm admin, fs *flag.FlagSet, args []string) error {
+	username := fs.String("user", "", "Username.")
+	ahash := fs.String("agent", "", "Hash of agent.")
+	fs.Parse(args)
+
+	tunnels, err := adm.Tunnels(*username, *ahash)
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "HASH\tTAG\tPROTO\tEXPORT\tSERVER\tENABLED\tSTATUS")
+	for _, t := range tunnels {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%v\t%s\n",
+			t.Hash, t.Tag, t.Proto, t.ExportAddr, t.ServerAddr, t.Enabled, t.Status)
+	}
+	return tw.Flush()
+}
+
+// createTunnel creates a tunnel from the fields given as key=value, the keys
+// are the same as the form of control panel, e.g. "proto=tcp", then prints
+// the generated fields.
+func createTunnel(adm admin, fs *flag.FlagSet, args []string) error {
+	username := fs.String("user", "", "Username.")
+	ahash := fs.String("agent", "", "Hash of agent.")
+	fs.Parse(args)
+
+	fields := map[string]string{}
+	for _, kv := range fs.Args() {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("invalid field %q, want key=value", kv)
+		}
+		fields[parts[0]] = parts[1]
+	}
+	tunnel, err := adm.CreateTunnel(*username, *ahash, fields)
+	if err != nil {
+		return err
+	}
+
+	for _, kv := range [][2]string{
+		{"hash", tunnel.Hash},
+		{"server_addr", tunnel.ServerAddr},
+		{"secret_key", tunnel.SecretKey},
+		{"proxy_user", tunnel.ProxyUser},
+		{"proxy_password", tunnel.ProxyPass},
+		{"target_addr", tunnel.TargetAddr},
+	} {
+		if kv[1] != "" {
+			fmt.Printf("%s=%s\n", kv[0], kv[1])
+		}
+	}
+	return nil
+}
+
+func enableTunnel(adm admin, fs *flag.FlagSet, args []string, enabled bool) error {
+	username := fs.String("user", "", "Username.")
+	ahash := fs.String("agent", "", "Hash of agent.")
+	thash := fs.String("tunnel", "", "Hash of tunnel.")
+	fs.Parse(args)
+
+	return adm.EnableTunnel(*username, *ahash, *thash, enabled)
+}
+
+// dbAdmin operates on the database in datadir.
+type dbAdmin struct {
+	db      *storage.DB
+	webconf *web.Config
+	offline bool // The server is stopped, confirmed by "-offline"
+}
+
+// checkOffline refuses the changes which the running server would miss.
+func (d *dbAdmin) checkOffline(what string) error {
+	if d.offline {
+		return nil
+	}
+	return fmt.Errorf("%s is not noticed by the running sun, "+
+		"send it to sun with -api, or add -offline if sun is stopped", what)
+}
+
+func (d *dbAdmin) AddUser(name, password, email string, isAdmin bool) error {
+	password, err := util.EncryptPasswd([]byte(password))
+	if err != nil {
+		return err
+	}
+	if err := d.db.CreateUser(name, password, email, isAdmin); err != nil {
+		if storage.IsExist(err) {
+			return fmt.Errorf("user %s already exists", name)
+		}
+		return err
+	}
+	return nil
+}
+
+func (d *dbAdmin) Users() ([]storage.User, error) {
+	return d.db.QueryUsers()
+}
+
+func (d *dbAdmin) DeleteUser(name string) error {
+	if err := d.checkOffline("deleting user"); err != nil {
+		return err
+	}
+	err := d.db.DeleteUser(name)
+	if storage.IsNotExist(err) {
+		return fmt.Errorf("no such user: %s", name)
+	}
+	return err
+}
+
+func (d *dbAdmin) ChangePasswd(name, password string) error {
+	password, err := util.EncryptPasswd([]byte(password))
+	if err != nil {
+		return err
+	}
+	ok, err := d.db.UpdateUser(name, map[string]interface{}{"password": password})
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("no such user: %s", name)
+	}
+	return nil
+}
+
+func (d *dbAdmin) Agents(username string) ([]storage.Agent, error) {
+	return d.db.QueryAgents(username)
+}
+
+func (d *dbAdmin) CreateAgent(username, tag string) (string, error) {
+	if _, err := d.db.QueryUser(username); err != nil {
+		if storage.IsNotExist(err) {
+			return "", fmt.Errorf("no such user: %s", username)
+		}
+		return "", err
+	}
+	count, err := d.db.QueryAgentCount(username)
+	if err != nil {
+		return "", err
+	}
+	if count > d.webconf.MaxAdminAgents {
+		return "", fmt.Errorf("only %d agents allowed", d.webconf.MaxAdminAgents)
+	}
+
+	ahash := util.Hash(username, tag)[:8]
+	if err := d.db.CreateAgent(username, ahash, tag); err != nil {
+		if storage.IsExist(err) {
+			return "", fmt.Errorf("agent %s[%s] already exists, try again", ahash, tag)
+		}
+		return "", err
+	}
+	return ahash, nil
+}
+
+func (d *dbAdmin) DeleteAgent(username, ahash string) error {
+	if err := d.checkOffline("deleting agent"); err != nil {
+		return err
+	}
+	err := d.db.DeleteAgent(username, ahash)
+	if storage.IsNotExist(err) {
+		return fmt.Errorf("no such agent: %s", ahash)
+	}
+	return err
+}
+
+func (d *dbAdmin) Tunnels(username, ahash string) ([]storage.Tunnel, error) {
+	return d.db.QueryTunnels(username, ahash)
+}
+
+func (d *dbAdmin) CreateTunnel(username, ahash string, fields map[string]string) (storage.Tunnel, error) {
+	if err := d.checkOffline("creating tunnel"); err != nil {
+		return storage.Tunnel{}, err
+	}
+	tunnel, err := web.CreateTunnel(d.webconf, d.db, username, ahash, fields)
+	if storage.IsNotExist(err) {
+		return tunnel, fmt.Errorf("no such agent: %s", ahash)
+	}
+	return tunnel, err
+}
+
+func (d *dbAdmin) EnableTunnel(username, ahash, thash string, enabled bool) error {
+	if err := d.checkOffline("enabling/disabling tunnel"); err != nil {
+		return err
+	}
+	ok, err := d.db.UpdateTunnel(username, ahash, thash, map[string]interface{}{"enabled": enabled})
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("no such tunnel: %s", thash)
+	}
+	return nil
+}
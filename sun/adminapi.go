@@ -0,0 +1,199 @@
+package sun
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/damnever/sunflower/pkg/input"
+	"github.com/damnever/sunflower/sun/storage"
+)
+
+const (
+	apiTimeout = 10 * time.Second
+	// apiTimeFormat is the format of times in the responses of control panel API.
+	apiTimeFormat = "15:04:05 01/02/2006"
+)
+
+// apiAdmin sends the commands to the control panel API of running server,
+// as an administrator.
+type apiAdmin struct {
+	endpoint string
+	cli      *http.Client
+}
+
+// newAPIAdmin logs in the control panel at rawurl, which is like
+// "http://admin@127.0.0.1:7000", the password is prompted if it is
+// not in rawurl.
+func newAPIAdmin(rawurl string) (*apiAdmin, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "" || u.Host == "" || u.User == nil || u.User.Username() == "" {
+		return nil, fmt.Errorf("invalid API address %q, want http://<admin>[:<password>]@<host>:<port>", rawurl)
+	}
+	username := u.User.Username()
+	password, ok := u.User.Password()
+	if !ok {
+		if password, err = input.GetPasswd(fmt.Sprintf("> Password of %s: ", username)); err != nil {
+			return nil, err
+		}
+	}
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+	a := &apiAdmin{
+		endpoint: fmt.Sprintf("%s://%s%s", u.Scheme, u.Host, strings.TrimRight(u.Path, "/")),
+		cli:      &http.Client{Jar: jar, Timeout: apiTimeout},
+	}
+	form := url.Values{"username": {username}, "password": {password}}
+	if err := a.do("POST", "/api/login", form, nil); err != nil {
+		return nil, fmt.Errorf("login as %s: %v", username, err)
+	}
+	return a, nil
+}
+
+// do sends the form and decodes the response into v if it is not nil.
+func (a *apiAdmin) do(method, path string, form url.Values, v interface{}) error {
+	var body io.Reader
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+	req, err := http.NewRequest(method, a.endpoint+path, body)
+	if err != nil {
+		return err
+	}
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	resp, err := a.cli.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		result := struct {
+			Message string `json:"message"`
+		}{}
+		if err := json.NewDecoder(resp.Body).Decode(&result); err == nil && result.Message != "" {
+			return fmt.Errorf("%s", result.Message)
+		}
+		return fmt.Errorf("unexpected response: %s", resp.Status)
+	}
+	if v == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func (a *apiAdmin) userPath(username string, elems ...string) string {
+	path := "/api/users/" + url.PathEscape(username)
+	for _, elem := range elems {
+		path += "/" + url.PathEscape(elem)
+	}
+	return path
+}
+
+func (a *apiAdmin) AddUser(name, password, email string, isAdmin bool) error {
+	if isAdmin {
+		return fmt.Errorf("administrators can not be added through API, use -offline")
+	}
+	form := url.Values{"username": {name}, "password": {password}, "email": {email}}
+	return a.do("POST", "/api/users", form, nil)
+}
+
+func (a *apiAdmin) Users() ([]storage.User, error) {
+	var result []struct {
+		storage.User
+		CreatedAt string `json:"created_at"`
+		UpdatedAt string `json:"updated_at"`
+	}
+	if err := a.do("GET", "/api/users", nil, &result); err != nil {
+		return nil, err
+	}
+	users := make([]storage.User, 0, len(result))
+	for _, r := range result {
+		user := r.User
+		user.CreatedAt, _ = time.ParseInLocation(apiTimeFormat, r.CreatedAt, time.Local)
+		user.UpdatedAt, _ = time.ParseInLocation(apiTimeFormat, r.UpdatedAt, time.Local)
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+func (a *apiAdmin) DeleteUser(name string) error {
+	return a.do("DELETE", a.userPath(name), nil, nil)
+}
+
+func (a *apiAdmin) ChangePasswd(name, password string) error {
+	return a.do("PATCH", a.userPath(name), url.Values{"password": {password}}, nil)
+}
+
+func (a *apiAdmin) Agents(username string) ([]storage.Agent, error) {
+	var result []struct {
+		storage.Agent
+		CreatedAt string `json:"created_at"`
+		UpdatedAt string `json:"updated_at"`
+	}
+	if err := a.do("GET", a.userPath(username, "agents"), nil, &result); err != nil {
+		return nil, err
+	}
+	agents := make([]storage.Agent, 0, len(result))
+	for _, r := range result {
+		agents = append(agents, r.Agent)
+	}
+	return agents, nil
+}
+
+func (a *apiAdmin) CreateAgent(username, tag string) (string, error) {
+	result := struct {
+		Hash string `json:"hash"`
+	}{}
+	err := a.do("POST", a.userPath(username, "agents"), url.Values{"tag": {tag}}, &result)
+	return result.Hash, err
+}
+
+func (a *apiAdmin) DeleteAgent(username, ahash string) error {
+	return a.do("DELETE", a.userPath(username, "agents", ahash), nil, nil)
+}
+
+func (a *apiAdmin) Tunnels(username, ahash string) ([]storage.Tunnel, error) {
+	var result []struct {
+		storage.Tunnel
+		CreatedAt string `json:"created_at"`
+		UpdatedAt string `json:"updated_at"`
+	}
+	if err := a.do("GET", a.userPath(username, "agents", ahash, "tunnels"), nil, &result); err != nil {
+		return nil, err
+	}
+	tunnels := make([]storage.Tunnel, 0, len(result))
+	for _, r := range result {
+		tunnels = append(tunnels, r.Tunnel)
+	}
+	return tunnels, nil
+}
+
+func (a *apiAdmin) CreateTunnel(username, ahash string, fields map[string]string) (storage.Tunnel, error) {
+	form := url.Values{}
+	for k, v := range fields {
+		form.Set(k, v)
+	}
+	var tunnel storage.Tunnel
+	err := a.do("POST", a.userPath(username, "agents", ahash, "tunnels"), form, &tunnel)
+	return tunnel, err
+}
+
+func (a *apiAdmin) EnableTunnel(username, ahash, thash string, enabled bool) error {
+	form := url.Values{"enabled": {strconv.FormatBool(enabled)}}
+	return a.do("PATCH", a.userPath(username, "agents", ahash, "tunnels", thash), form, nil)
+}
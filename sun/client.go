@@ -16,10 +16,13 @@ import (
 	"github.com/damnever/sunflower/sun/registry"
 	"github.com/damnever/sunflower/sun/storage"
 	"github.com/damnever/sunflower/sun/tracker"
+	"github.com/damnever/sunflower/sun/usererr"
 )
 
 const (
 	defaultDelayTimerBufferSize = 10
+	// Waits for agent to close the connection after the shutdown notice sent.
+	shutdownNoticeTimeout = 1 * time.Second
 )
 
 type CtlClient struct {
@@ -27,6 +30,8 @@ type CtlClient struct {
 	reg        *registry.TCPTunnelRegistry
 	sub        pubsub.Subscriber
 	db         *storage.DB
+	declarer   tunnelDeclarer
+	notice     *msgpb.ShutdownRequest // Read after ctx is done
 	tracker    *tracker.AgentTracker
 	logger     *zap.SugaredLogger
 	lastPingT  time.Time
@@ -55,6 +60,7 @@ PROCESS_LOOP:
 		select {
 		case <-ctx.Done():
 			c.logger.Info("Stopping..")
+			c.noticeShutdown()
 			break PROCESS_LOOP
 		case err := <-c.Err():
 			c.logger.Errorf("Connection error: %v", err)
@@ -67,6 +73,24 @@ PROCESS_LOOP:
 	}
 }
 
+// noticeShutdown tells agent to reconnect later, then waits for agent to
+// close the connection, so the notice will not be lost.
+func (c *CtlClient) noticeShutdown() {
+	notice := *c.notice
+	notice.ID, notice.ClientHash = c.ID, c.Hash
+	timer := time.NewTimer(shutdownNoticeTimeout)
+	defer timer.Stop()
+	select {
+	case c.Out() <- &notice:
+	case <-timer.C:
+		return
+	}
+	select {
+	case <-c.Err():
+	case <-timer.C:
+	}
+}
+
 func (c *CtlClient) handleMessage(m interface{}) {
 	switch x := m.(type) {
 	case *msgpb.PingRequest:
@@ -94,6 +118,43 @@ func (c *CtlClient) handleMessage(m interface{}) {
 		} else {
 			c.logger.Infof("Tunnel %s registered", x.TunnelHash)
 		}
+	case *msgpb.HealthReport:
+		if x.ID != c.ID || x.ClientHash != c.Hash {
+			c.logger.Warnf("Bad HealthReport: %+v", x)
+			return
+		}
+		tracker := c.tracker.TunnelTracker(x.TunnelHash)
+		if x.Healthy {
+			tracker.IsHealthy()
+		} else {
+			tracker.IsUnhealthy(x.Reason)
+		}
+	case *msgpb.DialErrorReport:
+		if x.ID != c.ID || x.ClientHash != c.Hash {
+			c.logger.Warnf("Bad DialErrorReport: %+v", x)
+			return
+		}
+		c.logger.Debugf("DialErrorReport: %+v", x)
+		c.tracker.TunnelTracker(x.TunnelHash).OnError(fmtDialError(x))
+	case *msgpb.PunchReport:
+		if x.ID != c.ID || x.ClientHash != c.Hash {
+			c.logger.Warnf("Bad PunchReport: %+v", x)
+			return
+		}
+		tracker := c.tracker.TunnelTracker(x.TunnelHash)
+		if x.Direct {
+			tracker.IsDirect()
+		} else {
+			c.logger.Infof("Punching of tunnel %s failed, fall back to relay: %s", x.TunnelHash, x.Reason)
+			tracker.IsRelayed()
+		}
+	case *msgpb.DeclareTunnelsRequest:
+		if x.ID != c.ID || x.ClientHash != c.Hash {
+			c.logger.Warnf("Bad DeclareTunnelsRequest: %+v", x)
+			return
+		}
+		// The events of new tunnels are sent to the loop, do not block it.
+		go c.declareTunnels(x.Tunnels)
 	case *msgpb.CloseTunnelResponse:
 		if err := msg.CodeToError(x.ErrCode); err != nil {
 			c.logger.Errorf("Received bad CloseTunnelResponse: %v", err)
@@ -106,6 +167,29 @@ func (c *CtlClient) handleMessage(m interface{}) {
 	}
 }
 
+func (c *CtlClient) declareTunnels(tunnels []*msgpb.DeclaredTunnel) {
+	resp := &msgpb.DeclareTunnelsResponse{}
+	for _, t := range tunnels {
+		result := &msgpb.DeclaredTunnelResult{Tag: t.Tag}
+		tunnel, err := c.declarer.DeclareTunnel(c.ID, c.Hash, map[string]string{
+			"tag":         t.Tag,
+			"proto":       t.Proto,
+			"export_addr": t.ExportAddr,
+			"server_addr": t.ServerAddr,
+		})
+		if err != nil {
+			c.logger.Warnf("Declared tunnel %s rejected: %v", t.Tag, err)
+			result.Reason = usererr.Reason(err)
+		} else {
+			result.Accepted = true
+			result.TunnelHash = tunnel.Hash
+			result.ServerAddr = tunnel.ServerAddr
+		}
+		resp.Results = append(resp.Results, result)
+	}
+	c.Out() <- resp
+}
+
 func (c *CtlClient) handleEvent(evt *pubsub.Event) error {
 	id, ahash, thash := c.ID, c.Hash, evt.TunnelHash
 
@@ -126,6 +210,8 @@ func (c *CtlClient) handleEvent(evt *pubsub.Event) error {
 			ClientHash: ahash,
 			TunnelHash: thash,
 		}
+	case pubsub.EventUpdateTunnel:
+		c.updateErrorPages(thash)
 	case pubsub.EventRejectAgent:
 		c.Out() <- &msgpb.ShutdownRequest{ID: id, ClientHash: ahash}
 		// XXX(damnever): better method to ensure message has been send.
@@ -149,22 +235,107 @@ func (c *CtlClient) openAllTunnels() error {
 	return nil
 }
 
-func (c *CtlClient) openTunnel(tunnel storage.Tunnel) {
-	err := c.reg.Register(
-		c.tracker.TunnelTracker(tunnel.Hash),
-		tunnel.Proto, tunnel.ServerAddr,
+// errorPage returns the error page of tunnel, or the one of user if it is empty.
+func (c *CtlClient) errorPage(tunnel storage.Tunnel) string {
+	if tunnel.ErrorPage != "" {
+		return tunnel.ErrorPage
+	}
+	user, err := c.db.QueryUser(c.ID)
+	if err != nil {
+		c.logger.Warnf("Query error page of user failed: %v", err)
+	}
+	return user.ErrorPage
+}
+
+// updateErrorPages applies the error pages to the opened tunnels,
+// all tunnels of agent if thash is empty.
+func (c *CtlClient) updateErrorPages(thash string) {
+	var (
+		tunnels []storage.Tunnel
+		err     error
 	)
+	if thash == "" {
+		tunnels, err = c.db.QueryTunnels(c.ID, c.Hash)
+	} else {
+		var tunnel storage.Tunnel
+		tunnel, err = c.db.QueryTunnel(c.ID, c.Hash, thash)
+		tunnels = append(tunnels, tunnel)
+	}
+	if err != nil {
+		c.logger.Warnf("Query tunnels to update error page failed: %v", err)
+		return
+	}
+	for _, tunnel := range tunnels {
+		if tunnel.Proto == "HTTP" {
+			c.reg.UpdateErrorPage(c.Hash, tunnel.Hash, c.errorPage(tunnel))
+		}
+	}
+}
+
+func (c *CtlClient) openTunnel(tunnel storage.Tunnel) {
+	err := c.reg.Register(c.tracker.TunnelTracker(tunnel.Hash), registry.TunnelOptions{
+		Proto:      tunnel.Proto,
+		ServerAddr: tunnel.ServerAddr,
+		ErrorPage:  c.errorPage(tunnel),
+		SecretKey:  tunnel.SecretKey,
+		Punch:      c.punchFunc(tunnel.Hash),
+		TargetAddr: tunnel.TargetAddr,
+	})
 	if err != nil {
 		c.logger.Errorf("Open tunnel %s failed: %v", tunnel.Hash, err)
 		return
 	}
 
 	c.Out() <- &msgpb.NewTunnelRequest{
-		ID:           c.ID,
-		ClientHash:   c.Hash,
-		TunnelHash:   tunnel.Hash,
-		Proto:        tunnel.Proto,
-		ExportAddr:   tunnel.ExportAddr,
-		RegistryAddr: c.reg.ListenAddr(),
+		ID:                c.ID,
+		ClientHash:        c.Hash,
+		TunnelHash:        tunnel.Hash,
+		Proto:             tunnel.Proto,
+		ExportAddr:        tunnel.ExportAddr,
+		RegistryAddr:      c.reg.ListenAddr(),
+		HealthCheckPath:   tunnel.HealthPath,
+		AllowCIDRs:        tunnel.AllowCIDRs,
+		AllowPorts:        tunnel.AllowPorts,
+		ProxyUser:         tunnel.ProxyUser,
+		ProxyPassword:     tunnel.ProxyPass,
+		LocalTLS:          tunnel.LocalTLS,
+		TLSServerName:     tunnel.TLSName,
+		TLSSkipVerify:     tunnel.TLSSkip,
+		TLSCA:             tunnel.TLSCA,
+		FileListing:       tunnel.FileList,
+		BasicAuthUser:     tunnel.AuthUser,
+		BasicAuthPassword: tunnel.AuthPass,
+	}
+}
+
+func (c *CtlClient) punchFunc(thash string) registry.PunchFunc {
+	return func(peerAddr, nonce string) bool {
+		select {
+		case c.Out() <- &msgpb.PunchRequest{
+			ID:         c.ID,
+			ClientHash: c.Hash,
+			TunnelHash: thash,
+			PeerAddr:   peerAddr,
+			Nonce:      nonce,
+		}:
+			return true
+		default: // Too busy
+			return false
+		}
+	}
+}
+
+func fmtDialError(r *msgpb.DialErrorReport) string {
+	var reason string
+	switch r.Class {
+	case msgpb.DialErrorRefused:
+		reason = "local service refused connection"
+	case msgpb.DialErrorTimeout:
+		reason = "connect to local service timed out"
+	case msgpb.DialErrorDNS:
+		reason = "resolve local address failed"
+	default:
+		reason = r.Reason
 	}
+	return fmt.Sprintf("%s, %d times", reason, r.Count)
 }
@@ -0,0 +1,320 @@
+// Package cluster runs sun on several nodes behind one address, they share
+// the users, agents and tunnels through the storage, the nodes exchange the
+// agents connected to them and the tunnels registered on them, so that:
+//   - an agent is connected to only one node at a time,
+//   - the events of agents published by any node reach the one they connected to,
+//   - the public connections of tunnels arriving at any node are forwarded to
+//     the one which registered the tunnel, so do the tunnel connections and
+//     visitors of secret tunnels.
+package cluster
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/damnever/sunflower/log"
+	"github.com/damnever/sunflower/sun/pubsub"
+	"github.com/damnever/sunflower/sun/registry"
+)
+
+// The state of peer is considered gone if it is not exchanged
+// in such many intervals.
+const stateTTLIntervals = 3
+
+// Config describes the local node and its peers.
+type Config struct {
+	Addr     string        // Listens for peers, they reach this node with it
+	Secret   string        // Shared by all nodes, authenticates the requests between them
+	Peers    []string      // The addresses of other nodes
+	Interval time.Duration // Exchanges the state with peers
+	Timeout  time.Duration // Connects to and requests peers
+	Listener net.Listener  // Listens on Addr if nil
+}
+
+// Node is the local node, see sun.CtlServer.
+type Node interface {
+	// Agents returns the agents connected, identified by "id:hash".
+	Agents() []string
+	Registry() *registry.TCPTunnelRegistry
+}
+
+// state is what a node tells peers.
+type state struct {
+	Node    string                `json:"node"`
+	Epoch   int64                 `json:"epoch"` // The start time of process, the new one wins on restart
+	Agents  []string              `json:"agents"`
+	Tunnels []registry.TunnelInfo `json:"tunnels"`
+}
+
+type Cluster struct {
+	sync.RWMutex
+	conf      Config
+	logger    *zap.SugaredLogger
+	epoch     int64
+	ps        *pubsub.PubSub
+	cli       *http.Client
+	ln        net.Listener
+	server    *http.Server
+	peers     map[string]*peer  // Indexed by address, the map is never changed
+	node      Node              // Set before serving
+	failed    map[string]string // The tunnels failed to forward, to avoid repeating logs, used by Run only
+	changes   chan struct{}     // The local state changed
+	received  chan struct{}     // The state of peer received
+	done      chan struct{}
+	stopped   chan struct{} // Closed after Run returned
+	closeOnce sync.Once
+}
+
+// New creates the cluster, the events published are delivered to ps
+// of this node and the peers.
+func New(conf Config, ps *pubsub.PubSub) (*Cluster, error) {
+	host, _, err := net.SplitHostPort(conf.Addr)
+	if err != nil {
+		return nil, err
+	}
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		return nil, fmt.Errorf("address must be reachable by peers: %s", conf.Addr)
+	}
+	if conf.Secret == "" {
+		return nil, fmt.Errorf("secret is required")
+	}
+	if len(conf.Peers) == 0 {
+		return nil, fmt.Errorf("no peers")
+	}
+	peers := make(map[string]*peer, len(conf.Peers))
+	for _, addr := range conf.Peers {
+		if addr == conf.Addr {
+			return nil, fmt.Errorf("the node itself is one of peers: %s", addr)
+		}
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			return nil, err
+		}
+		peers[addr] = newPeer(addr)
+	}
+
+	ln := conf.Listener
+	if ln == nil {
+		if ln, err = net.Listen("tcp", conf.Addr); err != nil {
+			return nil, err
+		}
+	}
+	c := &Cluster{
+		conf:     conf,
+		logger:   log.New("cluster"),
+		epoch:    time.Now().UnixNano(),
+		ps:       ps,
+		cli:      &http.Client{Timeout: conf.Timeout},
+		ln:       ln,
+		peers:    peers,
+		failed:   map[string]string{},
+		changes:  make(chan struct{}, 1),
+		received: make(chan struct{}, 1),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	c.server = &http.Server{
+		Handler:      c.handler(),
+		ReadTimeout:  conf.Timeout,
+		WriteTimeout: conf.Timeout, // The deadlines are cleared after hijacked
+	}
+	return c, nil
+}
+
+// Run serves the peers and exchanges the state with them until closed.
+func (c *Cluster) Run(node Node) error {
+	defer close(c.stopped)
+	c.node = node
+	errCh := make(chan error, 1)
+	go func() { errCh <- c.server.Serve(c.ln) }()
+	defer c.server.Close()
+	for _, p := range c.peers {
+		go c.sendEvents(p)
+	}
+
+	ticker := time.NewTicker(c.conf.Interval)
+	defer ticker.Stop()
+	exchange := true
+	for {
+		if exchange {
+			c.exchangeAll(c.localState())
+		}
+		c.reconcile()
+
+		select {
+		case <-c.done:
+			// Let peers take over the agents at once.
+			c.exchangeAll(state{Node: c.conf.Addr, Epoch: c.epoch})
+			return nil
+		case err := <-errCh:
+			return err
+		case <-ticker.C:
+			exchange = true
+		case <-c.changes:
+			exchange = true
+		case <-c.received:
+			exchange = false
+		}
+	}
+}
+
+// Close stops the cluster and waits for Run to return, peers are told
+// that this node has left.
+func (c *Cluster) Close() {
+	c.closeOnce.Do(func() { close(c.done) })
+	<-c.stopped
+}
+
+// Changed tells that the agents or tunnels of this node changed, it never blocks.
+func (c *Cluster) Changed() {
+	select {
+	case c.changes <- struct{}{}:
+	default:
+	}
+}
+
+func (c *Cluster) localState() state {
+	return state{
+		Node:    c.conf.Addr,
+		Epoch:   c.epoch,
+		Agents:  c.node.Agents(),
+		Tunnels: c.node.Registry().Tunnels(),
+	}
+}
+
+func (c *Cluster) exchangeAll(local state) {
+	var wg sync.WaitGroup
+	for _, p := range c.peers {
+		wg.Add(1)
+		go func(p *peer) {
+			defer wg.Done()
+			c.exchange(p, local)
+		}(p)
+	}
+	wg.Wait()
+}
+
+// reconcile forwards the tunnels registered on live peers, and stops
+// forwarding the ones gone.
+func (c *Cluster) reconcile() {
+	reg := c.node.Registry()
+	wants := c.forwardables()
+	for _, info := range reg.Tunnels() {
+		delete(wants, info.Hash)
+	}
+	for _, info := range reg.Forwards() {
+		if want, in := wants[info.Hash]; !in || want != info {
+			reg.Unforward(info.Hash)
+		}
+	}
+	for thash := range c.failed {
+		if _, in := wants[thash]; !in {
+			delete(c.failed, thash)
+		}
+	}
+	for thash, info := range wants {
+		err := reg.Forward(info)
+		if err == nil {
+			delete(c.failed, thash)
+			continue
+		}
+		if c.failed[thash] != err.Error() {
+			c.failed[thash] = err.Error()
+			c.logger.Warnf("Forward tunnel <%8s:%8s> failed: %v", info.AgentHash, thash, err)
+		}
+	}
+}
+
+// forwardables returns the tunnels of live peers which can be forwarded.
+func (c *Cluster) forwardables() map[string]registry.TunnelInfo {
+	c.RLock()
+	defer c.RUnlock()
+
+	infos := map[string]registry.TunnelInfo{}
+	now := time.Now()
+	for _, p := range c.peers {
+		if !p.isLive(now, c.ttl()) {
+			continue
+		}
+		for _, info := range p.state.Tunnels {
+			if info.Forwardable() {
+				infos[info.Hash] = info
+			}
+		}
+	}
+	return infos
+}
+
+func (c *Cluster) ttl() time.Duration {
+	return stateTTLIntervals * c.conf.Interval
+}
+
+// HasAgent reports whether the agent is connected to another node.
+func (c *Cluster) HasAgent(id, hash string) bool {
+	key := fmt.Sprintf("%s:%s", id, hash)
+	c.RLock()
+	defer c.RUnlock()
+	now := time.Now()
+	for _, p := range c.peers {
+		if p.isLive(now, c.ttl()) && p.agents[key] {
+			return true
+		}
+	}
+	return false
+}
+
+// HasTunnel reports whether the tunnel is registered on another node.
+func (c *Cluster) HasTunnel(thash string) bool {
+	return c.owner(thash) != nil
+}
+
+// owner returns the live peer which registered the tunnel.
+func (c *Cluster) owner(thash string) *peer {
+	c.RLock()
+	defer c.RUnlock()
+	now := time.Now()
+	for _, p := range c.peers {
+		if _, in := p.tunnels[thash]; in && p.isLive(now, c.ttl()) {
+			return p
+		}
+	}
+	return nil
+}
+
+// setState replaces the state of peer, the one of a previous process is ignored.
+func (c *Cluster) setState(p *peer, s state) {
+	c.Lock()
+	defer c.Unlock()
+	if s.Epoch < p.state.Epoch {
+		return
+	}
+	p.setState(s, time.Now())
+}
+
+// Pub publishes the events to the agent, which is connected to this node or a peer.
+func (c *Cluster) Pub(ahash string, evts ...*pubsub.Event) {
+	c.ps.Pub(ahash, evts...)
+	m := &eventsMessage{AgentHash: ahash}
+	for _, evt := range evts {
+		m.Events = append(m.Events, event{Type: evt.Type, TunnelHash: evt.TunnelHash})
+	}
+	for _, p := range c.peers {
+		select {
+		case p.events <- m:
+		default:
+			c.logger.Warnf("Too many events to %s, drop the ones of agent %s", p.addr, ahash)
+		}
+	}
+}
+
+func (c *Cluster) Sub(ahash string) <-chan *pubsub.Event {
+	return c.ps.Sub(ahash)
+}
+
+func (c *Cluster) Unsub(ahash string) {
+	c.ps.Unsub(ahash)
+}
@@ -0,0 +1,311 @@
+package cluster
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/damnever/sunflower/msg"
+	"github.com/damnever/sunflower/msg/msgpb"
+	connutil "github.com/damnever/sunflower/pkg/conn"
+	"github.com/damnever/sunflower/sun/pubsub"
+)
+
+const eventQueueSize = 64
+
+type event struct {
+	Type       pubsub.EventType `json:"type"`
+	TunnelHash string           `json:"tunnel_hash"`
+}
+
+type eventsMessage struct {
+	AgentHash string  `json:"agent_hash"`
+	Events    []event `json:"events"`
+}
+
+// peer is another node, the state is guarded by the lock of Cluster.
+type peer struct {
+	addr    string
+	state   state
+	agents  map[string]bool
+	tunnels map[string]bool
+	seenAt  time.Time
+	live    bool // Whether the last exchange succeeded, to avoid repeating logs
+	events  chan *eventsMessage
+}
+
+func newPeer(addr string) *peer {
+	return &peer{
+		addr:   addr,
+		events: make(chan *eventsMessage, eventQueueSize),
+	}
+}
+
+func (p *peer) setState(s state, now time.Time) {
+	p.state = s
+	p.agents = make(map[string]bool, len(s.Agents))
+	for _, key := range s.Agents {
+		p.agents[key] = true
+	}
+	p.tunnels = make(map[string]bool, len(s.Tunnels))
+	for _, info := range s.Tunnels {
+		p.tunnels[info.Hash] = true
+	}
+	p.seenAt = now
+}
+
+func (p *peer) isLive(now time.Time, ttl time.Duration) bool {
+	return now.Sub(p.seenAt) < ttl
+}
+
+// exchange sends the local state to the peer, and takes the one of it.
+func (c *Cluster) exchange(p *peer, local state) {
+	var remote state
+	err := c.post(p, "/cluster/state", local, &remote)
+	if err == nil && remote.Node != p.addr {
+		err = fmt.Errorf("unexpected node: %s", remote.Node)
+	}
+
+	c.Lock()
+	defer c.Unlock()
+	if err != nil {
+		if p.live {
+			c.logger.Warnf("Exchange state with %s failed: %v", p.addr, err)
+		}
+		p.live = false
+		return
+	}
+	if !p.live {
+		c.logger.Infof("Peer %s joined", p.addr)
+	}
+	p.live = true
+	if remote.Epoch >= p.state.Epoch {
+		p.setState(remote, time.Now())
+	}
+}
+
+// sendEvents delivers the events to the peer in order.
+func (c *Cluster) sendEvents(p *peer) {
+	for {
+		select {
+		case <-c.done:
+			return
+		case m := <-p.events:
+			if err := c.post(p, "/cluster/events", m, nil); err != nil {
+				c.logger.Warnf("Send events of agent %s to %s failed: %v", m.AgentHash, p.addr, err)
+			}
+		}
+	}
+}
+
+func (c *Cluster) post(p *peer, path string, in, out interface{}) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", "http://"+p.addr+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.conf.Secret)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.cli.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response: %s", resp.Status)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// dial connects to the peer, the connection is handed to the handler
+// of path there.
+func (c *Cluster) dial(p *peer, path string) (net.Conn, error) {
+	conn, err := net.DialTimeout("tcp", p.addr, c.conf.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetDeadline(time.Now().Add(c.conf.Timeout))
+	req, err := http.NewRequest("POST", "http://"+p.addr+path, nil)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.conf.Secret)
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	rd := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(rd, req)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("unexpected response: %s", resp.Status)
+	}
+	conn.SetDeadline(time.Time{})
+	return &bufferedConn{Conn: conn, rd: rd}, nil
+}
+
+// DialTunnel connects to the public side of the tunnel registered on the peer.
+func (c *Cluster) DialTunnel(ahash, thash string) (net.Conn, error) {
+	p := c.owner(thash)
+	if p == nil {
+		return nil, fmt.Errorf("no node registered tunnel %s", thash)
+	}
+	return c.dial(p, fmt.Sprintf("/cluster/tunnels/%s/%s", url.PathEscape(ahash), url.PathEscape(thash)))
+}
+
+// ForwardHandshake hands the tunnel connection to the peer which registered
+// the tunnel, it returns after the connection closed.
+func (c *Cluster) ForwardHandshake(conn net.Conn, req *msgpb.TunnelHandshakeRequest) error {
+	p := c.owner(req.TunnelHash)
+	if p == nil {
+		return fmt.Errorf("no node registered tunnel %s", req.TunnelHash)
+	}
+	peerConn, err := c.dial(p, "/cluster/handshake")
+	if err != nil {
+		return err
+	}
+	if err := msg.Write(peerConn, req); err != nil {
+		peerConn.Close()
+		return err
+	}
+	conn.SetDeadline(time.Time{})
+	connutil.LinkStream(conn, peerConn)
+	return nil
+}
+
+func (c *Cluster) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/cluster/state", c.handleState)
+	mux.HandleFunc("/cluster/events", c.handleEvents)
+	mux.HandleFunc("/cluster/handshake", c.handleHandshake)
+	mux.HandleFunc("/cluster/tunnels/", c.handleTunnel)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		secret := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(secret), []byte(c.conf.Secret)) != 1 {
+			http.Error(w, "bad secret", http.StatusUnauthorized)
+			return
+		}
+		if r.Method != "POST" {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+func (c *Cluster) handleState(w http.ResponseWriter, r *http.Request) {
+	var remote state
+	if err := json.NewDecoder(r.Body).Decode(&remote); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	p, in := c.peers[remote.Node]
+	if !in {
+		c.logger.Warnf("Unknown node %s from %s", remote.Node, r.RemoteAddr)
+		http.Error(w, "unknown node", http.StatusForbidden)
+		return
+	}
+	c.setState(p, remote)
+	select {
+	case c.received <- struct{}{}:
+	default:
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(c.localState())
+}
+
+func (c *Cluster) handleEvents(w http.ResponseWriter, r *http.Request) {
+	var m eventsMessage
+	if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	evts := make([]*pubsub.Event, 0, len(m.Events))
+	for _, evt := range m.Events {
+		evts = append(evts, &pubsub.Event{Type: evt.Type, TunnelHash: evt.TunnelHash})
+	}
+	c.ps.Pub(m.AgentHash, evts...)
+}
+
+// handleHandshake serves the tunnel connection forwarded by peer,
+// the handshake request is replayed.
+func (c *Cluster) handleHandshake(w http.ResponseWriter, r *http.Request) {
+	conn, err := hijack(w)
+	if err != nil {
+		c.logger.Errorf("Hijack forwarded handshake failed: %v", err)
+		return
+	}
+	c.node.Registry().HandleForwardedHandshake(conn)
+}
+
+// handleTunnel serves the public connection of tunnel forwarded by peer.
+func (c *Cluster) handleTunnel(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/cluster/tunnels/"), "/")
+	if len(parts) != 2 {
+		http.NotFound(w, r)
+		return
+	}
+	ahash, thash := parts[0], parts[1]
+	if !c.node.Registry().HasTunnel(ahash, thash) {
+		http.Error(w, "no such tunnel", http.StatusNotFound)
+		return
+	}
+	conn, err := hijack(w)
+	if err != nil {
+		c.logger.Errorf("Hijack forwarded connection failed: %v", err)
+		return
+	}
+	if !c.node.Registry().HandleForwardedConn(ahash, thash, conn) {
+		conn.Close()
+	}
+}
+
+// hijack takes over the connection after responded.
+func hijack(w http.ResponseWriter) (net.Conn, error) {
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		return nil, fmt.Errorf("hijacking is not supported")
+	}
+	conn, rw, err := hj.Hijack()
+	if err != nil {
+		return nil, err
+	}
+	conn.SetDeadline(time.Time{})
+	if _, err := conn.Write([]byte("HTTP/1.1 200 OK\r\n\r\n")); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &bufferedConn{Conn: conn, rd: rw.Reader}, nil
+}
+
+// bufferedConn reads the data buffered before the connection taken over.
+type bufferedConn struct {
+	net.Conn
+	rd *bufio.Reader
+}
+
+func (bc *bufferedConn) Read(p []byte) (int, error) {
+	if bc.rd.Buffered() > 0 {
+		return bc.rd.Read(p)
+	}
+	return bc.Conn.Read(p)
+}
@@ -8,19 +8,32 @@ import (
 	"github.com/damnever/cc"
 
 	"github.com/damnever/sunflower/birpc"
+	"github.com/damnever/sunflower/sun/cluster"
 	"github.com/damnever/sunflower/sun/registry"
 	"github.com/damnever/sunflower/sun/web"
 )
 
 type Config struct {
 	GracefulShutdown time.Duration
+	ReconnectDelay   time.Duration // Told to agents on shutdown, they wait it before reconnecting
 	MuxRegConf       registry.Config
 	RPCConf          birpc.ServerConfig
+	SinglePort       SinglePortConfig
+	Cluster          cluster.Config // Disabled if Addr is empty
+}
+
+// SinglePortConfig describes the port which serves control, registry,
+// subdomain and web traffic together, it is disabled if Addr is empty.
+type SinglePortConfig struct {
+	Addr    string
+	TLSCert string // Terminates TLS if provided, then detects the protocol again
+	TLSKey  string
 }
 
 func buildCoreConfig(rawConf cc.Configer) Config {
 	conf := Config{}
 	conf.GracefulShutdown = rawConf.DurationAndOr("graceful_shutdown", "N>=1", 3) * time.Second
+	conf.ReconnectDelay = rawConf.DurationAndOr("reconnect_delay", "N>=0", 10) * time.Second
 	{
 		rpcconf := birpc.ServerConfig{}
 		controlC := rawConf.Config("control")
@@ -37,11 +50,32 @@ func buildCoreConfig(rawConf cc.Configer) Config {
 		mrconf.IP = rawConf.StringOr("proxy_ip", rawConf.String("host_ip"))
 		muxC := rawConf.Config("muxreg")
 		mrconf.HTTPAddr = muxC.String("http_addr")
+		for _, addr := range muxC.Value("reverse_targets").List() {
+			mrconf.ReverseTargets = append(mrconf.ReverseTargets, addr.String())
+		}
 		timeoutC := muxC.Config("timeout")
+		mrconf.Timeout.Connect = timeoutC.DurationAndOr("connect", "N>=100", 1000) * time.Millisecond
 		mrconf.Timeout.Read = timeoutC.DurationAndOr("read", "N>=100", 2000) * time.Millisecond
 		mrconf.Timeout.Write = timeoutC.DurationAndOr("write", "N>0", 300) * time.Millisecond
+		mrconf.ResponseTimeout = timeoutC.DurationAndOr("response", "N>=1000", 60000) * time.Millisecond
 		conf.MuxRegConf = mrconf
 	}
+	{
+		singleC := rawConf.Config("single_port")
+		conf.SinglePort.Addr = singleC.String("addr")
+		conf.SinglePort.TLSCert = singleC.String("tls_cert")
+		conf.SinglePort.TLSKey = singleC.String("tls_key")
+	}
+	{
+		clusterC := rawConf.Config("cluster")
+		conf.Cluster.Addr = clusterC.String("addr")
+		conf.Cluster.Secret = clusterC.String("secret")
+		for _, addr := range clusterC.Value("peers").List() {
+			conf.Cluster.Peers = append(conf.Cluster.Peers, addr.String())
+		}
+		conf.Cluster.Interval = clusterC.DurationAndOr("interval", "N>=1", 5) * time.Second
+		conf.Cluster.Timeout = clusterC.DurationAndOr("timeout", "N>=100", 3000) * time.Millisecond
+	}
 	return conf
 }
 
@@ -52,6 +86,8 @@ func buildWebConfig(controlPort string, rawConf cc.Configer) *web.Config {
 	conf.MuxDomain = rawConf.String("domain")
 	webC := rawConf.Config("web")
 	conf.Addr = webC.String("addr")
+	conf.PublicURL = webC.String("public_url")
+	conf.SessionKey = rawConf.Config("cluster").String("secret") // Sessions are valid on all nodes
 	conf.AllowOrigins = []string{}
 	for _, origin := range webC.Value("allow_origins").List() {
 		conf.AllowOrigins = append(conf.AllowOrigins, origin.String())
@@ -62,6 +98,28 @@ func buildWebConfig(controlPort string, rawConf cc.Configer) *web.Config {
 	conf.MaxUserTunnels = webC.IntAndOr("max_user_tunnels", "N>=3&&N<=12", 10)
 	conf.MaxDownloadsPerHour = webC.IntAndOr("max_downloads_per_hour", "N>=3&&N<=10", 6)
 	conf.MaxTunnelUpdatePerHour = webC.IntAndOr("max_tunnel_updates_per_hour", "N>=5&&N<=24", 12)
+	autoPortC := webC.Config("auto_port")
+	conf.AutoPortMin = autoPortC.IntAndOr("min", "N>=1024&&N<=65535", 0)
+	conf.AutoPortMax = autoPortC.IntAndOr("max", "N>=1024&&N<=65535", 0)
+	conf.ReverseTargets = []string{}
+	for _, addr := range rawConf.Config("muxreg").Value("reverse_targets").List() {
+		conf.ReverseTargets = append(conf.ReverseTargets, addr.String())
+	}
+	conf.UnixSockets = []string{}
+	for _, pattern := range webC.Value("unix_sockets").List() {
+		conf.UnixSockets = append(conf.UnixSockets, pattern.String())
+	}
+	conf.FileDirs = []string{}
+	for _, pattern := range webC.Value("file_dirs").List() {
+		conf.FileDirs = append(conf.FileDirs, pattern.String())
+	}
+	conf.BindIPs = []string{}
+	for _, ip := range webC.Value("bind_ips").List() {
+		conf.BindIPs = append(conf.BindIPs, ip.String())
+	}
+	if len(conf.BindIPs) == 0 {
+		conf.BindIPs = append(conf.BindIPs, "0.0.0.0")
+	}
 	agentConfig := fmt.Sprintf("control_server: %s:%s\n%s", conf.HostIP, controlPort, webC.String("agent_config"))
 	conf.AgentConfig = agentConfig
 	return conf
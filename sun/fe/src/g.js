@@ -4,16 +4,21 @@ function User() {
   this.name = ""
   this.isadmin = false
   this.email = ""
+  this.error_page = ""
   this.reset = () => {
     this.name = ""
     this.isadmin = false
     this.email = ""
+    this.error_page = ""
   }
 }
 
 function Config() {
   this.domain = ""
   this.ip = ""
+  this.auto_port = false
+  this.bind_ips = []
+  this.reverse_targets = []
 }
 
 export var user = new User()
@@ -0,0 +1,72 @@
+package sun
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/damnever/sunflower/pkg/handoff"
+	"github.com/damnever/sunflower/sun/web"
+)
+
+const (
+	restartTimeout = 30 * time.Second
+	// The listeners of tunnels are listened again after agents reconnected,
+	// the ones of agents which do not come back are closed after it.
+	inheritedListenerTTL = time.Minute
+)
+
+// listenHandoff creates the listeners of control server, tunnel registry,
+// HTTP muxer and control panel, they are handed off to the new process on restart.
+func listenHandoff(listeners *handoff.Listeners, coreconf *Config, webconf *web.Config) error {
+	var err error
+	if coreconf.RPCConf.Listener, err = listeners.Listen("tcp", coreconf.RPCConf.ListenAddr); err != nil {
+		return err
+	}
+	if coreconf.MuxRegConf.Listener, err = listeners.Listen("tcp", "0.0.0.0:0"); err != nil {
+		return err
+	}
+	if coreconf.MuxRegConf.Domain != "" {
+		if coreconf.MuxRegConf.HTTPListener, err = listeners.Listen("tcp", coreconf.MuxRegConf.HTTPAddr); err != nil {
+			return err
+		}
+	}
+	webconf.Listener, err = listeners.Listen("tcp", webconf.Addr)
+	return err
+}
+
+// restart starts a new process with the same arguments and hands off the
+// listeners to it, the current process should drain and exit once it is done.
+func restart(listeners *handoff.Listeners) (int, error) {
+	exePath, err := os.Executable()
+	if err != nil {
+		return 0, err
+	}
+	p, err := listeners.Restart(exePath, os.Args[1:], restartTimeout)
+	if err != nil {
+		return 0, err
+	}
+	return p.Pid, nil
+}
+
+// notifySystemd tells systemd that the current process is the main one and
+// ready, so the new process takes over the service after restarting instead
+// of being killed with the old one, see etc/sun.service.
+// It is a no-op if the service is not started by systemd with Type=notify.
+func notifySystemd() error {
+	addr := os.Getenv("NOTIFY_SOCKET")
+	if addr == "" {
+		return nil
+	}
+	if addr[0] == '@' { // Abstract namespace
+		addr = "\x00" + addr[1:]
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: addr, Net: "unixgram"})
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = fmt.Fprintf(conn, "MAINPID=%d\nREADY=1", os.Getpid())
+	return err
+}
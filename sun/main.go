@@ -14,9 +14,11 @@ import (
 
 	"github.com/damnever/sunflower/log"
 	"github.com/damnever/sunflower/pkg/debug"
+	"github.com/damnever/sunflower/pkg/handoff"
 	"github.com/damnever/sunflower/pkg/input"
 	"github.com/damnever/sunflower/pkg/open"
 	"github.com/damnever/sunflower/pkg/util"
+	"github.com/damnever/sunflower/sun/cluster"
 	"github.com/damnever/sunflower/sun/pubsub"
 	"github.com/damnever/sunflower/sun/storage"
 	"github.com/damnever/sunflower/sun/web"
@@ -27,11 +29,29 @@ var (
 	a = flag.Bool("a", false, "Add an administrator.")
 	b = flag.Bool("b", false, "Open control panel in browser, for quick start.")
 	c = flag.String("c", "sun.server.yaml", "Path to server configuration file.")
+
+	api     = flag.String("api", "", "Send the administration commands to the control panel of running sun, e.g. http://admin@127.0.0.1:7000.")
+	offline = flag.Bool("offline", false, "Confirm that sun is stopped, the administration commands which change agents and tunnels are run on the datadir.")
 )
 
+func init() {
+	flag.Usage = func() {
+		out := flag.CommandLine.Output()
+		fmt.Fprintf(out, "Usage: %s [flags] [user|agent|tunnel <command> [flags]]\n\n", os.Args[0])
+		fmt.Fprintf(out, "  user add|list|delete|passwd\n\tManage users, the password is read from stdin.\n")
+		fmt.Fprintf(out, "  agent list|create|delete -user <name>\n\tManage the agents of user, the hash of created one is printed.\n")
+		fmt.Fprintf(out, "  tunnel list|create|enable|disable -user <name> -agent <hash>\n\tManage the tunnels of agent, the fields of created one are given as key=value.\n\n")
+		fmt.Fprintf(out, "The commands operate on the datadir, or are sent to the running sun with -api, the ones which\n")
+		fmt.Fprintf(out, "change agents and tunnels are noticed by sun only through -api, or require -offline if it is stopped.\n\n")
+		flag.PrintDefaults()
+	}
+}
+
 func Run() {
 	flag.Parse()
 	logger := log.New("M")
+	// Reuses the listeners handed off by the previous process if restarted.
+	listeners := handoff.Inherit()
 
 	ip, err := util.HostIP()
 	if err != nil {
@@ -49,14 +69,53 @@ func Run() {
 		logger.Fatalf("Resolve absolute path(%s) failed: %v", datadir, err)
 	}
 	cconf.Set("datadir", datadir)
+	storageC := cconf.Config("storage")
+	dbDriver, dbDSN := storageC.StringOr("driver", "sqlite3"), storageC.String("dsn")
+	openDB := func() (*storage.DB, error) {
+		if dbDSN == "" {
+			return storage.New(datadir)
+		}
+		return storage.Open(dbDriver, dbDSN)
+	}
 	coreconf := buildCoreConfig(cconf)
-	_, port, err := net.SplitHostPort(coreconf.RPCConf.ListenAddr)
+	controlAddr := coreconf.RPCConf.ListenAddr
+	if coreconf.SinglePort.Addr != "" {
+		controlAddr = coreconf.SinglePort.Addr
+	}
+	_, port, err := net.SplitHostPort(controlAddr)
 	if err != nil {
 		logger.Fatalf("Parse control address failed: %v", err)
 	}
 	webconf := buildWebConfig(port, cconf)
 	cconf = nil
 
+	if flag.NArg() > 0 {
+		var adm admin
+		if *api != "" {
+			if *offline {
+				logger.Fatalf("-api and -offline are exclusive")
+			}
+			if adm, err = newAPIAdmin(*api); err != nil {
+				logger.Fatalf("Connect to control panel failed: %v", err)
+			}
+		} else {
+			db, err := openDB()
+			if err != nil {
+				logger.Fatalf("Init database failed: %v", err)
+			}
+			defer db.Close()
+			adm = &dbAdmin{db: db, webconf: webconf, offline: *offline}
+		}
+		if err := runAdminCommand(adm, flag.Args()); err != nil {
+			if err == errUsage {
+				flag.Usage()
+				os.Exit(2)
+			}
+			logger.Fatalf("Run %s failed: %v", strings.Join(flag.Args()[:2], " "), err)
+		}
+		return
+	}
+
 	fatalF := func(err error, ignoreEOF bool, format string, args ...interface{}) {
 		if err != nil {
 			if err == io.EOF {
@@ -65,39 +124,91 @@ func Run() {
 			logger.Fatalf(format+": %+v", append(args, err)...)
 		}
 	}
-	tryStartDebugServer(debugAddr, logger)
+	tryStartDebugServer(debugAddr, listeners, logger)
 
 	// Init db
-	db, err := storage.New(datadir)
+	db, err := openDB()
 	fatalF(err, false, "Init database failed")
-	fatalF(tryAddAdmin(db), true, "Unexpected error")
+	if !listeners.IsInherited() {
+		fatalF(tryAddAdmin(db), true, "Unexpected error")
+	}
 
 	ps := pubsub.New()
-	errCh := make(chan error, 2)
+	errCh := make(chan error, 3)
 
-	ctls, err := NewCtlServer(coreconf, ps, db)
-	fatalF(err, false, "Init core server failed")
-	go func() { errCh <- ctls.Run() }()
+	coreconf.MuxRegConf.Listen = listeners.Listen
+	if coreconf.SinglePort.Addr != "" {
+		mux, err := listenSinglePort(&coreconf, webconf, listeners.Listen)
+		fatalF(err, false, "Listen on single port failed")
+		go func() { errCh <- mux.Serve() }()
+		defer mux.Close()
+	} else {
+		fatalF(listenHandoff(listeners, &coreconf, webconf), false, "Listen failed")
+	}
+
+	// The events are published to the agents connected to other nodes too.
+	var (
+		pub pubsub.Publisher = ps
+		cl  *cluster.Cluster
+	)
+	if coreconf.Cluster.Addr != "" {
+		coreconf.Cluster.Listener, err = listeners.Listen("tcp", coreconf.Cluster.Addr)
+		fatalF(err, false, "Listen on cluster address failed")
+		cl, err = cluster.New(coreconf.Cluster, ps)
+		fatalF(err, false, "Init cluster failed")
+		pub = cl
+	}
 
-	webserver, err := web.New(webconf, db, ps)
+	webserver, err := web.New(webconf, db, pub)
 	fatalF(err, false, "Init web server failed")
+	ctls, err := NewCtlServer(coreconf, ps, db, webserver, cl)
+	fatalF(err, false, "Init core server failed")
+	webserver.SetConnHandler(ctls)
+
+	go func() { errCh <- ctls.Run() }()
 	go func() { errCh <- webserver.Serve() }()
 
 	logger.Infof("The sun(%s) has risen, bring out your flowers.", version.Full())
-	openInBrowser(webserver.Endpoint(), logger)
+	if err := notifySystemd(); err != nil {
+		logger.Warnf("Notify systemd failed: %v", err)
+	}
+	if listeners.IsInherited() {
+		if err := listeners.Ready(inheritedListenerTTL); err != nil {
+			logger.Errorf("Notify the previous process failed: %v", err)
+		}
+	} else {
+		openInBrowser(webserver.Endpoint(), logger)
+	}
 
 	sigCh := util.WatchSignals()
-	select {
-	case err := <-errCh:
-		if err != nil {
-			logger.Errorf("Got error: %v", err)
+	restartCh := handoff.WatchSignal()
+	restarting := false
+LOOP:
+	for {
+		select {
+		case err := <-errCh:
+			if err != nil {
+				logger.Errorf("Got error: %v", err)
+			}
+			break LOOP
+		case sig := <-sigCh:
+			logger.Infof("Got signal: %v", sig)
+			break LOOP
+		case <-restartCh:
+			logger.Info("Restarting..")
+			pid, err := restart(listeners)
+			if err != nil {
+				logger.Errorf("Restart failed: %v", err)
+				continue
+			}
+			logger.Infof("The new sun(%d) has risen, draining..", pid)
+			restarting = true
+			break LOOP
 		}
-	case sig := <-sigCh:
-		logger.Infof("Got signal: %v", sig)
 	}
 
 	webserver.Close()
-	ctls.GracefulShutdown()
+	ctls.GracefulShutdown(restarting)
 }
 
 func tryAddAdmin(db *storage.DB) error {
@@ -187,13 +298,17 @@ INPUT_PASSWORD:
 	return nil
 }
 
-func tryStartDebugServer(debugAddr string, logger *zap.SugaredLogger) {
+func tryStartDebugServer(debugAddr string, listeners *handoff.Listeners, logger *zap.SugaredLogger) {
 	if debugAddr == "" {
 		return
 	}
 	debugServer := debug.NewServer(debugAddr)
 	go func() {
-		if err := debugServer.ListenAndServe(); err != nil {
+		l, err := listeners.Listen("tcp", debugAddr)
+		if err == nil {
+			err = debugServer.Serve(l)
+		}
+		if err != nil {
 			logger.Errorf("Start debug server failed: %v", err)
 		}
 	}()
@@ -11,6 +11,7 @@ const (
 	EventOpenTunnel EventType = iota
 	EventCloseTunnel
 	EventRejectAgent
+	EventUpdateTunnel // The error page changed, all tunnels of agent if TunnelHash is empty
 )
 
 type Event struct {
@@ -0,0 +1,198 @@
+package registry
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/yamux"
+
+	"github.com/damnever/sunflower/pkg/bufpool"
+)
+
+const (
+	defaultErrorPageText = `<!DOCTYPE html>
+<html>
+<head><title>{{.Code}} {{.Status}}</title></head>
+<body>
+<center><h1>{{.Code}} {{.Status}}</h1></center>
+<center><p>{{.Reason}}</p></center>
+<hr><center>sunflower</center>
+</body>
+</html>
+`
+	errorResponseHeader = "HTTP/1.1 %d %s\r\nContent-Type: text/html; charset=utf-8\r\nContent-Length: %d\r\nConnection: close\r\n\r\n"
+)
+
+var (
+	defaultErrorPage = template.Must(template.New("error").Parse(defaultErrorPageText))
+
+	errAgentOffline  = fmt.Errorf("agent is offline")
+	errMaxRetry      = fmt.Errorf("max retry exceeded")
+	errLocalNoAnswer = fmt.Errorf("local service refused or closed the connection")
+)
+
+// ErrorPageData is the data used to render error page templates.
+type ErrorPageData struct {
+	Code   int
+	Status string
+	Reason string
+	Tunnel string
+}
+
+// ParseErrorPage parses the custom error page template,
+// it returns the default one if text is empty.
+func ParseErrorPage(text string) (*template.Template, error) {
+	if text == "" {
+		return defaultErrorPage, nil
+	}
+	tmpl, err := template.New("error").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	// Make sure it works with the data we have.
+	data := ErrorPageData{Code: http.StatusBadGateway, Status: http.StatusText(http.StatusBadGateway)}
+	if err := tmpl.Execute(ioutil.Discard, data); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+// errorStatus maps the error to HTTP status code.
+func errorStatus(err error) int {
+	switch err {
+	case yamux.ErrTimeout, yamux.ErrConnectionWriteTimeout:
+		return http.StatusGatewayTimeout
+	case errLocalNoAnswer:
+		return http.StatusBadGateway
+	default:
+		return http.StatusServiceUnavailable
+	}
+}
+
+func writeErrorPage(w io.Writer, tmpl *template.Template, tunnel string, err error) error {
+	code := errorStatus(err)
+	data := ErrorPageData{
+		Code:   code,
+		Status: http.StatusText(code),
+		Reason: err.Error(),
+		Tunnel: tunnel,
+	}
+	buf := bufpool.Get()
+	defer bufpool.Put(buf)
+	if e := tmpl.Execute(buf, data); e != nil {
+		buf.Reset()
+		defaultErrorPage.Execute(buf, data)
+	}
+
+	if _, err := fmt.Fprintf(w, errorResponseHeader, code, data.Status, buf.Len()); err != nil {
+		return err
+	}
+	_, err = buf.WriteTo(w)
+	return err
+}
+
+// respWatcher watches the first response from agent side, the local service
+// is considered unavailable if nothing comes back in timeout after the request
+// forwarded, the timer is rearmed by every write, so that slow uploads are not
+// counted, and it is disarmed once responded, e.g. idle keep-alive connections.
+type respWatcher struct {
+	*yamux.Stream
+	timeout time.Duration
+
+	mu        sync.Mutex
+	timer     *time.Timer
+	timedOut  bool
+	responded bool
+	err       error
+}
+
+func (rw *respWatcher) Write(p []byte) (int, error) {
+	n, err := rw.Stream.Write(p)
+	if n > 0 {
+		rw.mu.Lock()
+		if !rw.responded && !rw.timedOut {
+			if rw.timer == nil {
+				rw.timer = time.AfterFunc(rw.timeout, rw.expire)
+			} else {
+				rw.timer.Reset(rw.timeout)
+			}
+		}
+		rw.mu.Unlock()
+	}
+	return n, err
+}
+
+// expire unblocks the pending Read by closing the stream.
+func (rw *respWatcher) expire() {
+	rw.mu.Lock()
+	if rw.responded {
+		rw.mu.Unlock()
+		return
+	}
+	rw.timedOut = true
+	rw.mu.Unlock()
+	rw.Stream.Close()
+}
+
+func (rw *respWatcher) Read(p []byte) (int, error) {
+	n, err := rw.Stream.Read(p)
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	if rw.responded {
+		return n, err
+	}
+	if rw.timedOut {
+		rw.err = yamux.ErrTimeout
+		return 0, rw.err
+	}
+	if n > 0 {
+		rw.responded = true
+		if rw.timer != nil {
+			rw.timer.Stop()
+		}
+		return n, err
+	}
+	if err == io.EOF {
+		err = errLocalNoAnswer
+	}
+	rw.err = err
+	return n, err
+}
+
+// failure returns the error if nothing responded.
+func (rw *respWatcher) failure() error {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	if rw.timer != nil {
+		rw.timer.Stop()
+	}
+	if rw.responded {
+		return nil
+	}
+	return rw.err
+}
+
+// errorPageConn writes an error page before closing if the agent side
+// has nothing responded, it must be closed by the goroutine which reads
+// the respWatcher.
+type errorPageConn struct {
+	net.Conn
+	once    sync.Once
+	watcher *respWatcher
+	write   func(w io.Writer, err error)
+}
+
+func (c *errorPageConn) Close() error {
+	c.once.Do(func() {
+		if err := c.watcher.failure(); err != nil {
+			c.write(c.Conn, err)
+		}
+	})
+	return c.Conn.Close()
+}
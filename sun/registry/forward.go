@@ -0,0 +1,229 @@
+package registry
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/damnever/sunflower/log"
+	"github.com/damnever/sunflower/msg/msgpb"
+	connutil "github.com/damnever/sunflower/pkg/conn"
+)
+
+var errNoOwner = fmt.Errorf("agent is connected to an unreachable node")
+
+// Forwarder reaches the tunnels registered on the other nodes of cluster,
+// see cluster.Cluster.
+type Forwarder interface {
+	// HasTunnel reports whether the tunnel is registered on another node.
+	HasTunnel(thash string) bool
+	// ForwardHandshake hands the tunnel connection to the node which registered
+	// the tunnel, the handshake request is replayed there.
+	ForwardHandshake(conn net.Conn, req *msgpb.TunnelHandshakeRequest) error
+	// DialTunnel connects to the public side of the tunnel registered on
+	// another node.
+	DialTunnel(ahash, thash string) (net.Conn, error)
+}
+
+// TunnelInfo describes the registered tunnel, the other nodes of cluster
+// forward the public connections of it.
+type TunnelInfo struct {
+	AgentHash  string
+	Hash       string
+	Proto      string
+	ServerAddr string
+}
+
+// Forwardable reports whether the tunnel has a public listener, only those
+// are forwarded by the other nodes of cluster, the visitors of secret tunnels
+// are forwarded by handshakes.
+func (info TunnelInfo) Forwardable() bool {
+	switch strings.ToLower(info.Proto) {
+	case "http", "tcp", "proxy":
+		return true
+	}
+	return false
+}
+
+// forwardTunnel listens on the server address of the tunnel registered on
+// another node of cluster, the connections are linked to that node.
+type forwardTunnel struct {
+	sync.WaitGroup
+	info   TunnelInfo
+	logger *zap.SugaredLogger
+	server net.Listener
+	dial   func(ahash, thash string) (net.Conn, error)
+}
+
+func (ft *forwardTunnel) Serve() error {
+	for {
+		conn, err := ft.server.Accept()
+		if err != nil {
+			return err
+		}
+
+		ft.Add(1)
+		go ft.handleConn(conn)
+	}
+}
+
+func (ft *forwardTunnel) handleConn(conn net.Conn) {
+	defer ft.Done()
+	defer func() {
+		if e := recover(); e != nil {
+			ft.logger.Panicf("Panic: %v", e)
+		}
+	}()
+
+	peerConn, err := ft.dial(ft.info.AgentHash, ft.info.Hash)
+	if err != nil {
+		ft.logger.Errorf("Forward connection failed: %v", err)
+		if strings.ToLower(ft.info.Proto) == "http" {
+			writeErrorPage(conn, defaultErrorPage, ft.info.Hash, errNoOwner)
+		}
+		conn.Close()
+		return
+	}
+	ft.logger.Debugf("Linking forwarded stream: %s<->%s", conn.RemoteAddr(), peerConn.RemoteAddr())
+	connutil.LinkStream(conn, peerConn)
+}
+
+func (ft *forwardTunnel) Close() {
+	ft.server.Close()
+}
+
+// Tunnels returns the tunnels registered on this node.
+func (tr *TCPTunnelRegistry) Tunnels() []TunnelInfo {
+	tr.RLock()
+	defer tr.RUnlock()
+
+	infos := make([]TunnelInfo, 0, len(tr.infos))
+	for _, info := range tr.infos {
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// Forwards returns the tunnels registered on other nodes and forwarded by
+// this node.
+func (tr *TCPTunnelRegistry) Forwards() []TunnelInfo {
+	tr.RLock()
+	defer tr.RUnlock()
+
+	infos := make([]TunnelInfo, 0, len(tr.forwards))
+	for _, ft := range tr.forwards {
+		infos = append(infos, ft.info)
+	}
+	return infos
+}
+
+// Forward listens on the server address of the tunnel registered on another
+// node, the connections are linked to that node, it does nothing if the tunnel
+// is registered or forwarded already.
+func (tr *TCPTunnelRegistry) Forward(info TunnelInfo) error {
+	if !info.Forwardable() || tr.forwarder == nil {
+		return fmt.Errorf("Tunnel can not be forwarded: %s", info.Hash)
+	}
+	tr.Lock()
+	defer tr.Unlock()
+
+	if _, in := tr.infos[info.Hash]; in {
+		return nil
+	}
+	if _, in := tr.forwards[info.Hash]; in {
+		return nil
+	}
+
+	var (
+		l   net.Listener
+		err error
+	)
+	if strings.ToLower(info.Proto) == "http" && tr.httpmuxer != nil {
+		l, err = tr.httpmuxer.Listen(info.ServerAddr)
+	} else {
+		l, err = tr.listen("tcp", info.ServerAddr)
+	}
+	if err != nil {
+		return err
+	}
+	ft := &forwardTunnel{
+		info:   info,
+		logger: log.New("fwd[%s/%s]", info.AgentHash, info.Hash),
+		server: l,
+		dial:   tr.forwarder.DialTunnel,
+	}
+	tr.Add(1)
+	go func() {
+		defer tr.Done()
+		defer ft.Wait()
+		ft.Serve()
+	}()
+
+	tr.forwards[info.Hash] = ft
+	tr.logger.Infof("Tunnel <%8s:%8s> of another node forwarded", info.AgentHash, info.Hash)
+	return nil
+}
+
+// Unforward stops forwarding the tunnel of another node.
+func (tr *TCPTunnelRegistry) Unforward(thash string) bool {
+	tr.Lock()
+	defer tr.Unlock()
+	return tr.unforward(thash)
+}
+
+func (tr *TCPTunnelRegistry) unforward(thash string) bool {
+	ft, in := tr.forwards[thash]
+	if !in {
+		return false
+	}
+	ft.Close()
+	delete(tr.forwards, thash)
+	tr.logger.Infof("Tunnel <%8s:%8s> of another node unforwarded", ft.info.AgentHash, thash)
+	return true
+}
+
+// HandleForwardedHandshake serves the tunnel connection forwarded by another
+// node, it is not forwarded again.
+func (tr *TCPTunnelRegistry) HandleForwardedHandshake(conn net.Conn) {
+	tr.handleIncomingTunnelConn(conn, false)
+}
+
+// HasTunnel reports whether the tunnel is registered on this node.
+func (tr *TCPTunnelRegistry) HasTunnel(ahash, thash string) bool {
+	tr.RLock()
+	defer tr.RUnlock()
+	_, in := tr.tunnels[ahash][thash]
+	return in
+}
+
+// HandleForwardedConn serves the public connection forwarded by another node,
+// false is returned if the tunnel is not registered.
+func (tr *TCPTunnelRegistry) HandleForwardedConn(ahash, thash string, conn net.Conn) bool {
+	tr.RLock()
+	tunnel := tr.tunnels[ahash][thash]
+	tr.RUnlock()
+
+	switch t := tunnel.(type) {
+	case *TCPTunnel:
+		return t.ServeConn(conn)
+	case *HTTPTunnel:
+		return t.ServeConn(conn)
+	}
+	return false
+}
+
+// forwardHandshake hands the connection to the node which registered the
+// tunnel, false is returned if there is none.
+func (tr *TCPTunnelRegistry) forwardHandshake(conn net.Conn, req *msgpb.TunnelHandshakeRequest) bool {
+	if tr.forwarder == nil || !tr.forwarder.HasTunnel(req.TunnelHash) {
+		return false
+	}
+	if err := tr.forwarder.ForwardHandshake(conn, req); err != nil {
+		tr.logger.Warnf("Forward handshake of <%s:%s> failed: %v", req.ClientHash, req.TunnelHash, err)
+		conn.Close()
+	}
+	return true
+}
@@ -38,13 +38,17 @@ func NewHTTPTunnelMuxer(domain string, addr string) (*HTTPTunnelMuxer, error) {
 	if err != nil {
 		return nil, err
 	}
+	return newHTTPTunnelMuxer(domain, l), nil
+}
+
+func newHTTPTunnelMuxer(domain string, l net.Listener) *HTTPTunnelMuxer {
 	return &HTTPTunnelMuxer{
 		logger:   log.New("mux[http]"),
 		l:        l,
 		domain:   fmt.Sprintf(".%s", strings.ToLower(domain)),
 		registry: map[string]*httpConnListener{},
 		closed:   false,
-	}, nil
+	}
 }
 
 func (hm *HTTPTunnelMuxer) Serve() error {
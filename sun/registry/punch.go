@@ -0,0 +1,84 @@
+package registry
+
+import (
+	"net"
+	"time"
+
+	"github.com/damnever/sunflower/msg"
+	"github.com/damnever/sunflower/msg/msgpb"
+	"github.com/damnever/sunflower/pkg/util"
+)
+
+const (
+	punchRendezvousTimeout = 5 * time.Second
+)
+
+// PunchFunc tells the serving agent the address of visitor and the nonce,
+// then the agent comes to the registry with the nonce, it returns false if
+// the request can not be sent.
+type PunchFunc func(peerAddr, nonce string) bool
+
+type punchPeer struct {
+	thash string
+	addr  string      // The visitor address observed by registry
+	ch    chan string // The agent address observed by registry
+}
+
+// rendezvousVisitor asks the serving agent to punch, then waits for it to come,
+// the visitor gets the address of the agent, or falls back to relay if failed.
+func (tr *TCPTunnelRegistry) rendezvousVisitor(conn net.Conn, tunnel *SecretTunnel, req *msgpb.TunnelHandshakeRequest) {
+	defer conn.Close()
+
+	nonce := util.RandString(16)
+	peer := &punchPeer{
+		thash: req.TunnelHash,
+		addr:  conn.RemoteAddr().String(),
+		ch:    make(chan string, 1),
+	}
+	tr.Lock()
+	tr.punches[nonce] = peer
+	tr.Unlock()
+	defer func() {
+		tr.Lock()
+		delete(tr.punches, nonce)
+		tr.Unlock()
+	}()
+
+	resp := msgpb.TunnelHandshakeResponse{ErrCode: msgpb.ErrCodePunchFailed}
+	if tunnel.Punch(peer.addr, nonce) {
+		select {
+		case addr := <-peer.ch:
+			resp = msgpb.TunnelHandshakeResponse{PeerAddr: addr, Nonce: nonce}
+		case <-time.After(punchRendezvousTimeout):
+			tr.logger.Infof("Serving agent of %s does not come for punching", req.TunnelHash)
+		}
+	}
+
+	conn.SetWriteDeadline(time.Now().Add(tr.timeout.Write))
+	if err := msg.Write(conn, resp); err != nil {
+		tr.logger.Warnf("Write rendezvous response to visitor <%s> failed: %v", req.ClientHash, err)
+	}
+}
+
+// rendezvousAgent tells the visitor the address of serving agent.
+func (tr *TCPTunnelRegistry) rendezvousAgent(conn net.Conn, req *msgpb.TunnelHandshakeRequest) {
+	defer conn.Close()
+
+	tr.RLock()
+	peer, in := tr.punches[req.Nonce]
+	tr.RUnlock()
+
+	resp := msgpb.TunnelHandshakeResponse{ErrCode: msgpb.ErrCodePunchFailed}
+	if in && peer.thash == req.TunnelHash {
+		select {
+		case peer.ch <- conn.RemoteAddr().String():
+			resp.ErrCode = msgpb.ErrCodeNull
+		default: // Duplicate
+		}
+	}
+
+	conn.SetWriteDeadline(time.Now().Add(tr.timeout.Write))
+	if err := msg.Write(conn, resp); err != nil {
+		tr.logger.Warnf("Write rendezvous response to <%s:%s> failed: %v", req.ClientHash, req.TunnelHash, err)
+	}
+}
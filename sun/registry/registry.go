@@ -2,6 +2,7 @@ package registry
 
 import (
 	"fmt"
+	"html/template"
 	"net"
 	"strings"
 	"sync"
@@ -17,33 +18,74 @@ import (
 )
 
 type Config struct {
-	IP       string
-	Domain   string
-	HTTPAddr string
-	Timeout  util.TimeoutConfig
+	IP              string
+	Domain          string
+	HTTPAddr        string
+	Listener        net.Listener // Listens on a random port if nil
+	HTTPListener    net.Listener // Listens on HTTPAddr if nil
+	Listen          ListenFunc   // Creates the listeners of TCP tunnels, net.Listen if nil
+	Timeout         util.TimeoutConfig
+	ResponseTimeout time.Duration // The first response of HTTP tunnels
+	ReverseTargets  []string      // The server side addresses reverse tunnels can reach
+	// VerifyAgent reports whether the agent is connected, visitors of secret
+	// tunnels must be, they are not verified if it is nil.
+	VerifyAgent func(id, hash string) bool
+	// Forwarder reaches the tunnels registered on the other nodes of cluster,
+	// nil if not clustered.
+	Forwarder Forwarder
+	// OnChange is called after tunnels registered or deregistered if not nil.
+	OnChange func()
 }
 
+// TunnelOptions describes the tunnel to register.
+type TunnelOptions struct {
+	Proto      string
+	ServerAddr string
+	ErrorPage  string // HTTP only, the default one will be used if it is empty or invalid
+	SecretKey  string // SECRET only, visitors must provide it
+	Punch      PunchFunc
+	TargetAddr string // REVERSE only, must be one of the Config.ReverseTargets
+}
+
+// ListenFunc announces on the local network address, the same as net.Listen.
+type ListenFunc func(network, address string) (net.Listener, error)
+
 type TCPTunnelRegistry struct {
 	sync.RWMutex
 	sync.WaitGroup
 
-	logger    *zap.SugaredLogger
-	timeout   util.TimeoutConfig
-	tunneln   net.Listener
-	tlnAddr   string
-	httpmuxer *HTTPTunnelMuxer
-	tunnels   map[string]map[string]Tunnel
+	logger      *zap.SugaredLogger
+	listen      ListenFunc
+	timeout     util.TimeoutConfig
+	respTimeout time.Duration
+	reverses    map[string]bool
+	verifyAgent func(id, hash string) bool
+	forwarder   Forwarder
+	onChange    func()
+	tunneln     net.Listener
+	tlnAddr     string
+	httpmuxer   *HTTPTunnelMuxer
+	tunnels     map[string]map[string]Tunnel
+	infos       map[string]TunnelInfo     // Indexed by tunnel hash
+	forwards    map[string]*forwardTunnel // Indexed by tunnel hash
+	secrets     map[string]*SecretTunnel  // Indexed by tunnel hash
+	punches     map[string]*punchPeer     // Indexed by nonce
 }
 
 func New(conf Config) (*TCPTunnelRegistry, error) {
-	ln, err := net.Listen("tcp", "0.0.0.0:0")
-	if err != nil {
-		return nil, err
+	var err error
+	ln := conf.Listener
+	if ln == nil {
+		if ln, err = net.Listen("tcp", "0.0.0.0:0"); err != nil {
+			return nil, err
+		}
 	}
 
 	var muxer *HTTPTunnelMuxer
 	if conf.Domain != "" {
-		if muxer, err = NewHTTPTunnelMuxer(conf.Domain, conf.HTTPAddr); err != nil {
+		if conf.HTTPListener != nil {
+			muxer = newHTTPTunnelMuxer(conf.Domain, conf.HTTPListener)
+		} else if muxer, err = NewHTTPTunnelMuxer(conf.Domain, conf.HTTPAddr); err != nil {
 			ln.Close()
 			return nil, err
 		}
@@ -58,13 +100,31 @@ func New(conf Config) (*TCPTunnelRegistry, error) {
 		return nil, err
 	}
 	lnAddr := fmt.Sprintf("%s:%s", conf.IP, port)
+	reverses := make(map[string]bool, len(conf.ReverseTargets))
+	for _, addr := range conf.ReverseTargets {
+		reverses[addr] = true
+	}
+	listen := conf.Listen
+	if listen == nil {
+		listen = net.Listen
+	}
 	return &TCPTunnelRegistry{
-		timeout:   conf.Timeout,
-		logger:    log.New("reg[tcp]"),
-		tunneln:   ln,
-		tlnAddr:   lnAddr,
-		httpmuxer: muxer,
-		tunnels:   map[string]map[string]Tunnel{},
+		listen:      listen,
+		timeout:     conf.Timeout,
+		respTimeout: conf.ResponseTimeout,
+		reverses:    reverses,
+		verifyAgent: conf.VerifyAgent,
+		forwarder:   conf.Forwarder,
+		onChange:    conf.OnChange,
+		logger:      log.New("reg[tcp]"),
+		tunneln:     ln,
+		tlnAddr:     lnAddr,
+		httpmuxer:   muxer,
+		tunnels:     map[string]map[string]Tunnel{},
+		infos:       map[string]TunnelInfo{},
+		forwards:    map[string]*forwardTunnel{},
+		secrets:     map[string]*SecretTunnel{},
+		punches:     map[string]*punchPeer{},
 	}, nil
 }
 
@@ -89,11 +149,19 @@ func (tr *TCPTunnelRegistry) serveIncomingTunnel() error {
 		if err != nil {
 			return err
 		}
-		go tr.handleIncomingTunnelConn(conn)
+		go tr.handleIncomingTunnelConn(conn, true)
 	}
 }
 
-func (tr *TCPTunnelRegistry) handleIncomingTunnelConn(conn net.Conn) {
+// HandleConn serves the tunnel connection accepted elsewhere, e.g. over WebSocket.
+func (tr *TCPTunnelRegistry) HandleConn(conn net.Conn) {
+	tr.handleIncomingTunnelConn(conn, true)
+}
+
+// handleIncomingTunnelConn serves the tunnel connection, it is handed to
+// the node which registered the tunnel if forward is true and it is not
+// registered here.
+func (tr *TCPTunnelRegistry) handleIncomingTunnelConn(conn net.Conn, forward bool) {
 	// One connection per tunnel, if server side found duplicate
 	// connection from client, simply close the connection
 	var req msgpb.TunnelHandshakeRequest
@@ -103,6 +171,14 @@ func (tr *TCPTunnelRegistry) handleIncomingTunnelConn(conn net.Conn) {
 		conn.Close()
 		return
 	}
+	if req.Visitor {
+		tr.handleVisitorConn(conn, &req, forward)
+		return
+	}
+	if req.Punch {
+		tr.rendezvousAgent(conn, &req)
+		return
+	}
 
 	resp := msgpb.TunnelHandshakeResponse{}
 	tr.RLock()
@@ -112,6 +188,9 @@ func (tr *TCPTunnelRegistry) handleIncomingTunnelConn(conn net.Conn) {
 	}
 	tr.RUnlock()
 
+	if tunnel == nil && forward && tr.forwardHandshake(conn, &req) {
+		return
+	}
 	if tunnel == nil {
 		tr.logger.Infof("No tunnel registered for: <%s:%s>", req.ClientHash, req.TunnelHash)
 		resp.ErrCode = msgpb.ErrCodeNoSuchTunnel
@@ -130,11 +209,64 @@ func (tr *TCPTunnelRegistry) handleIncomingTunnelConn(conn net.Conn) {
 	}
 }
 
-func (tr *TCPTunnelRegistry) Register(tracker *tracker.TunnelTracker, proto, serverAddr string) error {
+// handleVisitorConn pairs the visitor with the secret tunnel it asked for,
+// the connection is kept until either side goes away.
+func (tr *TCPTunnelRegistry) handleVisitorConn(conn net.Conn, req *msgpb.TunnelHandshakeRequest, forward bool) {
+	tr.RLock()
+	tunnel := tr.secrets[req.TunnelHash]
+	tr.RUnlock()
+
+	if tunnel == nil && forward && !req.Punch && tr.forwardHandshake(conn, req) {
+		return
+	}
+
+	resp := msgpb.TunnelHandshakeResponse{}
+	if tr.verifyAgent != nil && !tr.verifyAgent(req.ID, req.ClientHash) {
+		tr.logger.Warnf("Visitor <%s> is not a connected agent: %s", req.ClientHash, req.TunnelHash)
+		resp.ErrCode = msgpb.ErrCodeBadClient
+	} else if tunnel == nil && forward && tr.forwarder != nil && tr.forwarder.HasTunnel(req.TunnelHash) {
+		// Punching is relayed by the node which registered the tunnel,
+		// the addresses observed here are useless.
+		tr.logger.Infof("Secret tunnel of visitor <%s> is registered on another node: %s", req.ClientHash, req.TunnelHash)
+		resp.ErrCode = msgpb.ErrCodePunchFailed
+	} else if tunnel == nil {
+		tr.logger.Infof("No secret tunnel registered for visitor <%s>: %s", req.ClientHash, req.TunnelHash)
+		resp.ErrCode = msgpb.ErrCodeNoSuchTunnel
+	} else if !tunnel.Auth(req.SecretKey) {
+		tr.logger.Warnf("Visitor <%s> provided bad secret key for: %s", req.ClientHash, req.TunnelHash)
+		resp.ErrCode = msgpb.ErrCodeBadSecretKey
+	} else if req.Punch {
+		tr.rendezvousVisitor(conn, tunnel, req)
+		return
+	}
+
+	conn.SetWriteDeadline(time.Now().Add(tr.timeout.Write))
+	if err := msg.Write(conn, resp); err != nil || resp.ErrCode != msgpb.ErrCodeNull {
+		if err != nil {
+			tr.logger.Warnf("Write handshake response to visitor <%s> failed: %v", req.ClientHash, err)
+		}
+		conn.Close()
+		return
+	}
+
+	conn.SetDeadline(time.Time{})
+	tr.logger.Infof("Visitor <%s> paired with: %s", req.ClientHash, req.TunnelHash)
+	if err := tunnel.Visit(conn); err != nil {
+		tr.logger.Errorf("Serve visitor <%s> failed: %v", req.ClientHash, err)
+		conn.Close()
+		return
+	}
+	tr.logger.Infof("Visitor <%s> left: %s", req.ClientHash, req.TunnelHash)
+}
+
+// Register registers a tunnel described by opts.
+func (tr *TCPTunnelRegistry) Register(tracker *tracker.TunnelTracker, opts TunnelOptions) error {
 	ahash, thash := tracker.AgentHash(), tracker.Hash()
 	tr.Lock()
 	defer tr.Unlock()
 
+	// The agent has moved from another node.
+	tr.unforward(thash)
 	etunnels, in := tr.tunnels[ahash]
 	if !in {
 		etunnels = make(map[string]Tunnel, 5)
@@ -144,10 +276,13 @@ func (tr *TCPTunnelRegistry) Register(tracker *tracker.TunnelTracker, proto, ser
 		return nil
 	}
 
-	tunnel, err := tr.makeTunnel(tracker, proto, serverAddr)
+	tunnel, err := tr.makeTunnel(tracker, opts)
 	if err != nil {
 		return err
 	}
+	if st, ok := tunnel.(*SecretTunnel); ok {
+		tr.secrets[thash] = st
+	}
 
 	tr.Add(1)
 	go func() {
@@ -158,25 +293,38 @@ func (tr *TCPTunnelRegistry) Register(tracker *tracker.TunnelTracker, proto, ser
 	}()
 
 	etunnels[thash] = tunnel
+	tr.infos[thash] = TunnelInfo{AgentHash: ahash, Hash: thash, Proto: opts.Proto, ServerAddr: opts.ServerAddr}
 	tr.logger.Infof("New tunnel <%8s:%8s> registered", ahash, thash)
+	tr.changed()
 	return nil
 }
 
-func (tr *TCPTunnelRegistry) makeTunnel(tracker *tracker.TunnelTracker, proto, serverAddr string) (Tunnel, error) {
+func (tr *TCPTunnelRegistry) makeTunnel(tracker *tracker.TunnelTracker, opts TunnelOptions) (Tunnel, error) {
 	var (
 		tunnel Tunnel
 		err    error
 	)
-	proto = strings.ToLower(proto)
+	proto, serverAddr := strings.ToLower(opts.Proto), opts.ServerAddr
 	switch proto {
-	case "http", "tcp":
+	case "http", "tcp", "proxy":
 		if proto == "http" && tr.httpmuxer != nil {
 			var l net.Listener
+			tmpl := tr.parseErrorPage(tracker.Hash(), opts.ErrorPage)
 			if l, err = tr.httpmuxer.Listen(serverAddr); err == nil {
-				tunnel = NewHTTPTunnel(tracker, l)
+				tunnel = NewHTTPTunnel(tracker, l, tmpl, tr.respTimeout)
 			}
 		} else {
-			tunnel, err = NewTCPTunnel(tracker, serverAddr)
+			tunnel, err = NewTCPTunnel(tracker, tr.listen, serverAddr)
+		}
+	case "secret":
+		tunnel = NewSecretTunnel(tracker, opts.SecretKey, opts.Punch)
+	case "reverse":
+		// The targets may have been changed by admin since the tunnel created.
+		if !tr.reverses[opts.TargetAddr] {
+			tracker.OnError(fmt.Sprintf("target address %s is not allowed", opts.TargetAddr))
+			err = fmt.Errorf("Reverse target is not allowed: %s", opts.TargetAddr)
+		} else {
+			tunnel = NewReverseTunnel(tracker, opts.TargetAddr, tr.timeout.Connect)
 		}
 	default:
 		err = fmt.Errorf("Unsupported protocol: %s", proto)
@@ -184,6 +332,28 @@ func (tr *TCPTunnelRegistry) makeTunnel(tracker *tracker.TunnelTracker, proto, s
 	return tunnel, err
 }
 
+func (tr *TCPTunnelRegistry) parseErrorPage(thash, text string) *template.Template {
+	tmpl, err := ParseErrorPage(text)
+	if err != nil {
+		tr.logger.Warnf("Bad error page of tunnel %s, use the default one: %v", thash, err)
+		return defaultErrorPage
+	}
+	return tmpl
+}
+
+// UpdateErrorPage replaces the error page of the registered HTTP tunnel,
+// the default one will be used if text is empty or invalid.
+func (tr *TCPTunnelRegistry) UpdateErrorPage(ahash, thash, text string) bool {
+	tr.RLock()
+	defer tr.RUnlock()
+
+	if ht, ok := tr.tunnels[ahash][thash].(*HTTPTunnel); ok {
+		ht.SetErrorPage(tr.parseErrorPage(thash, text))
+		return true
+	}
+	return false
+}
+
 func (tr *TCPTunnelRegistry) Deregister(ahash, thash string) bool {
 	tr.Lock()
 	defer tr.Unlock()
@@ -193,6 +363,9 @@ func (tr *TCPTunnelRegistry) Deregister(ahash, thash string) bool {
 			tr.logger.Infof("Tunnel <%8s:%8s> deregistered", ahash, thash)
 			tunnel.Close()
 			delete(etunnels, thash)
+			delete(tr.infos, thash)
+			delete(tr.secrets, thash)
+			tr.changed()
 			return true
 		}
 	}
@@ -211,11 +384,21 @@ func (tr *TCPTunnelRegistry) DeregisterAll(ahash string) bool {
 	for thash, tunnel := range etunnels {
 		tr.logger.Infof("Tunnel <%s:%s> deregistered", ahash, thash)
 		tunnel.Close()
+		delete(tr.infos, thash)
+		delete(tr.secrets, thash)
 	}
 	delete(tr.tunnels, ahash)
+	tr.changed()
 	return true
 }
 
+// changed tells the change of tunnels, it is called with lock held.
+func (tr *TCPTunnelRegistry) changed() {
+	if tr.onChange != nil {
+		tr.onChange()
+	}
+}
+
 func (tr *TCPTunnelRegistry) Close() {
 	tr.Lock()
 	tr.tunneln.Close()
@@ -228,6 +411,9 @@ func (tr *TCPTunnelRegistry) Close() {
 			tunnel.Close()
 		}
 	}
+	for _, ft := range tr.forwards {
+		ft.Close()
+	}
 	tr.Unlock()
 }
 
@@ -0,0 +1,77 @@
+package registry
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	connutil "github.com/damnever/sunflower/pkg/conn"
+	"github.com/damnever/sunflower/sun/tracker"
+)
+
+// ReverseTunnel works in the opposite direction, the agent listens locally
+// and opens a stream for each connection, which will be linked to the
+// target address on server side.
+type ReverseTunnel struct {
+	*tcpBasedTunnel
+	targetAddr  string
+	dialTimeout time.Duration
+	streams     *streamListener
+}
+
+func NewReverseTunnel(tracker *tracker.TunnelTracker, targetAddr string, dialTimeout time.Duration) *ReverseTunnel {
+	l := newStreamListener("reverse", tracker.Hash())
+	return &ReverseTunnel{
+		tcpBasedTunnel: newTCPBasedTunnel(tracker, l),
+		targetAddr:     targetAddr,
+		dialTimeout:    dialTimeout,
+		streams:        l,
+	}
+}
+
+func (rt *ReverseTunnel) NewSession(conn net.Conn) bool {
+	if !rt.tcpBasedTunnel.NewSession(conn) {
+		return false
+	}
+	rt.RLock()
+	session := rt.session
+	rt.RUnlock()
+	go rt.streams.serve(session)
+	return true
+}
+
+func (rt *ReverseTunnel) Serve() error {
+	for {
+		stream, err := rt.server.Accept()
+		if err != nil {
+			return err
+		}
+
+		rt.Add(1)
+		go rt.handleStream(stream)
+	}
+}
+
+func (rt *ReverseTunnel) handleStream(stream net.Conn) {
+	defer rt.Done()
+	defer func() {
+		if e := recover(); e != nil {
+			rt.logger.Panicf("Panic: %v", e)
+		}
+	}()
+	rt.tracker.IncrConn()
+	defer rt.tracker.DecrConn()
+
+	conn, err := net.DialTimeout("tcp", rt.targetAddr, rt.dialTimeout)
+	if err != nil {
+		stream.Close()
+		rt.logger.Errorf("Connect to %s failed: %v", rt.targetAddr, err)
+		rt.tracker.OnError(fmt.Sprintf("connect to target: %v", err))
+		return
+	}
+
+	rt.logger.Infof("Linking stream: %s<->%s", conn.RemoteAddr(), stream.RemoteAddr())
+	in, out := connutil.LinkStream(stream, conn)
+	rt.tracker.RecordTraffic(in, out)
+	rt.logger.Infof("Linked stream closed")
+}
@@ -0,0 +1,144 @@
+package registry
+
+import (
+	"crypto/subtle"
+	"net"
+	"sync"
+
+	"github.com/hashicorp/yamux"
+
+	"github.com/damnever/sunflower/sun/tracker"
+)
+
+// SecretTunnel has no public listener, only the visitors which hold the
+// secret key can reach it through the registry, each stream opened by
+// visitors will be linked to a stream of the serving agent.
+type SecretTunnel struct {
+	*tcpBasedTunnel
+	secretKey string
+	punch     PunchFunc
+	visitors  *streamListener
+}
+
+// NewSecretTunnel creates a SecretTunnel, punch is used to ask the serving
+// agent to punch to visitors, peer-to-peer mode is disabled if it is nil.
+func NewSecretTunnel(tracker *tracker.TunnelTracker, secretKey string, punch PunchFunc) *SecretTunnel {
+	l := newStreamListener("secret", tracker.Hash())
+	return &SecretTunnel{
+		tcpBasedTunnel: newTCPBasedTunnel(tracker, l),
+		secretKey:      secretKey,
+		punch:          punch,
+		visitors:       l,
+	}
+}
+
+func (st *SecretTunnel) Auth(secretKey string) bool {
+	return subtle.ConstantTimeCompare([]byte(secretKey), []byte(st.secretKey)) == 1
+}
+
+// Punch asks the serving agent to punch to the visitor at peerAddr,
+// false will be returned if it is impossible.
+func (st *SecretTunnel) Punch(peerAddr, nonce string) bool {
+	if st.punch == nil {
+		return false
+	}
+	return st.punch(peerAddr, nonce)
+}
+
+// Visit serves the connection from visitor, it returns after the
+// connection or tunnel has been closed.
+func (st *SecretTunnel) Visit(conn net.Conn) error {
+	session, err := yamux.Server(conn, yamux.DefaultConfig())
+	if err != nil {
+		return err
+	}
+	st.tracker.IsRelayed()
+	st.visitors.serve(session)
+	return nil
+}
+
+// streamListener turns the streams opened by the other side of sessions
+// into connections, e.g. visitors of secret tunnels.
+type streamListener struct {
+	sync.Mutex
+	addr     streamAddr
+	connCh   chan net.Conn
+	sessions map[*yamux.Session]struct{}
+	done     chan struct{}
+	closed   bool
+}
+
+func newStreamListener(network, name string) *streamListener {
+	return &streamListener{
+		addr:     streamAddr{network: network, name: name},
+		connCh:   make(chan net.Conn),
+		sessions: map[*yamux.Session]struct{}{},
+		done:     make(chan struct{}),
+	}
+}
+
+func (sl *streamListener) serve(session *yamux.Session) {
+	sl.Lock()
+	if sl.closed {
+		sl.Unlock()
+		session.Close()
+		return
+	}
+	sl.sessions[session] = struct{}{}
+	sl.Unlock()
+
+	defer func() {
+		sl.Lock()
+		delete(sl.sessions, session)
+		sl.Unlock()
+		session.Close()
+	}()
+
+	for {
+		stream, err := session.AcceptStream()
+		if err != nil {
+			return
+		}
+		select {
+		case sl.connCh <- stream:
+		case <-sl.done:
+			stream.Close()
+			return
+		}
+	}
+}
+
+func (sl *streamListener) Accept() (net.Conn, error) {
+	select {
+	case <-sl.done:
+		return nil, errClosed
+	case conn := <-sl.connCh:
+		return conn, nil
+	}
+}
+
+func (sl *streamListener) Addr() net.Addr {
+	return sl.addr
+}
+
+func (sl *streamListener) Close() error {
+	sl.Lock()
+	defer sl.Unlock()
+	if sl.closed {
+		return nil
+	}
+	sl.closed = true
+	close(sl.done)
+	for session := range sl.sessions {
+		session.Close()
+	}
+	return nil
+}
+
+type streamAddr struct {
+	network string
+	name    string
+}
+
+func (a streamAddr) Network() string { return a.network }
+func (a streamAddr) String() string  { return a.name }
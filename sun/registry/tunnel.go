@@ -2,6 +2,8 @@ package registry
 
 import (
 	"fmt"
+	"html/template"
+	"io"
 	"net"
 	"sync"
 	"time"
@@ -16,6 +18,10 @@ import (
 
 // TODO(damnever): close then clean up
 
+// The agent connects after the tunnel opened, or after the previous process
+// handed off the listener, the public connections wait for it a while.
+const firstSessionTimeout = 5 * time.Second
+
 type Tunnel interface {
 	NewSession(conn net.Conn) bool
 	Serve() error
@@ -27,8 +33,8 @@ type TCPTunnel struct {
 	*tcpBasedTunnel
 }
 
-func NewTCPTunnel(tracker *tracker.TunnelTracker, serverAddr string) (*TCPTunnel, error) {
-	l, err := net.Listen("tcp", serverAddr)
+func NewTCPTunnel(tracker *tracker.TunnelTracker, listen ListenFunc, serverAddr string) (*TCPTunnel, error) {
+	l, err := listen("tcp", serverAddr)
 	if err != nil {
 		tracker.OnError(fmt.Sprintf("listen on server address: %v", err))
 		return nil, err
@@ -42,9 +48,15 @@ type HTTPTunnel struct {
 	*tcpBasedTunnel
 }
 
-func NewHTTPTunnel(tracker *tracker.TunnelTracker, l net.Listener) *HTTPTunnel {
+// NewHTTPTunnel creates a HTTPTunnel, errPage will be rendered if agent or
+// local service is unavailable, respTimeout limits the time to wait for
+// the first response from the local service after the request forwarded.
+func NewHTTPTunnel(tracker *tracker.TunnelTracker, l net.Listener, errPage *template.Template, respTimeout time.Duration) *HTTPTunnel {
+	tunnel := newTCPBasedTunnel(tracker, l)
+	tunnel.errPage = errPage
+	tunnel.respTimeout = respTimeout
 	return &HTTPTunnel{
-		tcpBasedTunnel: newTCPBasedTunnel(tracker, l),
+		tcpBasedTunnel: tunnel,
 	}
 }
 
@@ -55,9 +67,14 @@ type tcpBasedTunnel struct {
 	logger  *zap.SugaredLogger
 	server  net.Listener
 	session *yamux.Session
-	sesVer  uint64 // Make session versionable, since OpenStream() may block the entire lock process
+	sesVer  uint64        // Make session versionable, since OpenStream() may block the entire lock process
+	opened  chan struct{} // Closed once the first session opened
 	closed  bool
 	tracker *tracker.TunnelTracker
+
+	// HTTP only
+	errPage     *template.Template
+	respTimeout time.Duration
 }
 
 func newTCPBasedTunnel(tracker *tracker.TunnelTracker, l net.Listener) *tcpBasedTunnel {
@@ -68,6 +85,7 @@ func newTCPBasedTunnel(tracker *tracker.TunnelTracker, l net.Listener) *tcpBased
 		server:  l,
 		session: nil,
 		sesVer:  0,
+		opened:  make(chan struct{}),
 		closed:  false,
 	}
 }
@@ -93,6 +111,9 @@ func (tt *tcpBasedTunnel) NewSession(conn net.Conn) bool {
 	}
 	tt.sesVer += 1 // Overflow?? no such thing..
 	tt.session = session
+	if tt.sesVer == 1 {
+		close(tt.opened)
+	}
 	tt.logger.Debugf("Open session success")
 	return true
 }
@@ -101,12 +122,15 @@ func (tt *tcpBasedTunnel) getStream(retry int) (*yamux.Stream, error) {
 	tt.RLock()
 	session, version := tt.session, tt.sesVer
 	tt.RUnlock()
+	if version == 0 {
+		session, version = tt.waitFirstSession()
+	}
 
 	for i := 0; i < retry; i++ {
 		if session == nil {
-			// XXX(damnever): Update such status by client side?
-			tt.tracker.OnError("local address may be not working")
-			return nil, fmt.Errorf("local proxy has no activity")
+			// Failures of local service are reported by agent side.
+			tt.tracker.OnError("agent has no active session")
+			return nil, errAgentOffline
 		}
 		stream, err := session.OpenStream() // May block here if too many packet in flight
 		if err == nil {
@@ -118,7 +142,17 @@ func (tt *tcpBasedTunnel) getStream(retry int) (*yamux.Stream, error) {
 		}
 		session = tt.tryInvalidSession(version)
 	}
-	return nil, fmt.Errorf("max retry exceeded")
+	return nil, errMaxRetry
+}
+
+func (tt *tcpBasedTunnel) waitFirstSession() (*yamux.Session, uint64) {
+	select {
+	case <-tt.opened:
+	case <-time.After(firstSessionTimeout):
+	}
+	tt.RLock()
+	defer tt.RUnlock()
+	return tt.session, tt.sesVer
 }
 
 func (tt *tcpBasedTunnel) tryInvalidSession(version uint64) *yamux.Session {
@@ -142,6 +176,19 @@ func (tt *tcpBasedTunnel) Serve() error {
 	}
 }
 
+// ServeConn serves the connection accepted elsewhere, e.g. forwarded by
+// another node of cluster, false is returned if the tunnel is closed.
+func (tt *tcpBasedTunnel) ServeConn(conn net.Conn) bool {
+	tt.Lock()
+	defer tt.Unlock()
+	if tt.closed {
+		return false
+	}
+	tt.Add(1)
+	go tt.handleConn(conn)
+	return true
+}
+
 func (tt *tcpBasedTunnel) handleConn(conn net.Conn) {
 	defer tt.Done()
 	defer func() {
@@ -152,19 +199,54 @@ func (tt *tcpBasedTunnel) handleConn(conn net.Conn) {
 	tt.tracker.IncrConn()
 	defer tt.tracker.DecrConn()
 
+	errPage := tt.errorPage()
 	stream, err := tt.getStream(2)
-	if err != nil { // Write error message according to protocol
+	if err != nil {
 		tt.logger.Errorf("Open stream failed: %v", err)
+		if errPage != nil {
+			tt.writeErrorPage(conn, err)
+		}
+		conn.Close()
 		return
 	}
 
+	var agentConn net.Conn = stream
+	if errPage != nil {
+		watcher := &respWatcher{Stream: stream, timeout: tt.respTimeout}
+		conn = &errorPageConn{Conn: conn, watcher: watcher, write: tt.writeErrorPage}
+		agentConn = watcher
+	}
+
 	streamID := stream.StreamID()
 	tt.logger.Infof("[%d] Linking stream: %s<->%s", streamID, stream.LocalAddr(), conn.LocalAddr())
-	in, out := connutil.LinkStream(conn, stream)
+	in, out := connutil.LinkStream(conn, agentConn)
 	tt.tracker.RecordTraffic(in, out)
 	tt.logger.Infof("[%d] Linked stream closed", streamID)
 }
 
+func (tt *tcpBasedTunnel) writeErrorPage(w io.Writer, err error) {
+	if err == errLocalNoAnswer {
+		tt.tracker.OnError(err.Error())
+	}
+	if e := writeErrorPage(w, tt.errorPage(), tt.tracker.Hash(), err); e != nil {
+		tt.logger.Warnf("Write error page failed: %v", e)
+	}
+}
+
+func (tt *tcpBasedTunnel) errorPage() *template.Template {
+	tt.RLock()
+	defer tt.RUnlock()
+	return tt.errPage
+}
+
+// SetErrorPage replaces the error page, the new one applies to the
+// requests coming afterwards.
+func (ht *HTTPTunnel) SetErrorPage(errPage *template.Template) {
+	ht.Lock()
+	ht.errPage = errPage
+	ht.Unlock()
+}
+
 // Close closes the listener and set the closed flag,
 // then no more new requests could be processed.
 func (tt *tcpBasedTunnel) Close() {
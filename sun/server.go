@@ -3,6 +3,7 @@ package sun
 import (
 	"context"
 	"fmt"
+	"net"
 	"sync"
 	"time"
 
@@ -11,6 +12,7 @@ import (
 	"github.com/damnever/sunflower/birpc"
 	"github.com/damnever/sunflower/log"
 	"github.com/damnever/sunflower/msg/msgpb"
+	"github.com/damnever/sunflower/sun/cluster"
 	"github.com/damnever/sunflower/sun/pubsub"
 	"github.com/damnever/sunflower/sun/registry"
 	"github.com/damnever/sunflower/sun/storage"
@@ -18,6 +20,11 @@ import (
 	"github.com/damnever/sunflower/version"
 )
 
+// tunnelDeclarer creates the tunnels declared by agents, see web.Server.
+type tunnelDeclarer interface {
+	DeclareTunnel(username, ahash string, fields map[string]string) (storage.Tunnel, error)
+}
+
 type CtlServer struct {
 	sync.Mutex
 	logger           *zap.SugaredLogger
@@ -26,29 +33,47 @@ type CtlServer struct {
 	reg              *registry.TCPTunnelRegistry
 	sub              pubsub.Subscriber
 	filter           map[string]bool
+	clients          sync.WaitGroup
 	done             chan struct{}
+	stopped          chan struct{} // Closed after Run returned
 	tracker          *tracker.Tracker
+	declarer         tunnelDeclarer
+	cluster          *cluster.Cluster // Nil if not running as a cluster
 	gracefulShutdown time.Duration
+	reconnectDelay   time.Duration
+	// Sent to agents once done is closed, it is written before that.
+	notice msgpb.ShutdownRequest
 }
 
-func NewCtlServer(conf Config, sub pubsub.Subscriber, db *storage.DB) (*CtlServer, error) {
-	reg, err := registry.New(conf.MuxRegConf)
-	if err != nil {
-		return nil, err
-	}
-
+// NewCtlServer creates the control server, cl is nil if not running as a cluster.
+func NewCtlServer(conf Config, sub pubsub.Subscriber, db *storage.DB, declarer tunnelDeclarer, cl *cluster.Cluster) (*CtlServer, error) {
 	s := &CtlServer{
 		logger:           log.New("S"),
 		db:               db,
-		reg:              reg,
 		sub:              sub,
 		filter:           map[string]bool{},
 		tracker:          tracker.New(db),
+		declarer:         declarer,
+		cluster:          cl,
 		done:             make(chan struct{}),
+		stopped:          make(chan struct{}),
 		gracefulShutdown: conf.GracefulShutdown,
+		reconnectDelay:   conf.ReconnectDelay,
 	}
 
+	conf.MuxRegConf.VerifyAgent = s.isConnected
+	if cl != nil {
+		conf.MuxRegConf.Forwarder = cl
+		conf.MuxRegConf.OnChange = cl.Changed
+	}
+	reg, err := registry.New(conf.MuxRegConf)
+	if err != nil {
+		return nil, err
+	}
+	s.reg = reg
+
 	conf.RPCConf.ValidateFunc = s.ValidateClient
+	conf.RPCConf.RegistryAddr = reg.ListenAddr()
 	server, err := birpc.NewServer(&conf.RPCConf)
 	if err != nil {
 		return nil, err
@@ -62,7 +87,7 @@ func (s *CtlServer) ValidateClient(id, hash, device, ver string) msgpb.ErrCode {
 	defer s.Unlock()
 
 	key := fmt.Sprintf("%s:%s", id, hash)
-	if s.filter[key] {
+	if s.filter[key] || (s.cluster != nil && s.cluster.HasAgent(id, hash)) {
 		s.logger.Warnf("Agent (%s %s) already connected", id, hash)
 		return msgpb.ErrCodeDuplicateAgent
 	}
@@ -84,26 +109,76 @@ func (s *CtlServer) ValidateClient(id, hash, device, ver string) msgpb.ErrCode {
 		return msgpb.ErrCodeBadClient
 	}
 	s.filter[key] = true
+	s.changed()
 	return msgpb.ErrCodeNull
 }
 
+// HandleControlConn serves the control connection carried by WebSocket.
+func (s *CtlServer) HandleControlConn(conn net.Conn) {
+	s.server.HandleConn(conn)
+}
+
+// HandleTunnelConn serves the tunnel connection carried by WebSocket.
+func (s *CtlServer) HandleTunnelConn(conn net.Conn) {
+	s.reg.HandleConn(conn)
+}
+
+// isConnected reports whether the agent has connected to the control server,
+// or the one of another node, since the visitors are forwarded to the node
+// which registered the secret tunnel.
+func (s *CtlServer) isConnected(id, hash string) bool {
+	s.Lock()
+	connected := s.filter[fmt.Sprintf("%s:%s", id, hash)]
+	s.Unlock()
+	return connected || (s.cluster != nil && s.cluster.HasAgent(id, hash))
+}
+
 func (s *CtlServer) removeFilter(id, hash string) {
 	s.Lock()
 	delete(s.filter, fmt.Sprintf("%s:%s", id, hash))
+	s.changed()
 	s.Unlock()
 }
 
+// changed tells the other nodes of cluster that the agents connected changed.
+func (s *CtlServer) changed() {
+	if s.cluster != nil {
+		s.cluster.Changed()
+	}
+}
+
+// Agents returns the agents connected, see cluster.Node.
+func (s *CtlServer) Agents() []string {
+	s.Lock()
+	defer s.Unlock()
+	agents := make([]string, 0, len(s.filter))
+	for key := range s.filter {
+		agents = append(agents, key)
+	}
+	return agents
+}
+
+func (s *CtlServer) Registry() *registry.TCPTunnelRegistry {
+	return s.reg
+}
+
 func (s *CtlServer) Run() error {
+	defer close(s.stopped)
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	errCh := make(chan error, 2)
+	errCh := make(chan error, 3)
 	// Tunnel registry
 	go func() { errCh <- s.reg.Serve() }()
 	defer s.reg.Close()
 	// Control server
 	go func() { errCh <- s.server.Serve() }()
 	defer s.server.Close()
+	// Cluster, closed before the registry
+	if s.cluster != nil {
+		go func() { errCh <- s.cluster.Run(s) }()
+		defer s.cluster.Close()
+	}
 
 	for {
 		select {
@@ -119,9 +194,13 @@ func (s *CtlServer) Run() error {
 				reg:        s.reg,
 				sub:        s.sub,
 				db:         s.db,
+				declarer:   s.declarer,
+				notice:     &s.notice,
 				logger:     log.New("ctl[%s]", conn.Hash),
 			}
+			s.clients.Add(1)
 			go func() {
+				defer s.clients.Done()
 				defer s.removeFilter(conn.ID, conn.Hash)
 				cli.Run(ctx)
 			}()
@@ -129,12 +208,21 @@ func (s *CtlServer) Run() error {
 	}
 }
 
-func (s *CtlServer) GracefulShutdown() {
+// GracefulShutdown stops accepting agents and public connections, tells the
+// connected agents to reconnect, to this server again if restarting, then
+// waits for the in-flight streams until deadline.
+func (s *CtlServer) GracefulShutdown(restarting bool) {
+	s.notice = msgpb.ShutdownRequest{Reconnect: true, Restart: restarting}
+	if !restarting && s.cluster == nil { // The other nodes of cluster take over at once
+		s.notice.ReconnectDelay = uint32(s.reconnectDelay / time.Millisecond)
+	}
 	close(s.done)
 
 	s.logger.Info("Graceful shutdown..")
 	done := make(chan struct{})
 	go func() {
+		<-s.stopped
+		s.clients.Wait() // Agents have been told
 		s.reg.WaitDone()
 		close(done)
 	}()
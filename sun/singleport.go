@@ -0,0 +1,64 @@
+package sun
+
+import (
+	"crypto/tls"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/damnever/sunflower/msg"
+	"github.com/damnever/sunflower/pkg/connmux"
+	"github.com/damnever/sunflower/sun/registry"
+	"github.com/damnever/sunflower/sun/web"
+)
+
+const protocolDetectTimeout = 5 * time.Second
+
+// listenSinglePort listens on the single port, then replaces the listeners
+// of control server, tunnel registry, HTTP muxer and control panel with
+// the ones dispatched by the protocol detected.
+func listenSinglePort(coreconf *Config, webconf *web.Config, listen registry.ListenFunc) (*connmux.Mux, error) {
+	conf := coreconf.SinglePort
+	var tlsConf *tls.Config
+	if conf.TLSCert != "" {
+		cert, err := tls.LoadX509KeyPair(conf.TLSCert, conf.TLSKey)
+		if err != nil {
+			return nil, err
+		}
+		tlsConf = &tls.Config{Certificates: []tls.Certificate{cert}}
+	}
+	l, err := listen("tcp", conf.Addr)
+	if err != nil {
+		return nil, err
+	}
+
+	mux := connmux.New(l, protocolDetectTimeout)
+	if tlsConf != nil {
+		tlsl := mux.Match(connmux.TLS())
+		go func() {
+			for {
+				conn, err := tlsl.Accept()
+				if err != nil {
+					return
+				}
+				go mux.ServeConn(tls.Server(conn, tlsConf))
+			}
+		}()
+	}
+	coreconf.RPCConf.Listener = mux.Match(connmux.Head(msg.HeadSize, msg.IsHandshakeRequest))
+	coreconf.MuxRegConf.Listener = mux.Match(connmux.Head(msg.HeadSize, msg.IsTunnelHandshakeRequest))
+	if domain := coreconf.MuxRegConf.Domain; domain != "" {
+		suffix := "." + strings.ToLower(domain)
+		// The control panel may be served on a subdomain as well.
+		panelHost := ""
+		if u, err := url.Parse(webconf.PublicURL); err == nil {
+			panelHost = strings.ToLower(u.Hostname())
+		}
+		coreconf.MuxRegConf.HTTPListener = connmux.SameHost(mux.Match(connmux.HTTP(func(host string) bool {
+			return host != panelHost && strings.HasSuffix(host, suffix)
+		})))
+	}
+	webconf.Addr = conf.Addr
+	webconf.Listener = connmux.SameHost(mux.Match(connmux.HTTP(nil)))
+	return mux, nil
+}
@@ -5,11 +5,10 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	_ "github.com/mattn/go-sqlite3"
-
-	"github.com/damnever/sunflower/pkg/util"
 )
 
 type DB struct {
@@ -17,22 +16,43 @@ type DB struct {
 	*sqlx.DB
 }
 
+// New opens the sqlite3 database in datadir, it is created if not exists.
 func New(datadir string) (*DB, error) {
 	if err := os.MkdirAll(datadir, 0750); err != nil {
 		return nil, err
 	}
-	fpath := filepath.Join(datadir, "sqlite3.db")
-	needInitDB := !util.FileExist(fpath)
+	return Open("sqlite3", filepath.Join(datadir, "sqlite3.db"))
+}
 
-	db, err := sqlx.Open("sqlite3", fpath)
+// Open opens the database of driver by dsn, the schema is created if it is empty,
+// e.g. the nodes of cluster share "file:/mnt/sun/sqlite3.db?_busy_timeout=10000"
+// on a network file system. Only sqlite3 is supported for now, since the schema
+// is written in its dialect.
+func Open(driver, dsn string) (*DB, error) {
+	if driver != "sqlite3" {
+		return nil, fmt.Errorf("unsupported storage driver: %s", driver)
+	}
+	db, err := sqlx.Open(driver, dsn)
 	if err != nil {
 		return nil, err
 	}
+	var tables int
+	if err := db.Get(&tables, "SELECT count(*) FROM sqlite_master WHERE type='table'"); err != nil {
+		db.Close()
+		return nil, err
+	}
+	needInitDB := tables == 0
 	if needInitDB {
-		if _, err = db.Exec(sqlToInitDB); err != nil {
-			db.Close()
-			return nil, err
+		_, err = db.Exec(sqlToInitDB)
+		if err == nil {
+			_, err = db.Exec(fmt.Sprintf("PRAGMA user_version=%d", len(migrations)))
 		}
+	} else {
+		err = migrate(db)
+	}
+	if err != nil {
+		db.Close()
+		return nil, err
 	}
 	return &DB{
 		DB:        db,
@@ -40,6 +60,33 @@ func New(datadir string) (*DB, error) {
 	}, nil
 }
 
+// migrate upgrades the schema of an existing database,
+// PRAGMA user_version records how many migrations have been applied.
+func migrate(db *sqlx.DB) error {
+	var version int
+	if err := db.QueryRowx("PRAGMA user_version").Scan(&version); err != nil {
+		return err
+	}
+	for ; version < len(migrations); version++ {
+		tx, err := db.Beginx()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(migrations[version]); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version=%d", version+1)); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (db *DB) IsEmpty() (bool, error) {
 	if db.firstInit {
 		return true, nil
@@ -113,6 +160,9 @@ func (db *DB) DeleteUser(name string) error {
 	if _, err := tx.Exec("DELETE FROM user WHERE id=?", userID); err != nil {
 		return err
 	}
+	if _, err := tx.Exec("DELETE FROM enroll_token WHERE user_id=?", userID); err != nil {
+		return err
+	}
 	if err := db.deleteAgentsByTxUserID(tx, userID); err != nil {
 		return err
 	}
@@ -310,11 +360,55 @@ func (db *DB) QueryTunnel(username, ahash, hash string) (Tunnel, error) {
 	return tunnel, err
 }
 
-func (db *DB) CreateTunnel(username, ahash, hash, proto, exportAddr, serverAddr, tag string) error {
-	sql := `INSERT INTO tunnel (agent_id, hash, proto, export_addr, server_addr, tag)
+// QueryTunnelByTag queries the earliest tunnel with the tag.
+func (db *DB) QueryTunnelByTag(username, ahash, tag string) (Tunnel, error) {
+	sql := `SELECT * FROM tunnel WHERE
+	agent_id=(SELECT id FROM agent WHERE user_id=
+	(SELECT id FROM user WHERE name=?) AND hash=?)
+	AND tag=? ORDER BY id LIMIT 1`
+	var tunnel Tunnel
+	row := db.QueryRowx(sql, username, ahash, tag)
+	err := row.StructScan(&tunnel)
+	return tunnel, err
+}
+
+// QueryServerAddrs queries server addresses of tunnels with any of the protocols.
+func (db *DB) QueryServerAddrs(protos ...string) ([]string, error) {
+	query, args, err := sqlx.In("SELECT server_addr FROM tunnel WHERE proto IN (?)", protos)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.Queryx(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	addrs := []string{}
+	for rows.Next() {
+		var addr string
+		if err := rows.Scan(&addr); err != nil {
+			return nil, err
+		}
+		addrs = append(addrs, addr)
+	}
+	return addrs, rows.Err()
+}
+
+// CreateTunnel creates a tunnel from the user specified fields of tunnel.
+func (db *DB) CreateTunnel(username, ahash string, tunnel Tunnel) error {
+	sql := `INSERT INTO tunnel (agent_id, hash, proto, export_addr, server_addr, tag,
+	error_page, health_check_path, secret_key, allow_cidrs, allow_ports, proxy_user, proxy_password,
+	target_addr, local_tls, tls_server_name, tls_skip_verify, tls_ca,
+	file_listing, basic_auth_user, basic_auth_password)
 	VALUES ((SELECT id FROM agent WHERE user_id=(SELECT id FROM user WHERE name=?) AND hash=?),
-	?, ?, ?, ?, ?)`
-	_, err := db.Exec(sql, username, ahash, hash, proto, exportAddr, serverAddr, tag)
+	?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := db.Exec(sql, username, ahash,
+		tunnel.Hash, tunnel.Proto, tunnel.ExportAddr, tunnel.ServerAddr, tunnel.Tag,
+		tunnel.ErrorPage, tunnel.HealthPath, tunnel.SecretKey,
+		tunnel.AllowCIDRs, tunnel.AllowPorts, tunnel.ProxyUser, tunnel.ProxyPass,
+		tunnel.TargetAddr, tunnel.LocalTLS, tunnel.TLSName, tunnel.TLSSkip, tunnel.TLSCA,
+		tunnel.FileList, tunnel.AuthUser, tunnel.AuthPass)
 	return err
 }
 
@@ -342,6 +436,56 @@ func (db *DB) DeleteTunnel(username, ahash, hash string) error {
 	return err
 }
 
+// CreateEnrollToken creates an enrollment token for the user,
+// the expired tokens are cleaned up by the way.
+func (db *DB) CreateEnrollToken(username, token, tag string, expiresAt time.Time) error {
+	if _, err := db.Exec("DELETE FROM enroll_token WHERE expires_at<=?", time.Now().UTC()); err != nil {
+		return err
+	}
+	sql := `INSERT INTO enroll_token (user_id, token, tag, expires_at)
+	VALUES ((SELECT id FROM user WHERE name=?), ?, ?, ?)`
+	_, err := db.Exec(sql, username, token, tag, expiresAt.UTC())
+	return err
+}
+
+// QueryEnrollToken queries the token which has not expired yet,
+// the name of user who owns it is returned as well.
+func (db *DB) QueryEnrollToken(token string) (string, EnrollToken, error) {
+	var enrollToken EnrollToken
+	row := db.QueryRowx("SELECT * FROM enroll_token WHERE token=? AND expires_at>?", token, time.Now().UTC())
+	if err := row.StructScan(&enrollToken); err != nil {
+		return "", enrollToken, err
+	}
+	var username string
+	row = db.QueryRowx("SELECT name FROM user WHERE id=?", enrollToken.UserID)
+	err := row.Scan(&username)
+	return username, enrollToken, err
+}
+
+// EnrollAgent creates an agent with the hash for the owner of token,
+// the token is consumed in the same transaction.
+func (db *DB) EnrollAgent(token, hash string) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var enrollToken EnrollToken
+	row := tx.QueryRowx("SELECT * FROM enroll_token WHERE token=? AND expires_at>?", token, time.Now().UTC())
+	if err := row.StructScan(&enrollToken); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM enroll_token WHERE id=?", enrollToken.ID); err != nil {
+		return err
+	}
+	sql := "INSERT INTO agent (user_id, hash, tag) VALUES (?, ?, ?)"
+	if _, err := tx.Exec(sql, enrollToken.UserID, hash, enrollToken.Tag); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
 func buildQFromMap(m map[string]interface{}) (string, []interface{}) {
 	n := len(m)
 	columns, values := make([]string, n), make([]interface{}, n)
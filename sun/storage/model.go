@@ -19,6 +19,7 @@ type User struct {
 	Password  string    `json:"password" db:"password"`
 	Email     string    `json:"email" db:"email"`
 	IsAdmin   bool      `json:"is_admin" db:"is_admin"`
+	ErrorPage string    `json:"error_page" db:"error_page"` // Template for HTTP tunnels
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
 }
@@ -81,6 +82,23 @@ type Tunnel struct {
 	CountAt    time.Time `json:"count_at" db:"count_at"`
 	Enabled    bool      `json:"enabled" db:"enabled"`
 	Tag        string    `json:"tag" db:"tag"`
+	ErrorPage  string    `json:"error_page" db:"error_page"` // Overrides the one of user
+	HealthPath string    `json:"health_check_path" db:"health_check_path"`
+	Health     string    `json:"health" db:"health"`
+	SecretKey  string    `json:"secret_key" db:"secret_key"`   // SECRET only
+	ConnMode   string    `json:"conn_mode" db:"conn_mode"`     // SECRET only, direct or relay
+	AllowCIDRs string    `json:"allow_cidrs" db:"allow_cidrs"` // PROXY only
+	AllowPorts string    `json:"allow_ports" db:"allow_ports"` // PROXY only
+	ProxyUser  string    `json:"proxy_user" db:"proxy_user"`   // PROXY only
+	ProxyPass  string    `json:"proxy_password" db:"proxy_password"`
+	TargetAddr string    `json:"target_addr" db:"target_addr"` // REVERSE only, the server side address
+	LocalTLS   bool      `json:"local_tls" db:"local_tls"`     // Connect to local service over TLS
+	TLSName    string    `json:"tls_server_name" db:"tls_server_name"`
+	TLSSkip    bool      `json:"tls_skip_verify" db:"tls_skip_verify"`
+	TLSCA      string    `json:"tls_ca" db:"tls_ca"`
+	FileList   bool      `json:"file_listing" db:"file_listing"` // file:// only
+	AuthUser   string    `json:"basic_auth_user" db:"basic_auth_user"`
+	AuthPass   string    `json:"basic_auth_password" db:"basic_auth_password"`
 	CreatedAt  time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
 }
@@ -99,6 +117,58 @@ func (tunnel Tunnel) MarshalJSON() ([]byte, error) {
 	})
 }
 
+// EnrollToken is exchanged by agent for a new agent of the user, it can
+// be used only once before it expires.
+type EnrollToken struct {
+	ID        int       `json:"id" db:"id"`
+	UserID    int       `json:"user_id" db:"user_id"`
+	Token     string    `json:"token" db:"token"`
+	Tag       string    `json:"tag" db:"tag"` // The tag of agent
+	ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// migrations upgrade the schema of databases created by older versions,
+// one entry per version, sqlToInitDB always reflects the latest schema.
+var migrations = []string{
+	// v1: custom error pages for HTTP tunnels
+	`ALTER TABLE user ADD COLUMN error_page TEXT NOT NULL DEFAULT "";
+	ALTER TABLE tunnel ADD COLUMN error_page TEXT NOT NULL DEFAULT "";`,
+	// v2: health checks of local services
+	`ALTER TABLE tunnel ADD COLUMN health_check_path VARCHAR(255) NOT NULL DEFAULT "";
+	ALTER TABLE tunnel ADD COLUMN health TEXT NOT NULL DEFAULT "UNKNOWN";`,
+	// v3: secret tunnels
+	`ALTER TABLE tunnel ADD COLUMN secret_key VARCHAR(255) NOT NULL DEFAULT "";`,
+	// v4: peer-to-peer mode of secret tunnels
+	`ALTER TABLE tunnel ADD COLUMN conn_mode VARCHAR(10) NOT NULL DEFAULT "";`,
+	// v5: SOCKS5/HTTP CONNECT proxy tunnels
+	`ALTER TABLE tunnel ADD COLUMN allow_cidrs TEXT NOT NULL DEFAULT "";
+	ALTER TABLE tunnel ADD COLUMN allow_ports TEXT NOT NULL DEFAULT "";
+	ALTER TABLE tunnel ADD COLUMN proxy_user VARCHAR(64) NOT NULL DEFAULT "";
+	ALTER TABLE tunnel ADD COLUMN proxy_password VARCHAR(64) NOT NULL DEFAULT "";`,
+	// v6: reverse tunnels
+	`ALTER TABLE tunnel ADD COLUMN target_addr VARCHAR(255) NOT NULL DEFAULT "";`,
+	// v7: TLS to local services
+	`ALTER TABLE tunnel ADD COLUMN local_tls TINYINT(1) NOT NULL DEFAULT 0;
+	ALTER TABLE tunnel ADD COLUMN tls_server_name VARCHAR(255) NOT NULL DEFAULT "";
+	ALTER TABLE tunnel ADD COLUMN tls_skip_verify TINYINT(1) NOT NULL DEFAULT 0;
+	ALTER TABLE tunnel ADD COLUMN tls_ca TEXT NOT NULL DEFAULT "";`,
+	// v8: static file servers in agents
+	`ALTER TABLE tunnel ADD COLUMN file_listing TINYINT(1) NOT NULL DEFAULT 0;
+	ALTER TABLE tunnel ADD COLUMN basic_auth_user VARCHAR(64) NOT NULL DEFAULT "";
+	ALTER TABLE tunnel ADD COLUMN basic_auth_password VARCHAR(64) NOT NULL DEFAULT "";`,
+	// v9: enrollment tokens of agents
+	`CREATE TABLE enroll_token (
+		id INTEGER PRIMARY KEY,
+		user_id BIGINT NOT NULL DEFAULT -1,
+		token VARCHAR(32) NOT NULL DEFAULT "",
+		tag VARCHAR(255) NOT NULL DEFAULT "",
+		expires_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE UNIQUE INDEX idx_enroll_token ON enroll_token (token);`,
+}
+
 var sqlToInitDB = `
 PRAGMA encoding="UTF-8";
 
@@ -108,6 +178,7 @@ CREATE TABLE user (
 	password VARCHAR(60) NOT NULL DEFAULT "",
 	email VARCHAR(50) NOT NULL DEFAULT "",
 	is_admin TINYINT(1) NOT NULL DEFAULT 0,
+	error_page TEXT NOT NULL DEFAULT "",
 	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 	updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
 );
@@ -141,12 +212,38 @@ CREATE TABLE tunnel (
 	count_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 	tag VARCHAR(255) NOT NULL DEFAULT "",
 	enabled TINYINT(1) NOT NULL DEFAULT 1,
+	error_page TEXT NOT NULL DEFAULT "",
+	health_check_path VARCHAR(255) NOT NULL DEFAULT "",
+	health TEXT NOT NULL DEFAULT "UNKNOWN",
+	secret_key VARCHAR(255) NOT NULL DEFAULT "",
+	conn_mode VARCHAR(10) NOT NULL DEFAULT "",
+	allow_cidrs TEXT NOT NULL DEFAULT "",
+	allow_ports TEXT NOT NULL DEFAULT "",
+	proxy_user VARCHAR(64) NOT NULL DEFAULT "",
+	proxy_password VARCHAR(64) NOT NULL DEFAULT "",
+	target_addr VARCHAR(255) NOT NULL DEFAULT "",
+	local_tls TINYINT(1) NOT NULL DEFAULT 0,
+	tls_server_name VARCHAR(255) NOT NULL DEFAULT "",
+	tls_skip_verify TINYINT(1) NOT NULL DEFAULT 0,
+	tls_ca TEXT NOT NULL DEFAULT "",
+	file_listing TINYINT(1) NOT NULL DEFAULT 0,
+	basic_auth_user VARCHAR(64) NOT NULL DEFAULT "",
+	basic_auth_password VARCHAR(64) NOT NULL DEFAULT "",
 	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 	updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 
 	CONSTRAINT unique_tunnel_addr UNIQUE (proto, server_addr) ON CONFLICT ABORT
 );
 
+CREATE TABLE enroll_token (
+	id INTEGER PRIMARY KEY,
+	user_id BIGINT NOT NULL DEFAULT -1,
+	token VARCHAR(32) NOT NULL DEFAULT "",
+	tag VARCHAR(255) NOT NULL DEFAULT "",
+	expires_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
 -- on update feature..
 CREATE TRIGGER user_update_trigger AFTER UPDATE ON user
 	BEGIN
@@ -167,4 +264,5 @@ CREATE TRIGGER tunnel_update_trigger AFTER UPDATE ON tunnel
 CREATE UNIQUE INDEX idx_user_name ON user (name);
 CREATE UNIQUE INDEX idx_agent_hash_user_id ON agent (user_id, hash);
 CREATE UNIQUE INDEX idx_tunnel_hash_agent_id ON tunnel (agent_id, hash);
+CREATE UNIQUE INDEX idx_enroll_token ON enroll_token (token);
 `
@@ -20,6 +20,11 @@ const (
 	statusIdle                = "IDLE"
 	statusWorking             = "Working"
 	statusError               = "Err(%s)"
+	statusHealthy             = "Healthy"
+	statusUnhealthy           = "Unhealthy(%s)"
+	statusUnknown             = "UNKNOWN" // The health is not reported yet
+	statusDirect              = "Direct"
+	statusRelay               = "Relay"
 	oneWeek                   = time.Hour * 24 * 7
 )
 
@@ -77,15 +82,27 @@ func (t *Tracker) updateTunnelStatus(uid, ahash, thash, status string) {
 	}
 }
 
+// resetTunnelStatus updates the status and forgets the health reported before,
+// the health is reported again by agent once the tunnel opened.
+func (t *Tracker) resetTunnelStatus(uid, ahash, thash, status string) {
+	_, err := t.db.UpdateTunnel(uid, ahash, thash, map[string]interface{}{
+		"status": status,
+		"health": statusUnknown,
+	})
+	if err != nil {
+		t.logger.Errorf("Update tunnel[%s/%s] status to %s failed: %v", ahash, thash, status, err)
+	}
+}
+
 func (t *Tracker) tunnelOpened(uid, ahash, thash string) {
-	t.updateTunnelStatus(uid, ahash, thash, statusOpened)
+	t.resetTunnelStatus(uid, ahash, thash, statusOpened)
 }
 
 func (t *Tracker) tunnelClosed(uid, ahash, thash string) {
 	t.connMu.Lock()
 	delete(t.connCnt, fmt.Sprintf("%s:%s", ahash, thash))
 	t.connMu.Unlock()
-	t.updateTunnelStatus(uid, ahash, thash, statusClosed)
+	t.resetTunnelStatus(uid, ahash, thash, statusClosed)
 }
 
 func (t *Tracker) tunnelIsIdle(uid, ahash, thash string) {
@@ -100,6 +117,28 @@ func (t *Tracker) tunnelOnError(uid, ahash, thash, msg string) {
 	t.updateTunnelStatus(uid, ahash, thash, fmt.Sprintf(statusError, msg))
 }
 
+func (t *Tracker) updateTunnelHealth(uid, ahash, thash, health string) {
+	_, err := t.db.UpdateTunnel(uid, ahash, thash, map[string]interface{}{"health": health})
+	if err != nil {
+		t.logger.Errorf("Update tunnel[%s/%s] health to %s failed: %v", ahash, thash, health, err)
+	}
+}
+
+func (t *Tracker) tunnelIsHealthy(uid, ahash, thash string) {
+	t.updateTunnelHealth(uid, ahash, thash, statusHealthy)
+}
+
+func (t *Tracker) tunnelIsUnhealthy(uid, ahash, thash, reason string) {
+	t.updateTunnelHealth(uid, ahash, thash, fmt.Sprintf(statusUnhealthy, reason))
+}
+
+func (t *Tracker) updateTunnelConnMode(uid, ahash, thash, mode string) {
+	_, err := t.db.UpdateTunnel(uid, ahash, thash, map[string]interface{}{"conn_mode": mode})
+	if err != nil {
+		t.logger.Errorf("Update tunnel[%s/%s] connection mode to %s failed: %v", ahash, thash, mode, err)
+	}
+}
+
 func (t *Tracker) updateTunnelNumConn(uid, ahash, thash string, num int) {
 	_, err := t.db.UpdateTunnel(uid, ahash, thash, map[string]interface{}{"num_conn": num})
 	if err != nil {
@@ -242,6 +281,26 @@ func (tt *TunnelTracker) OnError(msg string) {
 	tt.root.tunnelOnError(tt.uid, tt.ahash, tt.hash, msg)
 }
 
+// IsHealthy marks the local service as healthy, reported by agent.
+func (tt *TunnelTracker) IsHealthy() {
+	tt.root.tunnelIsHealthy(tt.uid, tt.ahash, tt.hash)
+}
+
+// IsUnhealthy marks the local service as unhealthy, reported by agent.
+func (tt *TunnelTracker) IsUnhealthy(reason string) {
+	tt.root.tunnelIsUnhealthy(tt.uid, tt.ahash, tt.hash, reason)
+}
+
+// IsDirect marks the visitor connected with agent directly, reported by agent.
+func (tt *TunnelTracker) IsDirect() {
+	tt.root.updateTunnelConnMode(tt.uid, tt.ahash, tt.hash, statusDirect)
+}
+
+// IsRelayed marks the visitor connected with agent through server.
+func (tt *TunnelTracker) IsRelayed() {
+	tt.root.updateTunnelConnMode(tt.uid, tt.ahash, tt.hash, statusRelay)
+}
+
 func (tt *TunnelTracker) IncrConn() {
 	tt.root.tunnelIncrConn(tt.uid, tt.ahash, tt.hash)
 }
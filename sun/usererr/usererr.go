@@ -0,0 +1,30 @@
+// Package usererr carries the errors caused by users, their messages are safe
+// to show, the details of the other errors are hidden from users and agents.
+package usererr
+
+import (
+	"fmt"
+)
+
+const internalReason = "internal server error"
+
+// Error is caused by user, e.g. invalid fields.
+type Error struct {
+	msg string
+}
+
+func New(format string, args ...interface{}) *Error {
+	return &Error{msg: fmt.Sprintf(format, args...)}
+}
+
+func (e *Error) Error() string {
+	return e.msg
+}
+
+// Reason returns the message of err if it is caused by user.
+func Reason(err error) string {
+	if ue, ok := err.(*Error); ok {
+		return ue.msg
+	}
+	return internalReason
+}
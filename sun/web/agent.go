@@ -53,7 +53,7 @@ func (s *Server) createAgent(c echo.Context) error {
 
 	tag := c.FormValue("tag")
 	if err := ValidateTag(tag); err != nil {
-		return newUserError(err.Error())
+		return newUserError("%v", err)
 	}
 
 	ahash := util.Hash(user.targetName, tag)[:8]
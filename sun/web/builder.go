@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -124,6 +125,15 @@ var platforms = []platform{
 	platform{GOOS: "linux", GOARCH: "mipsle"},
 }
 
+func isSupportedPlatform(os, arch, arm string) bool {
+	for _, p := range platforms {
+		if p.GOOS == os && p.GOARCH == arch && p.GOARM == arm {
+			return true
+		}
+	}
+	return false
+}
+
 type Builder struct {
 	logger      *zap.SugaredLogger
 	done        int32
@@ -153,6 +163,23 @@ func NewBuilder(datadir string, agentConfig string) (*Builder, error) {
 }
 
 func (b *Builder) TryGetPkg(username, ahash, os, arch, arm, supervisorType string) ([]byte, error) {
+	binPath, binName, err := b.binPath(os, arch, arm)
+	if err != nil {
+		return nil, err
+	}
+	return zipBin(b.AgentConfig(username, ahash), supervisorType, binPath, binName)
+}
+
+// TryGetBin returns the bare executable without any config attached.
+func (b *Builder) TryGetBin(os, arch, arm string) ([]byte, error) {
+	binPath, _, err := b.binPath(os, arch, arm)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadFile(binPath)
+}
+
+func (b *Builder) binPath(os, arch, arm string) (string, string, error) {
 	ext := ""
 	if os == "windows" {
 		ext = ".exe"
@@ -160,15 +187,21 @@ func (b *Builder) TryGetPkg(username, ahash, os, arch, arm, supervisorType strin
 	binName := "flower" + ext
 	binPath := filepath.Join(b.bindir, fmtPlatform(os, arch, arm), binName)
 
+	if !isSupportedPlatform(os, arch, arm) {
+		return "", "", errUnknown
+	}
 	if !util.FileExist(binPath) {
 		if atomic.LoadInt32(&b.done) == int32(1) {
-			return nil, errUnknown
+			return "", "", errUnknown
 		}
-		return nil, errIsBuilding
+		return "", "", errIsBuilding
 	}
+	return binPath, binName, nil
+}
 
-	agentConf := fmt.Sprintf("id: %s\nhash: %s\n%s", username, ahash, b.agentConfig)
-	return zipBin(agentConf, supervisorType, binPath, binName)
+// AgentConfig returns the configuration of agent in YAML.
+func (b *Builder) AgentConfig(username, ahash string) string {
+	return fmt.Sprintf("id: %s\nhash: %s\n%s", username, ahash, b.agentConfig)
 }
 
 func (b *Builder) StartCrossPlatformBuild() {
@@ -24,7 +24,7 @@ func (s *Server) download(c echo.Context) error {
 	data, err := s.builder.TryGetPkg(user.targetName, ahash, os, arch, arm, configType)
 	if err != nil {
 		if err == errIsBuilding || err == errUnknown {
-			return newUserError(err.Error())
+			return newUserError("%v", err)
 		}
 		return err
 	}
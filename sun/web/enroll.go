@@ -0,0 +1,94 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo"
+
+	"github.com/damnever/sunflower/pkg/util"
+	"github.com/damnever/sunflower/sun/storage"
+)
+
+const (
+	enrollTokenLen        = 32
+	defaultEnrollTokenTTL = 60 // min
+	maxEnrollTokenTTL     = 1440
+)
+
+// createEnrollToken creates a short-lived token, a generic agent exchanges
+// it for the credentials of a new agent with the tag.
+func (s *Server) createEnrollToken(c echo.Context) error {
+	user := c.Get(CtxUser).(userCtx)
+	tag := c.FormValue("tag")
+	if err := ValidateTag(tag); err != nil {
+		return newUserError("%v", err)
+	}
+	ttl := defaultEnrollTokenTTL
+	if v := c.FormValue("ttl"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxEnrollTokenTTL {
+			return newUserError("ttl of token must range in [1, %d] minutes", maxEnrollTokenTTL)
+		}
+		ttl = n
+	}
+
+	token := util.RandString(enrollTokenLen)
+	expiresAt := time.Now().Add(time.Duration(ttl) * time.Minute)
+	if err := s.db.CreateEnrollToken(user.targetName, token, tag, expiresAt); err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, echo.Map{
+		"token":      token,
+		"tag":        tag,
+		"expires_at": expiresAt.Format(time.RFC3339),
+	})
+}
+
+// enroll exchanges the token for the id, hash and configuration of
+// a new agent, the token can be used only once.
+func (s *Server) enroll(c echo.Context) error {
+	token := c.FormValue("token")
+	if len(token) != enrollTokenLen {
+		return newAuthError("invalid or expired token")
+	}
+	username, enrollToken, err := s.db.QueryEnrollToken(token)
+	if err != nil {
+		if storage.IsNotExist(err) {
+			return newAuthError("invalid or expired token")
+		}
+		return err
+	}
+	user, err := s.db.QueryUser(username)
+	if err != nil {
+		return err
+	}
+	count, err := s.db.QueryAgentCount(username)
+	if err != nil {
+		return err
+	}
+	limits := s.conf.MaxUserAgents
+	if user.IsAdmin {
+		limits = s.conf.MaxAdminAgents
+	}
+	if count > limits {
+		return newUserError("only %d agents allowed", limits)
+	}
+
+	ahash := util.Hash(username, enrollToken.Tag)[:8]
+	if err := s.db.EnrollAgent(token, ahash); err != nil {
+		if storage.IsNotExist(err) {
+			return newAuthError("invalid or expired token")
+		}
+		if storage.IsExist(err) {
+			return newUserError("agent %s[%s] already exists, try again", ahash, enrollToken.Tag)
+		}
+		return err
+	}
+	return c.JSON(http.StatusCreated, echo.Map{
+		"id":     username,
+		"hash":   ahash,
+		"config": s.builder.AgentConfig(username, ahash),
+	})
+}
@@ -0,0 +1,80 @@
+package web
+
+import (
+	"errors"
+	"math/rand"
+	"net"
+	"strconv"
+	"sync"
+
+	"github.com/damnever/sunflower/sun/storage"
+)
+
+const (
+	autoServerAddr = "auto"
+)
+
+// listenProtos are the protocols which listen on ports of server.
+var listenProtos = []string{"TCP", "PROXY"}
+
+var (
+	errAutoPortDisabled = errors.New("automatic port allocation is disabled")
+	errNoFreePort       = errors.New("no free port available, try again later")
+)
+
+// portAllocator picks free ports for TCP tunnels from a range,
+// the allocated port is persisted as the server address of tunnel.
+type portAllocator struct {
+	sync.Mutex
+	min int
+	max int
+	db  *storage.DB
+}
+
+func newPortAllocator(min, max int, db *storage.DB) *portAllocator {
+	return &portAllocator{
+		min: min,
+		max: max,
+		db:  db,
+	}
+}
+
+func (pa *portAllocator) Enabled() bool {
+	return pa.min > 0 && pa.max >= pa.min
+}
+
+// Allocate picks a server address on bindIP with a port which is neither
+// taken by other tunnels nor in use on this host, then calls create with it,
+// allocations are serialized so that they won't pick the same port.
+func (pa *portAllocator) Allocate(bindIP string, create func(addr string) error) (string, error) {
+	if !pa.Enabled() {
+		return "", errAutoPortDisabled
+	}
+	pa.Lock()
+	defer pa.Unlock()
+
+	addrs, err := pa.db.QueryServerAddrs(listenProtos...)
+	if err != nil {
+		return "", err
+	}
+
+	n := pa.max - pa.min + 1
+	start := rand.Intn(n)
+	for i := 0; i < n; i++ {
+		addr := net.JoinHostPort(bindIP, strconv.Itoa(pa.min+(start+i)%n))
+		if portConflicts(addrs, addr) || !isPortFree(addr) {
+			continue
+		}
+		return addr, create(addr)
+	}
+	return "", errNoFreePort
+}
+
+func isPortFree(addr string) bool {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return false
+	}
+	l.Close()
+	return true
+}
@@ -1,11 +1,15 @@
 package web
 
 import (
+	"crypto/ed25519"
+	"encoding/base64"
 	"expvar"
 	"fmt"
 	"mime"
+	"net"
 	"net/http"
 	"net/http/pprof"
+	"net/url"
 	"path/filepath"
 	"strings"
 
@@ -33,6 +37,9 @@ type userCtx struct {
 
 type Config struct {
 	Addr                   string
+	PublicURL              string       // Where agents reach the control panel, e.g. behind a reverse proxy
+	Listener               net.Listener // Listens on Addr if nil
+	SessionKey             string       // Signs the session cookies, random if empty
 	DataDir                string
 	MuxDomain              string
 	AllowOrigins           []string
@@ -44,6 +51,12 @@ type Config struct {
 	MaxUserTunnels         int
 	MaxDownloadsPerHour    int
 	MaxTunnelUpdatePerHour int
+	AutoPortMin            int
+	AutoPortMax            int
+	BindIPs                []string // The first one is the default
+	ReverseTargets         []string // The server side addresses reverse tunnels can reach
+	UnixSockets            []string // The patterns of unix sockets agents can export
+	FileDirs               []string // The patterns of directories agents can serve
 }
 
 type Server struct {
@@ -52,12 +65,49 @@ type Server struct {
 	downloadsCounter *counter
 	updatesCounter   *counter
 	builder          *Builder
+	ports            *portAllocator
 	db               *storage.DB
 	pub              pubsub.Publisher
+	updateKey        ed25519.PrivateKey
+	connHandler      ConnHandler
 }
 
 func New(conf *Config, db *storage.DB, pub pubsub.Publisher) (*Server, error) {
-	builder, err := NewBuilder(conf.DataDir, conf.AgentConfig)
+	for _, ip := range conf.BindIPs {
+		if err := ValidateIP(ip); err != nil {
+			return nil, fmt.Errorf("invalid bind ip: %v", err)
+		}
+	}
+	for _, pattern := range conf.UnixSockets {
+		if err := ValidatePathPattern(pattern); err != nil {
+			return nil, fmt.Errorf("invalid unix socket pattern: %v", err)
+		}
+	}
+	for _, pattern := range conf.FileDirs {
+		if err := ValidatePathPattern(pattern); err != nil {
+			return nil, fmt.Errorf("invalid directory pattern: %v", err)
+		}
+	}
+	updateKey, err := loadUpdateKey(conf.DataDir)
+	if err != nil {
+		return nil, err
+	}
+	// Agents download the new version from here if they are incompatible.
+	updateServer := conf.PublicURL
+	if updateServer == "" {
+		_, webPort, err := net.SplitHostPort(conf.Addr)
+		if err != nil {
+			return nil, err
+		}
+		updateServer = "http://" + net.JoinHostPort(conf.HostIP, webPort)
+	} else if u, err := url.Parse(updateServer); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid public url: %s", updateServer)
+	}
+	agentConfig := fmt.Sprintf("update_server: %s\nupdate_key: %s\n%s",
+		updateServer,
+		base64.StdEncoding.EncodeToString(updateKey.Public().(ed25519.PublicKey)),
+		conf.AgentConfig)
+	builder, err := NewBuilder(conf.DataDir, agentConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -68,8 +118,10 @@ func New(conf *Config, db *storage.DB, pub pubsub.Publisher) (*Server, error) {
 		downloadsCounter: newCounster(conf.MaxDownloadsPerHour),
 		updatesCounter:   newCounster(conf.MaxTunnelUpdatePerHour),
 		builder:          builder,
+		ports:            newPortAllocator(conf.AutoPortMin, conf.AutoPortMax, db),
 		db:               db,
 		pub:              pub,
+		updateKey:        updateKey,
 	}
 	s.e.HideBanner = true
 	s.setupMiddlewares()
@@ -79,6 +131,9 @@ func New(conf *Config, db *storage.DB, pub pubsub.Publisher) (*Server, error) {
 
 func (s *Server) Serve() error {
 	go s.builder.StartCrossPlatformBuild()
+	if s.conf.Listener != nil {
+		s.e.Listener = s.conf.Listener
+	}
 	return s.e.Start(s.conf.Addr)
 }
 
@@ -105,8 +160,12 @@ func (s *Server) setupMiddlewares() {
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
 		AllowOrigins: s.conf.AllowOrigins,
 	}))
+	sessionKey := s.conf.SessionKey
+	if sessionKey == "" {
+		sessionKey = util.RandString(14)
+	}
 	e.Use(session.MiddlewareWithConfig(session.Config{
-		Store: sessions.NewCookieStore([]byte(util.RandString(14))),
+		Store: sessions.NewCookieStore([]byte(sessionKey)),
 	}))
 }
 
@@ -117,6 +176,7 @@ func (s *Server) setupRouters() {
 	s.registerUserAPIRouters()
 	s.registerAdminAPIRouters()
 	s.registerSysAPIRouters()
+	s.registerWebSocketRouters()
 }
 
 func (s *Server) registerAssetsRouter() {
@@ -143,8 +203,11 @@ func (s *Server) registerAssetsRouter() {
 func (s *Server) registerConfigAPIRouter() {
 	s.e.GET("/api/config", func(c echo.Context) error {
 		return c.JSON(http.StatusOK, echo.Map{
-			"domain": s.conf.MuxDomain,
-			"ip":     s.conf.HostIP,
+			"domain":          s.conf.MuxDomain,
+			"ip":              s.conf.HostIP,
+			"auto_port":       s.ports.Enabled(),
+			"bind_ips":        s.conf.BindIPs,
+			"reverse_targets": s.conf.ReverseTargets,
 		})
 	})
 }
@@ -153,6 +216,8 @@ func (s *Server) registerAuthAPIRouters() {
 	e := s.e
 	e.POST("/api/login", s.login)
 	e.DELETE("/api/logout", s.logout)
+	e.POST("/api/enroll", s.enroll)
+	e.GET("/api/update", s.update)
 }
 
 func (s *Server) registerUserAPIRouters() {
@@ -173,6 +238,7 @@ func (s *Server) registerUserAPIRouters() {
 	g.DELETE("/agents/:ahash", s.deleteAgent)
 
 	g.GET("/agents/:ahash/bin", s.download)
+	g.POST("/enroll_tokens", s.createEnrollToken)
 
 	g.GET("/agents/:ahash/tunnels", s.showTunnels)
 	g.DELETE("/agents/:ahash/tunnels", s.deleteTunnels)
@@ -203,6 +269,7 @@ func (s *Server) registerAdminAPIRouters() {
 	g.DELETE("/:username/agents/:ahash", s.deleteAgent)
 
 	g.GET("/:username/agents/:ahash/bin", s.download)
+	g.POST("/:username/enroll_tokens", s.createEnrollToken)
 
 	g.GET("/:username/agents/:ahash/tunnels", s.showTunnels)
 	g.DELETE("/:username/agents/:ahash/tunnels", s.deleteTunnels)
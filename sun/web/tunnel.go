@@ -2,6 +2,7 @@ package web
 
 import (
 	"fmt"
+	"net"
 	"net/http"
 	"strings"
 
@@ -10,6 +11,7 @@ import (
 	"github.com/damnever/sunflower/pkg/util"
 	"github.com/damnever/sunflower/sun/pubsub"
 	"github.com/damnever/sunflower/sun/storage"
+	"github.com/damnever/sunflower/sun/usererr"
 )
 
 func (s *Server) showTunnels(c echo.Context) error {
@@ -43,57 +45,182 @@ func (s *Server) showTunnel(c echo.Context) error {
 }
 
 func (s *Server) createTunnel(c echo.Context) error {
-	// XXX(damnever): like shit....
 	user := c.Get(CtxUser).(userCtx)
 	ahash := c.Param("ahash")
-	count, err := s.db.QueryTunnelCount(user.targetName, ahash)
+	tunnel, err := s.newTunnel(user, ahash, c)
 	if err != nil {
 		return err
 	}
+
+	s.pub.Pub(ahash, &pubsub.Event{
+		Type:       pubsub.EventOpenTunnel,
+		TunnelHash: tunnel.Hash,
+	})
+	return c.JSON(http.StatusCreated, echo.Map{
+		"hash":           tunnel.Hash,
+		"server_addr":    tunnel.ServerAddr,
+		"secret_key":     tunnel.SecretKey,
+		"proxy_user":     tunnel.ProxyUser,
+		"proxy_password": tunnel.ProxyPass,
+		"target_addr":    tunnel.TargetAddr,
+	})
+}
+
+// formValuer provides the fields of tunnel, it is satisfied by echo.Context.
+type formValuer interface {
+	FormValue(name string) string
+}
+
+// newTunnel validates the fields from form and creates the tunnel,
+// the same rules apply to the tunnels from control panel and agents.
+func (s *Server) newTunnel(user userCtx, ahash string, form formValuer) (storage.Tunnel, error) {
+	// XXX(damnever): like shit....
+	count, err := s.db.QueryTunnelCount(user.targetName, ahash)
+	if err != nil {
+		return storage.Tunnel{}, err
+	}
 	limits := s.conf.MaxUserTunnels
 	if user.isAdmin {
 		limits = s.conf.MaxAdminTunnels
 	}
 	if count > limits {
-		return newUserError("only %d tunnels allowed", limits)
+		return storage.Tunnel{}, newUserError("only %d tunnels allowed", limits)
 	}
 
-	proto := strings.ToUpper(c.FormValue("proto"))
+	proto := strings.ToUpper(form.FormValue("proto"))
 	if err := ValidteProtocol(proto); err != nil {
-		return newUserError(err.Error())
+		return storage.Tunnel{}, newUserError("%v", err)
 	}
-	exportAddr := c.FormValue("export_addr")
-	if err := ValidateLocalAddr(exportAddr); err != nil {
-		return newUserError(err.Error())
+	// The destinations of proxy tunnels are chosen by clients.
+	exportAddr := form.FormValue("export_addr")
+	if proto == "PROXY" {
+		exportAddr = ""
+	} else if err := ValidateLocalAddr(exportAddr, s.conf.UnixSockets, s.conf.FileDirs); err != nil {
+		return storage.Tunnel{}, newUserError("%v", err)
 	}
-	tag := c.FormValue("tag")
+	isFileServer := strings.HasPrefix(exportAddr, fileAddrPrefix)
+	if isFileServer && proto == "REVERSE" {
+		return storage.Tunnel{}, newUserError("reverse tunnels can not serve files")
+	}
+	tag := form.FormValue("tag")
 	if err := ValidateTag(tag); err != nil {
-		return newUserError(err.Error())
+		return storage.Tunnel{}, newUserError("%v", err)
+	}
+	errPage := form.FormValue("error_page")
+	if err := ValidateErrorPage(errPage); err != nil {
+		return storage.Tunnel{}, newUserError("%v", err)
+	}
+	healthPath := form.FormValue("health_check_path")
+	if err := ValidateHealthCheckPath(healthPath); err != nil {
+		return storage.Tunnel{}, newUserError("%v", err)
 	}
 
-	serverAddr := c.FormValue("server_addr")
+	thash := util.Hash(user.targetName, ahash, tag)[:8]
+	tunnel := storage.Tunnel{
+		Hash:       thash,
+		Proto:      proto,
+		ExportAddr: exportAddr,
+		Tag:        tag,
+		ErrorPage:  errPage,
+		HealthPath: healthPath,
+	}
+	createTunnel := func(serverAddr string) error {
+		tunnel.ServerAddr = serverAddr
+		return s.db.CreateTunnel(user.targetName, ahash, tunnel)
+	}
+
+	if proto == "SECRET" {
+		// Visitors connect to it by the tunnel hash, no public address at all.
+		secretKey := form.FormValue("secret_key")
+		if secretKey == "" {
+			secretKey = util.RandString(24)
+		} else if err := ValidateSecretKey(secretKey); err != nil {
+			return storage.Tunnel{}, newUserError("%v", err)
+		}
+		tunnel.SecretKey = secretKey
+	}
+
+	if isFileServer {
+		tunnel.FileList = form.FormValue("file_listing") == "true"
+		tunnel.AuthUser = form.FormValue("basic_auth_user")
+		tunnel.AuthPass = form.FormValue("basic_auth_password")
+		if err := ValidateBasicAuth(tunnel.AuthUser, tunnel.AuthPass); err != nil {
+			return storage.Tunnel{}, newUserError("%v", err)
+		}
+	} else if form.FormValue("local_tls") == "true" && proto != "PROXY" && proto != "REVERSE" {
+		tunnel.LocalTLS = true
+		tunnel.TLSName = form.FormValue("tls_server_name")
+		tunnel.TLSSkip = form.FormValue("tls_skip_verify") == "true"
+		tunnel.TLSCA = form.FormValue("tls_ca")
+		if err := ValidateLocalTLS(tunnel.TLSName, tunnel.TLSCA); err != nil {
+			return storage.Tunnel{}, newUserError("%v", err)
+		}
+	}
+
+	if proto == "PROXY" {
+		tunnel.AllowCIDRs = form.FormValue("allow_cidrs")
+		tunnel.AllowPorts = form.FormValue("allow_ports")
+		if err := ValidateAllowlist(tunnel.AllowCIDRs, tunnel.AllowPorts); err != nil {
+			return storage.Tunnel{}, newUserError("%v", err)
+		}
+		tunnel.ProxyUser = form.FormValue("proxy_user")
+		tunnel.ProxyPass = form.FormValue("proxy_password")
+		if tunnel.ProxyPass == "" {
+			tunnel.ProxyPass = util.RandString(16)
+		}
+		if err := ValidateProxyCredentials(tunnel.ProxyUser, tunnel.ProxyPass); err != nil {
+			return storage.Tunnel{}, newUserError("%v", err)
+		}
+	}
+
+	serverAddr := form.FormValue("server_addr")
+	if proto == "REVERSE" {
+		// The agent listens on the export address, connections go to the target.
+		tunnel.TargetAddr = serverAddr
+		if !isReverseTarget(tunnel.TargetAddr, s.conf.ReverseTargets) {
+			return storage.Tunnel{}, newUserError("target address %s is not allowed", tunnel.TargetAddr)
+		}
+	}
+	bindIP := form.FormValue("bind_ip")
+	if proto == "TCP" || proto == "PROXY" {
+		if bindIP == "" {
+			bindIP = s.conf.BindIPs[0]
+		} else if !isBindableIP(bindIP, s.conf.BindIPs) {
+			return storage.Tunnel{}, newUserError("ip %s is not bindable", bindIP)
+		}
+	}
 	if proto == "HTTP" {
 		serverAddr = strings.ToLower(fmt.Sprintf("%s.%s", serverAddr, user.targetName))
+		err = createTunnel(serverAddr)
+	} else if proto == "SECRET" || proto == "REVERSE" {
+		serverAddr = thash
+		err = createTunnel(serverAddr)
+	} else if strings.ToLower(serverAddr) == autoServerAddr {
+		serverAddr, err = s.ports.Allocate(bindIP, createTunnel)
+		if err == errAutoPortDisabled || err == errNoFreePort {
+			return storage.Tunnel{}, newUserError("%v", err)
+		}
 	} else {
-		serverAddr = fmt.Sprintf("0.0.0.0:%s", serverAddr)
+		serverAddr = net.JoinHostPort(bindIP, serverAddr)
 		if err := ValidateServerAddr(serverAddr); err != nil {
-			return newUserError(err.Error())
+			return storage.Tunnel{}, newUserError("%v", err)
+		}
+		var addrs []string
+		if addrs, err = s.db.QueryServerAddrs(listenProtos...); err != nil {
+			return storage.Tunnel{}, err
 		}
+		if portConflicts(addrs, serverAddr) {
+			return storage.Tunnel{}, newUserError("server address %s is already in use", serverAddr)
+		}
+		err = createTunnel(serverAddr)
 	}
-	thash := util.Hash(user.targetName, ahash, tag)[:8]
-	err = s.db.CreateTunnel(user.targetName, ahash, thash, proto, exportAddr, serverAddr, tag)
 	if err != nil {
 		if storage.IsExist(err) {
-			return newUserError("tunnel %s[%s] already exists, try again", thash, tag)
+			return storage.Tunnel{}, newUserError("tunnel %s[%s] already exists, try again", thash, tag)
 		}
-		return err
+		return storage.Tunnel{}, err
 	}
-
-	s.pub.Pub(ahash, &pubsub.Event{
-		Type:       pubsub.EventOpenTunnel,
-		TunnelHash: thash,
-	})
-	return c.JSON(http.StatusCreated, echo.Map{"hash": thash})
+	return tunnel, nil
 }
 
 func (s *Server) updateTunnel(c echo.Context) error {
@@ -105,25 +232,54 @@ func (s *Server) updateTunnel(c echo.Context) error {
 		return newUserError("Exceed the limit of max tunnel updates per hour")
 	}
 
-	enabled := c.FormValue("enabled")
-	if enabled == "" {
-		return newUserError("empty fields")
+	params := map[string]interface{}{}
+	form, err := c.FormParams()
+	if err != nil {
+		return err
+	}
+	// An empty error page means using the one of user
+	if _, in := form["error_page"]; in {
+		errPage := c.FormValue("error_page")
+		if err := ValidateErrorPage(errPage); err != nil {
+			return newUserError("%v", err)
+		}
+		params["error_page"] = errPage
+	}
+	if _, in := form["health_check_path"]; in {
+		healthPath := c.FormValue("health_check_path")
+		if err := ValidateHealthCheckPath(healthPath); err != nil {
+			return newUserError("%v", err)
+		}
+		params["health_check_path"] = healthPath
+	}
+	var evts []*pubsub.Event
+	if _, in := params["error_page"]; in {
+		evts = append(evts, &pubsub.Event{
+			Type:       pubsub.EventUpdateTunnel,
+			TunnelHash: thash,
+		})
 	}
-	params := map[string]interface{}{"enabled": true}
-	evtType := pubsub.EventOpenTunnel
-	if enabled == "false" {
-		params["enabled"] = false
-		evtType = pubsub.EventCloseTunnel
+	if enabled := c.FormValue("enabled"); enabled != "" {
+		params["enabled"] = true
+		evtType := pubsub.EventOpenTunnel
+		if enabled == "false" {
+			params["enabled"] = false
+			evtType = pubsub.EventCloseTunnel
+		}
+		evts = append(evts, &pubsub.Event{
+			Type:       evtType,
+			TunnelHash: thash,
+		})
+	}
+	if len(params) == 0 {
+		return newUserError("empty fields")
 	}
 
 	if _, err := s.db.UpdateTunnel(user.targetName, ahash, thash, params); err != nil {
 		return err
 	}
 
-	s.pub.Pub(ahash, &pubsub.Event{
-		Type:       evtType,
-		TunnelHash: thash,
-	})
+	s.pub.Pub(ahash, evts...)
 	return c.NoContent(http.StatusResetContent)
 }
 
@@ -141,3 +297,81 @@ func (s *Server) deleteTunnel(c echo.Context) error {
 	})
 	return c.NoContent(http.StatusOK)
 }
+
+// tunnelForm provides the fields of tunnels declared by agents.
+type tunnelForm map[string]string
+
+func (f tunnelForm) FormValue(name string) string {
+	return f[name]
+}
+
+// DeclareTunnel creates the tunnel declared by agent if it does not exist, the
+// fields are the same as the form of control panel, and the tag is required.
+// An existing tunnel is accepted only if its protocol and export address match.
+func (s *Server) DeclareTunnel(username, ahash string, fields map[string]string) (storage.Tunnel, error) {
+	tunnel, err := s.declareTunnel(username, ahash, fields)
+	return tunnel, asUserError(err)
+}
+
+func (s *Server) declareTunnel(username, ahash string, fields map[string]string) (storage.Tunnel, error) {
+	user, err := s.db.QueryUser(username)
+	if err != nil {
+		return storage.Tunnel{}, err
+	}
+	form := tunnelForm(fields)
+	tag := form.FormValue("tag")
+	if tag == "" {
+		return storage.Tunnel{}, newUserError("tag of declared tunnel can not be empty")
+	}
+
+	proto, exportAddr := strings.ToUpper(form.FormValue("proto")), form.FormValue("export_addr")
+	if proto == "PROXY" { // Stored without export address
+		exportAddr = ""
+	}
+	tunnel, err := s.db.QueryTunnelByTag(username, ahash, tag)
+	if err == nil {
+		if tunnel.Proto != proto || tunnel.ExportAddr != exportAddr {
+			return storage.Tunnel{}, newUserError("tunnel %s[%s] already exists with different definition", tunnel.Hash, tag)
+		}
+		return tunnel, nil
+	}
+	if !storage.IsNotExist(err) {
+		return storage.Tunnel{}, err
+	}
+
+	uctx := userCtx{name: username, targetName: username, isAdmin: user.IsAdmin}
+	if tunnel, err = s.newTunnel(uctx, ahash, form); err != nil {
+		return storage.Tunnel{}, err
+	}
+	s.pub.Pub(ahash, &pubsub.Event{
+		Type:       pubsub.EventOpenTunnel,
+		TunnelHash: tunnel.Hash,
+	})
+	return tunnel, nil
+}
+
+// CreateTunnel creates the tunnel without the web server, e.g. from command
+// line, the fields are the same as the form of control panel, and the limits
+// are the ones of administrators. The connected agent is not notified.
+func CreateTunnel(conf *Config, db *storage.DB, username, ahash string, fields map[string]string) (storage.Tunnel, error) {
+	if _, err := db.QueryAgent(username, ahash); err != nil {
+		return storage.Tunnel{}, err
+	}
+	s := &Server{
+		conf:  conf,
+		db:    db,
+		ports: newPortAllocator(conf.AutoPortMin, conf.AutoPortMax, db),
+	}
+	uctx := userCtx{name: username, targetName: username, isAdmin: true}
+	tunnel, err := s.newTunnel(uctx, ahash, tunnelForm(fields))
+	return tunnel, asUserError(err)
+}
+
+// asUserError converts the errors for control panel into usererr.Error,
+// so they can be told apart without the web server.
+func asUserError(err error) error {
+	if he, ok := err.(*echo.HTTPError); ok && he.Code == http.StatusBadRequest {
+		return usererr.New("%v", he.Message)
+	}
+	return err
+}
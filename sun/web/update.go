@@ -0,0 +1,76 @@
+package web
+
+import (
+	"crypto/ed25519"
+	crand "crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/labstack/echo"
+
+	"github.com/damnever/sunflower/pkg/selfupdate"
+	"github.com/damnever/sunflower/sun/storage"
+	"github.com/damnever/sunflower/version"
+)
+
+const updateKeyFile = "update.key"
+
+// loadUpdateKey loads the key which signs the executables served to agents,
+// it is generated if not exists, agents verify them with the public key.
+func loadUpdateKey(datadir string) (ed25519.PrivateKey, error) {
+	fpath := filepath.Join(datadir, updateKeyFile)
+	data, err := ioutil.ReadFile(fpath)
+	if err == nil {
+		seed, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+		if err != nil || len(seed) != ed25519.SeedSize {
+			return nil, fmt.Errorf("bad update key: %s", fpath)
+		}
+		return ed25519.NewKeyFromSeed(seed), nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(datadir, 0750); err != nil {
+		return nil, err
+	}
+	_, key, err := ed25519.GenerateKey(crand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	seed := base64.StdEncoding.EncodeToString(key.Seed())
+	if err := ioutil.WriteFile(fpath, []byte(seed+"\n"), 0600); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// update serves the signed executable of the platform to agents,
+// which authenticate themselves with id and hash.
+func (s *Server) update(c echo.Context) error {
+	req := c.Request()
+	id, ahash := req.Header.Get(selfupdate.HeaderAgentID), req.Header.Get(selfupdate.HeaderAgentHash)
+	if _, err := s.db.QueryAgent(id, ahash); err != nil {
+		if storage.IsNotExist(err) {
+			return newAuthError("no such agent")
+		}
+		return err
+	}
+
+	data, err := s.builder.TryGetBin(c.QueryParam("GOOS"), c.QueryParam("GOARCH"), c.QueryParam("GOARM"))
+	if err != nil {
+		if err == errIsBuilding || err == errUnknown {
+			return newUserError("%v", err)
+		}
+		return err
+	}
+	header := c.Response().Header()
+	header.Set(selfupdate.HeaderSignature, selfupdate.Sign(s.updateKey, data))
+	header.Set(selfupdate.HeaderVersion, version.Full())
+	return c.Blob(http.StatusOK, "application/octet-stream", data)
+}
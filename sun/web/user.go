@@ -31,15 +31,15 @@ func (s *Server) createUser(c echo.Context) error {
 	// XXX(damnver): like shit...
 	username := c.FormValue("username")
 	if err := ValidateUsername(username); err != nil {
-		return newUserError(err.Error())
+		return newUserError("%v", err)
 	}
 	password := c.FormValue("password")
 	if err := ValidatePassword(password); err != nil {
-		return newUserError(err.Error())
+		return newUserError("%v", err)
 	}
 	email := c.FormValue("email")
 	if err := ValidateEmail(email); err != nil {
-		return newUserError(err.Error())
+		return newUserError("%v", err)
 	}
 	password, err := util.EncryptPasswd([]byte(password))
 	if err != nil {
@@ -67,19 +67,41 @@ func (s *Server) updateUser(c echo.Context) error {
 	}
 	if email := c.FormValue("email"); email != "" {
 		if err := ValidateEmail(email); err != nil {
-			return newUserError(err.Error())
+			return newUserError("%v", err)
 		}
 		fields["email"] = email
 	}
+	form, err := c.FormParams()
+	if err != nil {
+		return err
+	}
+	if _, in := form["error_page"]; in {
+		errPage := c.FormValue("error_page")
+		if err := ValidateErrorPage(errPage); err != nil {
+			return newUserError("%v", err)
+		}
+		fields["error_page"] = errPage
+	}
 	if len(fields) == 0 {
 		return newUserError("empty fields")
 	}
 
 	user := c.Get(CtxUser).(userCtx)
-	_, err := s.db.UpdateUser(user.targetName, fields)
+	_, err = s.db.UpdateUser(user.targetName, fields)
 	if err != nil {
 		return err
 	}
+
+	if _, in := fields["error_page"]; in {
+		// The tunnels without their own error page use the one of user.
+		ahashs, err := s.db.QueryAgentHashs(user.targetName)
+		if err != nil {
+			return err
+		}
+		for _, ahash := range ahashs {
+			s.pub.Pub(ahash, &pubsub.Event{Type: pubsub.EventUpdateTunnel})
+		}
+	}
 	return c.NoContent(http.StatusResetContent)
 }
 
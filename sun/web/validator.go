@@ -1,20 +1,27 @@
 package web
 
 import (
+	"crypto/x509"
 	"fmt"
 	"net"
+	"net/url"
+	"path"
 	"regexp"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/damnever/sunflower/pkg/allowlist"
+	"github.com/damnever/sunflower/sun/registry"
 )
 
 const (
-	minUsernameLen = 6
-	maxUsernameLen = 23
-	minPasswordLen = 8
-	maxPasswordLen = 30
+	minUsernameLen  = 6
+	maxUsernameLen  = 23
+	minPasswordLen  = 8
+	maxPasswordLen  = 30
+	maxErrorPageLen = 16384
 )
 
 var (
@@ -56,9 +63,102 @@ func ValidateTag(tag string) error {
 	return nil
 }
 
+func ValidateErrorPage(page string) error {
+	if len(page) > maxErrorPageLen {
+		return fmt.Errorf("error page is too large, at most %d bytes", maxErrorPageLen)
+	}
+	if _, err := registry.ParseErrorPage(page); err != nil {
+		return fmt.Errorf("bad error page template: %v", err)
+	}
+	return nil
+}
+
+func ValidateHealthCheckPath(path string) error {
+	if path == "" {
+		return nil
+	}
+	if !strings.HasPrefix(path, "/") {
+		return fmt.Errorf("health check path must start with '/'")
+	}
+	if _, err := url.ParseRequestURI(path); err != nil {
+		return fmt.Errorf("bad health check path: %v", err)
+	}
+	return nil
+}
+
+const (
+	minSecretKeyLen = 8
+	maxSecretKeyLen = 64
+)
+
+func ValidateSecretKey(key string) error {
+	if n := len(key); n < minSecretKeyLen || n > maxSecretKeyLen {
+		return fmt.Errorf("length of secret key must range in [%d, %d]", minSecretKeyLen, maxSecretKeyLen)
+	}
+	return nil
+}
+
+const maxTLSCALen = 65536
+
+// ValidateLocalTLS validates the TLS options to connect to local services,
+// the CA bundle must contain at least one PEM encoded certificate if not empty.
+func ValidateLocalTLS(serverName, ca string) error {
+	if len(serverName) > 253 || strings.ContainsAny(serverName, " /:") {
+		return fmt.Errorf("bad TLS server name: %s", serverName)
+	}
+	if ca == "" {
+		return nil
+	}
+	if len(ca) > maxTLSCALen {
+		return fmt.Errorf("CA bundle is too large, at most %d bytes", maxTLSCALen)
+	}
+	if !x509.NewCertPool().AppendCertsFromPEM([]byte(ca)) {
+		return fmt.Errorf("no valid PEM encoded certificate found in CA bundle")
+	}
+	return nil
+}
+
+func ValidateAllowlist(cidrs, ports string) error {
+	if _, err := allowlist.Parse(cidrs, ports); err != nil {
+		return fmt.Errorf("bad allowlist: %v", err)
+	}
+	return nil
+}
+
+// ValidateProxyCredentials validates the credentials, which must fit in
+// the username/password authentication of SOCKS5.
+func ValidateProxyCredentials(user, password string) error {
+	return validateCredentials("proxy", user, password)
+}
+
+// ValidateBasicAuth validates the credentials of basic auth,
+// both of them are empty means basic auth is disabled.
+func ValidateBasicAuth(user, password string) error {
+	if user == "" && password == "" {
+		return nil
+	}
+	return validateCredentials("basic auth", user, password)
+}
+
+func validateCredentials(kind, user, password string) error {
+	if n := len(user); n < 1 || n > 64 {
+		return fmt.Errorf("length of %s user must range in [1, 64]", kind)
+	}
+	if strings.Contains(user, ":") {
+		return fmt.Errorf("%s user can not contain ':'", kind)
+	}
+	if n := len(password); n < 8 || n > 64 {
+		return fmt.Errorf("length of %s password must range in [8, 64]", kind)
+	}
+	return nil
+}
+
 var supportedProtos = map[string]bool{
-	"HTTP": true,
-	"TCP":  true,
+	"HTTP":    true,
+	"TCP":     true,
+	"SECRET":  true,
+	"PROXY":   true,
+	"REVERSE": true,
 }
 
 func ValidteProtocol(proto string) error {
@@ -68,10 +168,57 @@ func ValidteProtocol(proto string) error {
 	return fmt.Errorf("unsupported protocol %s", proto)
 }
 
-func ValidateLocalAddr(addr string) error {
+const (
+	unixAddrPrefix = "unix://"
+	fileAddrPrefix = "file://"
+)
+
+// ValidateLocalAddr validates host:port, unix:///path/to/socket or
+// file:///path/to/dir, the socket path must match one of the patterns
+// in unixSockets, and the directory must match one of fileDirs.
+func ValidateLocalAddr(addr string, unixSockets, fileDirs []string) error {
+	if strings.HasPrefix(addr, unixAddrPrefix) {
+		return validateAgentPath("unix socket", addr[len(unixAddrPrefix):], unixSockets)
+	}
+	if strings.HasPrefix(addr, fileAddrPrefix) {
+		return validateAgentPath("directory", addr[len(fileAddrPrefix):], fileDirs)
+	}
 	return validateAddr(addr, 0)
 }
 
+// The paths are on agent side, always use slash.
+func validateAgentPath(kind, p string, patterns []string) error {
+	if !isAbsAgentPath(p) || path.Clean(p) != p {
+		return fmt.Errorf("%s path must be absolute and clean", kind)
+	}
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, p); matched {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s %s is not allowed", kind, p)
+}
+
+// isAbsAgentPath reports whether the slash separated path is absolute on
+// agent side, which may be a Windows one starts with the drive, e.g. C:/dir.
+func isAbsAgentPath(p string) bool {
+	if path.IsAbs(p) {
+		return true
+	}
+	return len(p) >= 3 && p[1] == ':' && p[2] == '/' &&
+		('a' <= p[0] && p[0] <= 'z' || 'A' <= p[0] && p[0] <= 'Z')
+}
+
+// ValidatePathPattern validates the patterns of unix sockets and
+// directories configured by admin.
+func ValidatePathPattern(pattern string) error {
+	if !isAbsAgentPath(pattern) {
+		return fmt.Errorf("pattern %s must be an absolute path", pattern)
+	}
+	_, err := path.Match(pattern, "")
+	return err
+}
+
 func ValidateServerAddr(addr string) error {
 	return validateAddr(addr, 1024)
 }
@@ -86,17 +233,68 @@ func validateAddr(addr string, minPort int) error {
 		return err
 	}
 	if n < minPort || n > 65535 {
-		return fmt.Errorf("port(%d) must range in [1024, 65535]", n)
+		return fmt.Errorf("port(%d) must range in [%d, 65535]", n, minPort)
 	}
 	if host == "localhost" {
 		return nil
 	}
-	if net.ParseIP(host) == nil {
-		return fmt.Errorf("invalid ip address or host name: %s", host)
+	return ValidateIP(host)
+}
+
+// ValidateIP validates both IPv4 and IPv6 addresses,
+// IPv6 addresses may have a zone, e.g. fe80::1%eth0.
+func ValidateIP(ip string) error {
+	if i := strings.LastIndex(ip, "%"); i > 0 && strings.Contains(ip, ":") {
+		ip = ip[:i]
+	}
+	if net.ParseIP(ip) == nil {
+		return fmt.Errorf("invalid ip address or host name: %s", ip)
 	}
 	return nil
 }
 
+// portConflicts reports whether the addr conflicts with any of addrs,
+// it happens if ports are the same and either host is a wildcard address.
+func portConflicts(addrs []string, addr string) bool {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	for _, a := range addrs {
+		h, p, err := net.SplitHostPort(a)
+		if err != nil || p != port {
+			continue
+		}
+		if h == host || isWildcardIP(h) || isWildcardIP(host) {
+			return true
+		}
+	}
+	return false
+}
+
+func isBindableIP(ip string, bindIPs []string) bool {
+	for _, bindIP := range bindIPs {
+		if ip == bindIP {
+			return true
+		}
+	}
+	return false
+}
+
+func isReverseTarget(addr string, targets []string) bool {
+	for _, target := range targets {
+		if addr == target {
+			return true
+		}
+	}
+	return false
+}
+
+func isWildcardIP(host string) bool {
+	ip := net.ParseIP(host)
+	return host == "" || (ip != nil && ip.IsUnspecified())
+}
+
 type counter struct {
 	sync.Mutex
 	max      int
@@ -0,0 +1,43 @@
+package web
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/labstack/echo"
+
+	"github.com/damnever/sunflower/pkg/wsconn"
+)
+
+// ConnHandler serves the connections of agents carried by WebSocket,
+// see sun.CtlServer.
+type ConnHandler interface {
+	HandleControlConn(conn net.Conn)
+	HandleTunnelConn(conn net.Conn)
+}
+
+// SetConnHandler enables agents to connect over WebSocket, it must be
+// called before Serve.
+func (s *Server) SetConnHandler(h ConnHandler) {
+	s.connHandler = h
+}
+
+func (s *Server) registerWebSocketRouters() {
+	e := s.e
+	e.GET("/ws/control", s.websocket(ConnHandler.HandleControlConn))
+	e.GET("/ws/tunnel", s.websocket(ConnHandler.HandleTunnelConn))
+}
+
+// websocket upgrades the request, the agents are authenticated by
+// the handshakes of control server and tunnel registry.
+func (s *Server) websocket(handle func(ConnHandler, net.Conn)) echo.HandlerFunc {
+	upgrade := echo.WrapHandler(wsconn.Handler(func(conn net.Conn) {
+		handle(s.connHandler, conn)
+	}))
+	return func(c echo.Context) error {
+		if s.connHandler == nil {
+			return echo.NewHTTPError(http.StatusServiceUnavailable, "not ready")
+		}
+		return upgrade(c)
+	}
+}